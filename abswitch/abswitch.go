@@ -12,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/tikz/bio/fasta"
 	"github.com/tikz/bio/pdb"
 	"github.com/tikz/bio/uniprot"
 )
@@ -109,7 +110,8 @@ func (ab *AbSwitch) Run(seq string) ([]*ResidueResults, error) {
 
 		// Write fasta
 		fastaFile := "abswitch_" + name + ".fasta"
-		ioutil.WriteFile(dirName+"/"+fastaFile, []byte(">"+name+"\n"+seq), 0644)
+		raw, _ := fasta.Format(&fasta.Record{ID: name, Sequence: seq})
+		ioutil.WriteFile(dirName+"/"+fastaFile, raw, 0644)
 
 		// Write cfg
 		cfgFile := "abswitch_" + name + ".cfg"
@@ -0,0 +1,303 @@
+// Package align implements pairwise sequence alignment with affine gap penalties.
+package align
+
+import (
+	"math"
+	"strings"
+)
+
+// Gap is the character used for gaps in the aligned sequences.
+const Gap = '-'
+
+const negInf = math.MinInt32 / 2
+
+// Matrix is a substitution matrix over an alphabet of one letter codes.
+type Matrix struct {
+	alphabet string
+	scores   [][]int
+	index    [256]int
+}
+
+// NewMatrix constructs a substitution matrix from the scores of each pair of letters of the alphabet.
+// Letters not in the alphabet are scored as X, if present, or as the last letter otherwise.
+func NewMatrix(alphabet string, scores [][]int) *Matrix {
+	m := &Matrix{alphabet: alphabet, scores: scores}
+	unknown := strings.IndexByte(alphabet, 'X')
+	if unknown == -1 {
+		unknown = len(alphabet) - 1
+	}
+	for i := range m.index {
+		m.index[i] = unknown
+	}
+	for i := 0; i < len(alphabet); i++ {
+		m.index[alphabet[i]] = i
+		m.index[strings.ToLower(alphabet[i : i+1])[0]] = i
+	}
+	return m
+}
+
+// Score returns the substitution score of a pair of letters.
+func (m *Matrix) Score(a byte, b byte) int {
+	return m.scores[m.index[a]][m.index[b]]
+}
+
+// Scoring holds the substitution matrix and gap penalties. A gap of length k costs GapOpen + k * GapExtend.
+type Scoring struct {
+	Matrix    *Matrix
+	GapOpen   int
+	GapExtend int
+}
+
+// DefaultScoring is BLOSUM62 with the BLAST default gap penalties.
+var DefaultScoring = Scoring{Matrix: BLOSUM62, GapOpen: 11, GapExtend: 1}
+
+// Alignment is a pairwise alignment between sequences A and B.
+type Alignment struct {
+	A      string `json:"a"`      // aligned A, with gaps
+	B      string `json:"b"`      // aligned B, with gaps
+	Score  int    `json:"score"`  // alignment score
+	StartA int    `json:"startA"` // 0-based position in A of the first aligned column
+	StartB int    `json:"startB"` // 0-based position in B of the first aligned column
+}
+
+type mode int
+
+const (
+	global mode = iota
+	semiGlobal
+	local
+)
+
+// DP states
+const (
+	match = iota
+	gapB  // letter of A against a gap
+	gapA  // letter of B against a gap
+	start // beginning of a local alignment
+)
+
+// Global aligns both sequences end to end (Needleman-Wunsch, with affine gaps).
+func Global(a string, b string, s Scoring) *Alignment {
+	return align(a, b, s, global)
+}
+
+// SemiGlobal aligns both sequences end to end without penalizing leading and trailing gaps,
+// useful when one sequence is contained in the other, or both overlap.
+func SemiGlobal(a string, b string, s Scoring) *Alignment {
+	return align(a, b, s, semiGlobal)
+}
+
+// Local finds the best scoring alignment between subsequences (Smith-Waterman, with affine gaps).
+func Local(a string, b string, s Scoring) *Alignment {
+	return align(a, b, s, local)
+}
+
+func align(a string, b string, s Scoring, m mode) *Alignment {
+	n, w := len(a), len(b)
+	cols := w + 1
+	open := s.GapOpen + s.GapExtend
+
+	// Only the previous and current rows of scores are kept, and the traceback takes a byte per cell,
+	// so aligning a 35000 residue sequence against a 1000 residue one takes about 35 MB.
+	trace := make([]byte, (n+1)*cols)
+	var prev, cur, lastCol [3][]int // lastCol keeps the scores of the last column, for the end of semi-global alignments
+	for k := range prev {
+		prev[k], cur[k], lastCol[k] = make([]int, cols), make([]int, cols), make([]int, n+1)
+		for j := range prev[k] {
+			prev[k][j] = negInf
+		}
+	}
+
+	// Leading gaps, free in semi-global alignments and absent in local ones
+	prev[match][0] = 0
+	for j := 1; j <= w && m != local; j++ {
+		prev[gapA][j] = -(s.GapOpen + j*s.GapExtend)
+		if m == semiGlobal {
+			prev[gapA][j] = 0
+		}
+		if j > 1 {
+			setTrace(trace, j, gapA, gapA)
+		}
+	}
+	for k := range prev {
+		lastCol[k][0] = prev[k][w]
+	}
+
+	// best returns the highest scoring state among the candidates, in order of preference.
+	best := func(candidates [3]int) (int, int) {
+		state := match
+		for k := 1; k < 3; k++ {
+			if candidates[k] > candidates[state] {
+				state = k
+			}
+		}
+		return candidates[state], state
+	}
+
+	// End cell
+	endI, endJ, endState := n, w, match
+	endScore := negInf
+	if m == local {
+		endScore = 0
+	}
+
+	for i := 1; i <= n; i++ {
+		for k := range cur {
+			cur[k][0] = negInf
+		}
+		if m != local {
+			cur[gapB][0] = -(s.GapOpen + i*s.GapExtend)
+			if m == semiGlobal {
+				cur[gapB][0] = 0
+			}
+			if i > 1 {
+				setTrace(trace, i*cols, gapB, gapB)
+			}
+		}
+
+		for j := 1; j <= w; j++ {
+			c := i*cols + j
+
+			v, state := best([3]int{prev[match][j-1], prev[gapB][j-1], prev[gapA][j-1]})
+			if m == local && v <= 0 {
+				v, state = 0, start
+			}
+			cur[match][j] = v + s.Matrix.Score(a[i-1], b[j-1])
+			setTrace(trace, c, match, state)
+
+			v, state = best([3]int{prev[match][j] - open, prev[gapB][j] - s.GapExtend, prev[gapA][j] - open})
+			cur[gapB][j] = v
+			setTrace(trace, c, gapB, state)
+
+			v, state = best([3]int{cur[match][j-1] - open, cur[gapB][j-1] - open, cur[gapA][j-1] - s.GapExtend})
+			cur[gapA][j] = v
+			setTrace(trace, c, gapA, state)
+
+			if m == local && cur[match][j] > endScore {
+				endScore, endI, endJ, endState = cur[match][j], i, j, match
+			}
+		}
+
+		for k := range cur {
+			lastCol[k][i] = cur[k][w]
+		}
+		prev, cur = cur, prev
+	}
+
+	// consider takes the given cell as the end if it scores higher, with its scores at the given index of each state.
+	consider := func(i int, j int, scores [3][]int, index int) {
+		for k := range scores {
+			if v := scores[k][index]; v > endScore {
+				endScore, endI, endJ, endState = v, i, j, k
+			}
+		}
+	}
+	switch m {
+	case global:
+		consider(n, w, prev, w)
+	case semiGlobal:
+		for j := 0; j <= w; j++ {
+			consider(n, j, prev, j)
+		}
+		for i := 0; i <= n; i++ {
+			consider(i, w, lastCol, i)
+		}
+	case local:
+		if endScore <= 0 {
+			return &Alignment{}
+		}
+	}
+
+	// Traceback, building the aligned sequences backwards
+	var ra, rb []byte
+	if m == semiGlobal {
+		for k := n - 1; k >= endI; k-- {
+			ra, rb = append(ra, a[k]), append(rb, Gap)
+		}
+		for k := w - 1; k >= endJ; k-- {
+			ra, rb = append(ra, Gap), append(rb, b[k])
+		}
+	}
+	i, j, state := endI, endJ, endState
+	for (i > 0 || j > 0) && state != start {
+		next := getTrace(trace, i*cols+j, state)
+		switch state {
+		case match:
+			ra, rb = append(ra, a[i-1]), append(rb, b[j-1])
+			i, j = i-1, j-1
+		case gapB:
+			ra, rb = append(ra, a[i-1]), append(rb, Gap)
+			i--
+		case gapA:
+			ra, rb = append(ra, Gap), append(rb, b[j-1])
+			j--
+		}
+		state = next
+	}
+	reverse(ra)
+	reverse(rb)
+
+	return &Alignment{A: string(ra), B: string(rb), Score: endScore, StartA: i, StartB: j}
+}
+
+// setTrace stores the previous state of a state in a traceback cell, 2 bits for each of the 3 states.
+func setTrace(trace []byte, cell int, state int, previous int) {
+	trace[cell] |= byte(previous) << (2 * state)
+}
+
+// getTrace returns the previous state of a state in a traceback cell.
+func getTrace(trace []byte, cell int, state int) int {
+	return int(trace[cell]>>(2*state)) & 3
+}
+
+func reverse(s []byte) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// Pairs returns the 0-based positions in B aligned to each position in A, excluding gaps.
+func (al *Alignment) Pairs() map[int]int {
+	pairs := make(map[int]int)
+	i, j := al.StartA, al.StartB
+	for k := 0; k < len(al.A); k++ {
+		if al.A[k] != Gap && al.B[k] != Gap {
+			pairs[i] = j
+		}
+		if al.A[k] != Gap {
+			i++
+		}
+		if al.B[k] != Gap {
+			j++
+		}
+	}
+	return pairs
+}
+
+// Aligned returns the number of columns without gaps.
+func (al *Alignment) Aligned() int {
+	n := 0
+	for k := 0; k < len(al.A); k++ {
+		if al.A[k] != Gap && al.B[k] != Gap {
+			n++
+		}
+	}
+	return n
+}
+
+// Identity returns the fraction of identical letters among the columns without gaps.
+func (al *Alignment) Identity() float64 {
+	identical, aligned := 0, 0
+	for k := 0; k < len(al.A); k++ {
+		if al.A[k] != Gap && al.B[k] != Gap {
+			aligned++
+			if al.A[k] == al.B[k] {
+				identical++
+			}
+		}
+	}
+	if aligned == 0 {
+		return 0
+	}
+	return float64(identical) / float64(aligned)
+}
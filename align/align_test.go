@@ -0,0 +1,73 @@
+package align
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBLOSUM62(t *testing.T) {
+	letters := "ARNDCQEGHILKMFPSTWYVBZX*"
+	for i := 0; i < len(letters); i++ {
+		for j := 0; j < len(letters); j++ {
+			if BLOSUM62.Score(letters[i], letters[j]) != BLOSUM62.Score(letters[j], letters[i]) {
+				t.Errorf("asymmetric score for %c %c", letters[i], letters[j])
+			}
+		}
+	}
+	if BLOSUM62.Score('W', 'W') != 11 || BLOSUM62.Score('a', 'A') != 4 || BLOSUM62.Score('U', 'A') != 0 {
+		t.Errorf("unexpected scores")
+	}
+}
+
+func TestGlobal(t *testing.T) {
+	// Insulin B chain with an internal deletion of 6 residues
+	a := "FVNQHLCGSHLVEALYLVCGERGFFYTPKT"
+	b := "FVNQHLCGSHLVEALYGFFYTPKT"
+	al := Global(a, b, DefaultScoring)
+	if strings.Replace(al.A, "-", "", -1) != a || strings.Replace(al.B, "-", "", -1) != b {
+		t.Fatalf("aligned sequences differ from the input: %s %s", al.A, al.B)
+	}
+	if al.Identity() != 1 || al.Aligned() != len(b) {
+		t.Errorf("expected %d identical columns, got %d with identity %.2f", len(b), al.Aligned(), al.Identity())
+	}
+	if strings.Count(al.B, "-") != 6 || !strings.Contains(al.B, "------") {
+		t.Errorf("expected a single gap of 6, got %s", al.B)
+	}
+
+	pairs := al.Pairs()
+	if pairs[0] != 0 || pairs[len(a)-1] != len(b)-1 {
+		t.Errorf("unexpected ends %v", pairs)
+	}
+	if pairs[22] != 16 {
+		t.Errorf("position after the deletion aligned to %d, expected 16", pairs[22])
+	}
+}
+
+func TestSemiGlobal(t *testing.T) {
+	// Observed fragment with an expression tag, against the full sequence
+	a := "MGSSHHHHHHGIVEQCCTSICSLYQLENYCN"
+	b := "GIVEQCCTSICSLYQLENYCN"
+	al := SemiGlobal(a, b, DefaultScoring)
+	pairs := al.Pairs()
+	for i := 0; i < len(b); i++ {
+		if pairs[i+10] != i {
+			t.Fatalf("position %d aligned to %d, expected %d", i+10, pairs[i+10], i)
+		}
+	}
+	if len(pairs) != len(b) {
+		t.Errorf("expected %d aligned positions, got %d", len(b), len(pairs))
+	}
+}
+
+func TestLocal(t *testing.T) {
+	a := "AAAAAAAAWHCYWWKCMPPP"
+	b := "GGGWHCYWWKCMGGG"
+	al := Local(a, b, DefaultScoring)
+	if al.A != "WHCYWWKCM" || al.B != "WHCYWWKCM" || al.StartA != 8 || al.StartB != 3 {
+		t.Errorf("unexpected local alignment %s at %d, %s at %d", al.A, al.StartA, al.B, al.StartB)
+	}
+
+	if empty := Local("WWW", "", DefaultScoring); empty.Aligned() != 0 {
+		t.Errorf("expected empty alignment")
+	}
+}
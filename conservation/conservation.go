@@ -15,6 +15,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/tikz/bio/fasta"
 	"github.com/tikz/bio/pdb"
 	"github.com/tikz/bio/uniprot"
 )
@@ -75,7 +76,10 @@ func (pfam *Pfam) Families(unp *uniprot.UniProt) (fams []*Family, err error) {
 
 	// Write temporary FASTA of sequence
 	fastaPath := os.TempDir() + "/" + unp.ID + ".fasta"
-	err = ioutil.WriteFile(fastaPath, []byte(">"+unp.ID+"\n"+unp.Sequence), 0644)
+	raw, err := fasta.Format(&fasta.Record{ID: unp.ID, Sequence: unp.Sequence})
+	if err == nil {
+		err = ioutil.WriteFile(fastaPath, raw, 0644)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("write FASTA: %v", err)
 	}
@@ -257,7 +261,14 @@ func align(hmmPath string, fastaPath string) (mappings [][2]int, err error) {
 		return nil, errors.New(string(out))
 	}
 
-	alignSeq := parseFASTA(string(out))
+	records, err := fasta.Parse(out)
+	if err != nil {
+		return nil, fmt.Errorf("parse alignment: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("empty alignment")
+	}
+	alignSeq := records[0].Sequence
 
 	modelIndex, seqIndex, alignSeqIndex := 0, 0, 0
 
@@ -280,12 +291,3 @@ func align(hmmPath string, fastaPath string) (mappings [][2]int, err error) {
 
 	return mappings, nil
 }
-
-func parseFASTA(txt string) (sequence string) {
-	for _, line := range strings.Split(string(txt), "\n") {
-		if len(line) > 0 && string(line[0]) != ">" {
-			sequence += line
-		}
-	}
-	return sequence
-}
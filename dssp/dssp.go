@@ -1,19 +1,31 @@
 package dssp
 
 import (
+	"errors"
 	"os/exec"
-	"strconv"
-	"strings"
 
 	"github.com/tikz/bio/pdb"
 )
 
+// Engines that can assign the secondary structure.
+const (
+	EngineMkDSSP = "mkdssp"
+	EngineNative = "native"
+)
+
+// Results holds the secondary structure one-letter code of each residue, and the full DSSP records when parsed from mkdssp output.
 type Results struct {
 	Residues map[*pdb.Residue]string
+	Records  map[*pdb.Residue]*Record
+	Engine   string // EngineMkDSSP or EngineNative
 }
 
-// DSSP calculates secondary structure for a given PDB.
+// DSSP calculates secondary structure for a given PDB using mkdssp on its local PDB file.
 func DSSP(p *pdb.PDB) (results Results, err error) {
+	if p.PDBPath == "" {
+		return results, errors.New("no local PDB file")
+	}
+
 	cmd := exec.Command("mkdssp", "-i", p.PDBPath)
 
 	out, err := cmd.CombinedOutput()
@@ -21,29 +33,14 @@ func DSSP(p *pdb.PDB) (results Results, err error) {
 		return
 	}
 
-	results.Residues = make(map[*pdb.Residue]string)
-
-	// https://swift.cmbi.umcn.nl/gv/dssp/
-	start := false
-	for _, l := range strings.Split(string(out), "\n") {
-		if len(l) > 17 {
-			if start {
-				posStr := strings.TrimSpace(l[5:10])
-				if len(posStr) > 0 {
-					chain := string(l[11])
-					pos, _ := strconv.ParseInt(posStr, 10, 64)
-					if chain, ok := p.Chains[chain]; ok {
-						if res, ok := chain[pos]; ok {
-							results.Residues[res] = string(l[16])
-						}
-					}
-				}
-			}
-			if string(l[2]) == "#" {
-				start = true
-			}
-		}
-	}
+	return Parse(p, out)
+}
 
-	return
+// DSSPOrNative calculates secondary structure using mkdssp as DSSP does, or the native implementation
+// if mkdssp is not installed or there is no local PDB file. Results.Engine reports which one ran.
+func DSSPOrNative(p *pdb.PDB) (results Results, err error) {
+	if _, err := exec.LookPath("mkdssp"); err != nil || p.PDBPath == "" {
+		return Native(p)
+	}
+	return DSSP(p)
 }
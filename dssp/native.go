@@ -0,0 +1,453 @@
+package dssp
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/tikz/bio/pdb"
+)
+
+const (
+	couplingConstant     = 0.084 * 332 // q1 * q2 * f in kcal/mol*A
+	minHBondEnergy       = -9.9        // kcal/mol
+	maxHBondEnergy       = -0.5        // kcal/mol
+	minAtomDistance      = 0.5         // A
+	minCADistance        = 9.0         // A, residues further apart cannot be H-bonded
+	maxPeptideBondLength = 2.5         // A, C-N distance beyond which there is a chain break
+	minBendAngle         = 70.0        // degrees
+	minPPStretch         = 3           // residues
+)
+
+// hbond is an H-bond partner of a residue, by index in the backbone list.
+type hbond struct {
+	partner int
+	energy  float64
+}
+
+// bridgeType is the type of a beta bridge.
+type bridgeType int
+
+const (
+	parallel bridgeType = iota
+	antiparallel
+)
+
+type bridge struct {
+	i, j int
+	kind bridgeType
+}
+
+// backbone holds the backbone coordinates and assignment state of a residue.
+type backbone struct {
+	res               *pdb.Residue
+	n, ca, c, o, h    [3]float64
+	proline           bool
+	chainBreak        bool     // not bonded to the previous residue in the list
+	acceptors, donors [2]hbond // two lowest energy H-bonds where the residue is the N-H donor or the C=O acceptor
+	turns             [6]bool  // n-turn starting at the residue, for n = 3, 4, 5
+	ss                byte
+}
+
+// Native calculates secondary structure for a given PDB in pure Go, using the Kabsch-Sander algorithm
+// as implemented by mkdssp: backbone H-bonds by electrostatic energy, beta bridges and ladders (E, B),
+// alpha, 3-10, pi and polyproline helices (H, G, I, P), turns (T) and bends (S).
+// https://doi.org/10.1002/bip.360221211
+// Residues without a complete backbone are not assigned.
+func Native(p *pdb.PDB) (results Results, err error) {
+	bb := backboneResidues(p)
+	if len(bb) == 0 {
+		return results, errors.New("no residues with complete backbone")
+	}
+
+	calculateHBonds(bb)
+	assignBetaSheets(bb)
+	assignHelices(bb)
+	assignTurnsAndBends(bb)
+	assignPPHelices(bb)
+
+	results.Engine = EngineNative
+	results.Residues = make(map[*pdb.Residue]string)
+	for _, r := range bb {
+		results.Residues[r.res] = string(r.ss)
+	}
+
+	return results, nil
+}
+
+func coords(a *pdb.Atom) [3]float64 {
+	return [3]float64{a.X, a.Y, a.Z}
+}
+
+func distance(a [3]float64, b [3]float64) float64 {
+	return math.Sqrt((a[0]-b[0])*(a[0]-b[0]) + (a[1]-b[1])*(a[1]-b[1]) + (a[2]-b[2])*(a[2]-b[2]))
+}
+
+// backboneResidues lists the residues with complete backbone, chains ordered as in the file.
+func backboneResidues(p *pdb.PDB) []*backbone {
+	var chains [][]*pdb.Residue
+	for _, chain := range p.Residues {
+		if len(chain) > 0 && len(chain[0].Atoms) > 0 {
+			chains = append(chains, chain)
+		}
+	}
+	sort.Slice(chains, func(i, j int) bool {
+		return chains[i][0].Atoms[0].Number < chains[j][0].Atoms[0].Number
+	})
+
+	var bb []*backbone
+	for _, chain := range chains {
+		var prev *backbone
+		for _, res := range chain {
+			n, ca, c, o := res.Atom("N"), res.Atom("CA"), res.Atom("C"), res.Atom("O")
+			if n == nil || ca == nil || c == nil || o == nil {
+				prev = nil
+				continue
+			}
+
+			r := &backbone{
+				res:     res,
+				n:       coords(n),
+				ca:      coords(ca),
+				c:       coords(c),
+				o:       coords(o),
+				proline: res.Name1 == "P",
+				ss:      ' ',
+			}
+			r.acceptors = [2]hbond{{-1, 0}, {-1, 0}}
+			r.donors = [2]hbond{{-1, 0}, {-1, 0}}
+
+			// The amide hydrogen lies opposite to the previous carbonyl oxygen
+			r.h = r.n
+			r.chainBreak = prev == nil || distance(prev.c, r.n) > maxPeptideBondLength
+			if !r.chainBreak {
+				co := distance(prev.c, prev.o)
+				for k := range r.h {
+					r.h[k] += (prev.c[k] - prev.o[k]) / co
+				}
+			}
+
+			bb = append(bb, r)
+			prev = r
+		}
+	}
+
+	return bb
+}
+
+// noChainBreak reports whether the residues from i to j are consecutive and bonded.
+func noChainBreak(bb []*backbone, i int, j int) bool {
+	if i < 0 || j >= len(bb) {
+		return false
+	}
+	for k := i + 1; k <= j; k++ {
+		if bb[k].chainBreak {
+			return false
+		}
+	}
+	return true
+}
+
+// hbondEnergy calculates the electrostatic energy of the H-bond between the N-H of the donor and the C=O of the acceptor.
+func hbondEnergy(donor *backbone, acceptor *backbone) float64 {
+	if donor.proline {
+		return 0
+	}
+
+	dHO := distance(donor.h, acceptor.o)
+	dHC := distance(donor.h, acceptor.c)
+	dNC := distance(donor.n, acceptor.c)
+	dNO := distance(donor.n, acceptor.o)
+	if dHO < minAtomDistance || dHC < minAtomDistance || dNC < minAtomDistance || dNO < minAtomDistance {
+		return minHBondEnergy
+	}
+
+	energy := couplingConstant/dNO + couplingConstant/dHC - couplingConstant/dHO - couplingConstant/dNC
+	energy = math.Round(energy*1000) / 1000
+
+	return math.Max(energy, minHBondEnergy)
+}
+
+// keepLowest keeps the two lowest energy H-bonds.
+func keepLowest(bonds *[2]hbond, partner int, energy float64) {
+	if energy < bonds[0].energy {
+		bonds[1] = bonds[0]
+		bonds[0] = hbond{partner, energy}
+	} else if energy < bonds[1].energy {
+		bonds[1] = hbond{partner, energy}
+	}
+}
+
+func calculateHBonds(bb []*backbone) {
+	cas := make([]*pdb.Atom, len(bb))
+	index := make(map[*pdb.Atom]int)
+	for i, r := range bb {
+		cas[i] = &pdb.Atom{X: r.ca[0], Y: r.ca[1], Z: r.ca[2]}
+		index[cas[i]] = i
+	}
+
+	idx := pdb.NewIndex(cas, minCADistance)
+	for i, r := range bb {
+		for _, near := range idx.WithinAtom(cas[i], minCADistance) {
+			j := index[near]
+			if j <= i || distance(r.ca, bb[j].ca) >= minCADistance {
+				continue
+			}
+
+			energy := hbondEnergy(r, bb[j])
+			keepLowest(&r.acceptors, j, energy)
+			keepLowest(&bb[j].donors, i, energy)
+
+			if j != i+1 {
+				energy = hbondEnergy(bb[j], r)
+				keepLowest(&bb[j].acceptors, i, energy)
+				keepLowest(&r.donors, j, energy)
+			}
+		}
+	}
+}
+
+// hbonded reports whether the C=O of residue i is H-bonded to the N-H of residue j.
+func hbonded(bb []*backbone, i int, j int) bool {
+	if i < 0 || j < 0 || i >= len(bb) || j >= len(bb) {
+		return false
+	}
+	for _, b := range bb[j].acceptors {
+		if b.partner == i && b.energy < maxHBondEnergy {
+			return true
+		}
+	}
+	return false
+}
+
+func assignBetaSheets(bb []*backbone) {
+	var bridges []bridge
+	for i := 1; i+1 < len(bb); i++ {
+		if !noChainBreak(bb, i-1, i+1) {
+			continue
+		}
+		for j := i + 3; j+1 < len(bb); j++ {
+			if !noChainBreak(bb, j-1, j+1) {
+				continue
+			}
+
+			switch {
+			case (hbonded(bb, i-1, j) && hbonded(bb, j, i+1)) || (hbonded(bb, j-1, i) && hbonded(bb, i, j+1)):
+				bridges = append(bridges, bridge{i, j, parallel})
+			case (hbonded(bb, i, j) && hbonded(bb, j, i)) || (hbonded(bb, i-1, j+1) && hbonded(bb, j-1, i+1)):
+				bridges = append(bridges, bridge{i, j, antiparallel})
+			}
+		}
+	}
+
+	// Ladders are sets of one or more consecutive bridges of identical type
+	var ladders [][]bridge
+	for _, b := range bridges {
+		extended := false
+		for l, ladder := range ladders {
+			last := ladder[len(ladder)-1]
+			next := last.j + 1
+			if b.kind == antiparallel {
+				next = last.j - 1
+			}
+			if last.kind == b.kind && b.i == last.i+1 && b.j == next &&
+				noChainBreak(bb, last.i, b.i) && noChainBreak(bb, minInt(last.j, b.j), maxInt(last.j, b.j)) {
+				ladders[l] = append(ladder, b)
+				extended = true
+				break
+			}
+		}
+		if !extended {
+			ladders = append(ladders, []bridge{b})
+		}
+	}
+
+	// Ladders connected by a beta bulge are part of the same strand
+	linked := make([]bool, len(ladders))
+	for a := range ladders {
+		for b := a + 1; b < len(ladders); b++ {
+			if ladders[a][0].kind != ladders[b][0].kind {
+				continue
+			}
+			ibi, iei, jbi, jei := ladderBounds(ladders[a])
+			ibj, iej, jbj, jej := ladderBounds(ladders[b])
+			if ibj <= iei || ibj-iei >= 6 || !noChainBreak(bb, ibi, iej) {
+				continue
+			}
+
+			var bulge bool
+			if ladders[a][0].kind == parallel {
+				bulge = jbj > jei && noChainBreak(bb, jbi, jej) && ((jbj-jei < 6 && ibj-iei < 3) || jbj-jei < 3)
+			} else {
+				bulge = jbi > jej && noChainBreak(bb, jbj, jei) && ((jbi-jej < 6 && ibj-iei < 3) || jbi-jej < 3)
+			}
+			if bulge {
+				linked[a], linked[b] = true, true
+				for k := iei; k <= ibj; k++ {
+					bb[k].ss = 'E'
+				}
+				if ladders[a][0].kind == parallel {
+					for k := jei; k <= jbj; k++ {
+						bb[k].ss = 'E'
+					}
+				} else {
+					for k := jej; k <= jbi; k++ {
+						bb[k].ss = 'E'
+					}
+				}
+			}
+		}
+	}
+
+	for l, ladder := range ladders {
+		ib, ie, jb, je := ladderBounds(ladder)
+		if len(ladder) > 1 || linked[l] {
+			for k := ib; k <= ie; k++ {
+				bb[k].ss = 'E'
+			}
+			for k := jb; k <= je; k++ {
+				bb[k].ss = 'E'
+			}
+			continue
+		}
+
+		for _, k := range []int{ladder[0].i, ladder[0].j} {
+			if bb[k].ss != 'E' {
+				bb[k].ss = 'B'
+			}
+		}
+	}
+}
+
+// ladderBounds returns the first and last residue of each side of a ladder.
+func ladderBounds(ladder []bridge) (ib int, ie int, jb int, je int) {
+	ib, ie = ladder[0].i, ladder[len(ladder)-1].i
+	jb, je = ladder[0].j, ladder[len(ladder)-1].j
+	if jb > je {
+		jb, je = je, jb
+	}
+	return
+}
+
+func assignHelices(bb []*backbone) {
+	for _, n := range []int{3, 4, 5} {
+		for i := 0; i+n < len(bb); i++ {
+			bb[i].turns[n] = noChainBreak(bb, i, i+n) && hbonded(bb, i, i+n)
+		}
+	}
+
+	// Two consecutive n-turns make a minimal helix, alpha helices have priority over every other structure
+	for i := 1; i+4 < len(bb); i++ {
+		if bb[i].turns[4] && bb[i-1].turns[4] {
+			for k := i; k < i+4; k++ {
+				bb[k].ss = 'H'
+			}
+		}
+	}
+
+	helix := func(n int, ss byte, replaces string) {
+		for i := 1; i+n < len(bb); i++ {
+			if !bb[i].turns[n] || !bb[i-1].turns[n] {
+				continue
+			}
+			empty := true
+			for k := i; k < i+n; k++ {
+				if bb[k].ss != ' ' && bb[k].ss != ss && !containsByte(replaces, bb[k].ss) {
+					empty = false
+				}
+			}
+			if empty {
+				for k := i; k < i+n; k++ {
+					bb[k].ss = ss
+				}
+			}
+		}
+	}
+	helix(3, 'G', "")
+	helix(5, 'I', "H")
+}
+
+func assignTurnsAndBends(bb []*backbone) {
+	for i := range bb {
+		for _, n := range []int{3, 4, 5} {
+			if !bb[i].turns[n] {
+				continue
+			}
+			for k := i + 1; k < i+n; k++ {
+				if bb[k].ss == ' ' {
+					bb[k].ss = 'T'
+				}
+			}
+		}
+	}
+
+	for i := 2; i+2 < len(bb); i++ {
+		if bb[i].ss != ' ' || !noChainBreak(bb, i-2, i+2) {
+			continue
+		}
+		if kappa(bb[i-2].ca, bb[i].ca, bb[i+2].ca) > minBendAngle {
+			bb[i].ss = 'S'
+		}
+	}
+}
+
+// kappa returns the angle in degrees between the CA(i-2) to CA(i) and CA(i) to CA(i+2) vectors.
+func kappa(prev [3]float64, ca [3]float64, next [3]float64) float64 {
+	var u, v [3]float64
+	for k := range u {
+		u[k] = ca[k] - prev[k]
+		v[k] = next[k] - ca[k]
+	}
+	cos := (u[0]*v[0] + u[1]*v[1] + u[2]*v[2]) / (distance(ca, prev) * distance(next, ca))
+	return math.Acos(math.Max(-1, math.Min(1, cos))) * 180 / math.Pi
+}
+
+// assignPPHelices assigns polyproline II helices to stretches of unassigned residues with phi and psi near -75 and 145.
+func assignPPHelices(bb []*backbone) {
+	inPP := make([]bool, len(bb))
+	for i, r := range bb {
+		phi, psi := r.res.Phi(), r.res.Psi()
+		inPP[i] = math.Abs(phi+75) <= 29 && math.Abs(psi-145) <= 29
+	}
+
+	for i := 0; i+minPPStretch <= len(bb); i++ {
+		if !noChainBreak(bb, i, i+minPPStretch-1) {
+			continue
+		}
+		stretch := true
+		for k := i; k < i+minPPStretch; k++ {
+			stretch = stretch && inPP[k]
+		}
+		if !stretch {
+			continue
+		}
+		for k := i; k < i+minPPStretch; k++ {
+			if bb[k].ss == ' ' {
+				bb[k].ss = 'P'
+			}
+		}
+	}
+}
+
+func containsByte(s string, b byte) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] == b {
+			return true
+		}
+	}
+	return false
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,167 @@
+package dssp
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"testing"
+
+	"github.com/tikz/bio/pdb"
+)
+
+func TestNative(t *testing.T) {
+	p := loadTestPDB(t)
+
+	results, err := Native(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results.Residues) != 102 {
+		t.Errorf("expected 102 residues, got %d", len(results.Residues))
+	}
+
+	// Secondary structure as in the HELIX and SHEET records of the file, where the first
+	// and last residues of each helix are not part of the DSSP helix.
+	expected := []struct {
+		chain    string
+		from, to int64
+		ss       string
+	}{
+		{"A", 2, 8, "HI"},
+		{"A", 13, 17, "HG"},
+		{"B", 9, 19, "H"},
+		{"C", 2, 6, "HI"},
+		{"C", 13, 16, "HG"},
+		{"D", 9, 19, "H"},
+		{"B", 24, 26, "E"},
+		{"D", 24, 26, "E"},
+	}
+	for _, e := range expected {
+		for pos := e.from; pos <= e.to; pos++ {
+			res := p.Chains[e.chain][pos]
+			ss := results.Residues[res]
+			if len(ss) != 1 || !containsByte(e.ss, ss[0]) {
+				t.Errorf("expected %s in %s-%d, got %q", e.ss, e.chain, pos, ss)
+			}
+		}
+	}
+
+	if ss := results.Residues[p.Chains["B"][1]]; ss != " " {
+		t.Errorf("expected loop in B-1, got %q", ss)
+	}
+}
+
+// maxNativeMismatches is the number of residues of 1mso whose native assignment may differ from mkdssp,
+// allowing for rounding differences in H-bond energies near the cutoff.
+const maxNativeMismatches = 3
+
+// referenceDSSP returns the mkdssp results for 1mso from testdata/name if present, or by running mkdssp
+// if installed, skipping the test if neither is available. The reference is generated with
+// mkdssp -i ../pdb/testdata/1mso.pdb -o testdata/1mso.dssp.
+func referenceDSSP(t *testing.T, p *pdb.PDB, name string) Results {
+	out, err := ioutil.ReadFile("testdata/" + name)
+	if err == nil {
+		results, err := Parse(p, out)
+		if err != nil {
+			t.Fatalf("parse %s: %v", name, err)
+		}
+		return results
+	}
+	if _, err := exec.LookPath("mkdssp"); err != nil {
+		t.Skipf("neither testdata/%s nor mkdssp available", name)
+	}
+
+	p.PDBPath = "../pdb/testdata/1mso.pdb"
+	results, err := DSSP(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return results
+}
+
+func TestNativeReference(t *testing.T) {
+	p := loadTestPDB(t)
+	reference := referenceDSSP(t, p, "1mso.dssp")
+
+	results, err := Native(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results.Engine != EngineNative || reference.Engine != EngineMkDSSP {
+		t.Errorf("unexpected engines %s and %s", results.Engine, reference.Engine)
+	}
+
+	// mkdssp before version 4 does not assign polyproline helices
+	var pp bool
+	for _, ss := range reference.Residues {
+		pp = pp || ss == "P"
+	}
+
+	var mismatches int
+	for res, expected := range reference.Residues {
+		ss := results.Residues[res]
+		if ss == "P" && !pp {
+			ss = " "
+		}
+		if ss != expected {
+			mismatches++
+			t.Logf("%s-%d: native %q, mkdssp %q", res.Chain, res.StructPosition, ss, expected)
+		}
+	}
+	if len(reference.Residues) != len(results.Residues) {
+		t.Errorf("mkdssp assigned %d residues, native %d", len(reference.Residues), len(results.Residues))
+	}
+	if mismatches > maxNativeMismatches {
+		t.Errorf("%d residues differ from mkdssp, expected at most %d", mismatches, maxNativeMismatches)
+	}
+}
+
+func TestDSSPOrNative(t *testing.T) {
+	p := loadTestPDB(t)
+	if _, err := DSSP(p); err == nil {
+		t.Errorf("expected error without a local PDB file")
+	}
+
+	results, err := DSSPOrNative(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results.Engine != EngineNative || len(results.Residues) == 0 {
+		t.Errorf("expected native results, got %s", results.Engine)
+	}
+}
+
+func TestBetaBulge(t *testing.T) {
+	// Antiparallel single bridge ladders between residues i and j, with the given strand positions
+	sheet := func(ladders ...[2]int) []*backbone {
+		bb := make([]*backbone, 60)
+		for k := range bb {
+			bb[k] = &backbone{ss: ' '}
+		}
+		for _, l := range ladders {
+			bb[l[1]].acceptors[0] = hbond{partner: l[0], energy: -2}
+			bb[l[0]].acceptors[0] = hbond{partner: l[1], energy: -2}
+		}
+		assignBetaSheets(bb)
+		return bb
+	}
+
+	// Ladders 3 residues apart on the i strand are linked by a bulge
+	bb := sheet([2]int{10, 50}, [2]int{13, 48})
+	for _, k := range []int{10, 11, 12, 13, 48, 49, 50} {
+		if bb[k].ss != 'E' {
+			t.Errorf("expected strand in bulge residue %d, got %q", k, bb[k].ss)
+		}
+	}
+
+	// Ladders more than 5 residues apart are not, even if close on the j strand
+	bb = sheet([2]int{10, 50}, [2]int{18, 48})
+	for k := 10; k <= 18; k++ {
+		expected := byte(' ')
+		if k == 10 || k == 18 {
+			expected = 'B'
+		}
+		if bb[k].ss != expected {
+			t.Errorf("expected %q in residue %d, got %q", expected, k, bb[k].ss)
+		}
+	}
+}
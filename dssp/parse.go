@@ -0,0 +1,381 @@
+package dssp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tikz/bio/pdb"
+)
+
+// Record holds all the DSSP data of a single residue.
+type Record struct {
+	Number         int64           `json:"number"`        // DSSP sequential residue number
+	SS             string          `json:"ss"`            // secondary structure one-letter code, blank for loops
+	Helix          [4]string       `json:"helix"`         // 3-10, alpha, pi and polyproline (DSSP 4) helix flags: >, <, X or the helix number
+	Bend           bool            `json:"bend"`          // kappa angle greater than 70 degrees
+	Chirality      string          `json:"chirality"`     // sign of the alpha angle: + or -
+	BridgeLabels   [2]string       `json:"bridgeLabels"`  // ladder labels, lowercase for parallel and uppercase for antiparallel
+	BridgePartners [2]*pdb.Residue `json:"-"`             // bridge partner residues, nil if none
+	Sheet          string          `json:"sheet"`         // sheet label
+	Accessibility  float64         `json:"accessibility"` // solvent accessible surface area in A^2
+	NHO            [2]HBond        `json:"nho"`           // two lowest energy N-H-->O H-bonds, residue as donor
+	OHN            [2]HBond        `json:"ohn"`           // two lowest energy O-->H-N H-bonds, residue as acceptor
+	TCO            float64         `json:"tco"`           // cosine of the angle between C=O of the residue and C=O of the previous one
+	Kappa          float64         `json:"kappa"`         // virtual bond angle defined by CA(i-2), CA(i) and CA(i+2), 360 if undefined
+	Alpha          float64         `json:"alpha"`         // virtual torsion angle defined by CA(i-1), CA(i), CA(i+1) and CA(i+2), 360 if undefined
+	Phi            float64         `json:"phi"`           // 360 if undefined
+	Psi            float64         `json:"psi"`           // 360 if undefined
+}
+
+// HBond is a backbone H-bond partner of a residue.
+type HBond struct {
+	Offset  int64        `json:"offset"` // partner DSSP number minus the residue DSSP number, 0 if none
+	Energy  float64      `json:"energy"` // kcal/mol
+	Partner *pdb.Residue `json:"-"`
+}
+
+// Parse parses the output of mkdssp, either in the classic DSSP format or the mmCIF format of mkdssp 4,
+// and matches each record to the residues of the given structure. Residues not found are skipped.
+func Parse(p *pdb.PDB, out []byte) (results Results, err error) {
+	if bytes.HasPrefix(bytes.TrimSpace(out), []byte("data_")) {
+		return parseCIF(p, out)
+	}
+	return parseClassic(p, out)
+}
+
+func newResults() Results {
+	return Results{
+		Residues: make(map[*pdb.Residue]string),
+		Records:  make(map[*pdb.Residue]*Record),
+		Engine:   EngineMkDSSP,
+	}
+}
+
+var dsspVersion = regexp.MustCompile(`DSSP.*?version ([0-9]+)`)
+
+// parseClassic parses the classic fixed-column DSSP format.
+// https://swift.cmbi.umcn.nl/gv/dssp/DSSP_3.html
+func parseClassic(p *pdb.PDB, out []byte) (results Results, err error) {
+	results = newResults()
+
+	version := 2
+	if m := dsspVersion.FindSubmatch(out); m != nil {
+		version, _ = strconv.Atoi(string(m[1]))
+	}
+
+	var header string
+	var trailing []string // names of the columns after the CA coordinates, such as CHAIN and AUTHCHAIN in DSSP 4
+	byNumber := make(map[int64]*pdb.Residue)
+	var records []*Record
+	partners := make(map[*Record][2]int64)
+
+	for _, l := range strings.Split(string(out), "\n") {
+		if header == "" {
+			if strings.HasPrefix(l, "  #  RESIDUE") {
+				header = l
+				if len(l) > 136 {
+					trailing = strings.Fields(l[136:])
+				}
+			}
+			continue
+		}
+		if len(l) < 136 || l[13] == '!' {
+			continue // chain breaks
+		}
+
+		chain := string(l[11])
+		if len(trailing) > 0 {
+			fields := strings.Fields(l[136:])
+			for i, name := range trailing {
+				if i < len(fields) && (name == "AUTHCHAIN" || (name == "CHAIN" && chain == ">")) {
+					chain = fields[i]
+				}
+			}
+		}
+
+		number, err := strconv.ParseInt(strings.TrimSpace(l[0:5]), 10, 64)
+		if err != nil {
+			return results, fmt.Errorf("invalid DSSP number %s: %v", l[0:5], err)
+		}
+		pos, err := strconv.ParseInt(strings.TrimSpace(l[5:10]), 10, 64)
+		if err != nil {
+			return results, fmt.Errorf("invalid residue number %s: %v", l[5:10], err)
+		}
+		insertionCode := strings.TrimSpace(string(l[10]))
+
+		r := &Record{
+			Number:        number,
+			SS:            string(l[16]),
+			Bend:          l[21] == 'S',
+			Chirality:     strings.TrimSpace(string(l[22])),
+			BridgeLabels:  [2]string{strings.TrimSpace(string(l[23])), strings.TrimSpace(string(l[24]))},
+			Sheet:         strings.TrimSpace(string(l[33])),
+			Accessibility: parseFloat(l[34:38]),
+			TCO:           parseFloat(l[85:91]),
+			Kappa:         parseFloat(l[91:97]),
+			Alpha:         parseFloat(l[97:103]),
+			Phi:           parseFloat(l[103:109]),
+			Psi:           parseFloat(l[109:115]),
+		}
+		if version >= 4 {
+			r.Helix = [4]string{flag(l[18]), flag(l[19]), flag(l[20]), flag(l[17])} // PP column before the others
+		} else {
+			r.Helix = [4]string{flag(l[18]), flag(l[19]), flag(l[20]), ""}
+		}
+
+		r.NHO[0], err = parseHBond(l[39:50])
+		if err == nil {
+			r.OHN[0], err = parseHBond(l[50:61])
+		}
+		if err == nil {
+			r.NHO[1], err = parseHBond(l[61:72])
+		}
+		if err == nil {
+			r.OHN[1], err = parseHBond(l[72:83])
+		}
+		if err != nil {
+			return results, fmt.Errorf("DSSP residue %d: %v", number, err)
+		}
+
+		bp1, _ := strconv.ParseInt(strings.TrimSpace(l[25:29]), 10, 64)
+		bp2, _ := strconv.ParseInt(strings.TrimSpace(l[29:33]), 10, 64)
+		partners[r] = [2]int64{bp1, bp2}
+		records = append(records, r)
+
+		if res := p.ResidueAt(chain, pos, insertionCode); res != nil {
+			byNumber[number] = res
+			results.Residues[res] = r.SS
+			results.Records[res] = r
+		}
+	}
+
+	if header == "" {
+		return results, errors.New("DSSP residues header not found")
+	}
+
+	// Partners can only be matched once all residues are known
+	for _, r := range records {
+		for i, bp := range partners[r] {
+			if bp != 0 {
+				r.BridgePartners[i] = byNumber[bp]
+			}
+		}
+		for i := range r.NHO {
+			if r.NHO[i].Offset != 0 {
+				r.NHO[i].Partner = byNumber[r.Number+r.NHO[i].Offset]
+			}
+			if r.OHN[i].Offset != 0 {
+				r.OHN[i].Partner = byNumber[r.Number+r.OHN[i].Offset]
+			}
+		}
+	}
+
+	return results, nil
+}
+
+func flag(c byte) string {
+	return strings.TrimSpace(string(c))
+}
+
+func parseFloat(s string) float64 {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return math.NaN()
+	}
+	return f
+}
+
+// parseHBond parses an H-bond column as offset,energy (i.e. -3,-1.4).
+func parseHBond(s string) (HBond, error) {
+	split := strings.Split(strings.TrimSpace(s), ",")
+	if len(split) != 2 {
+		return HBond{}, fmt.Errorf("invalid H-bond %q", s)
+	}
+	offset, err := strconv.ParseInt(strings.TrimSpace(split[0]), 10, 64)
+	if err != nil {
+		return HBond{}, fmt.Errorf("invalid H-bond offset %q", s)
+	}
+	energy, err := strconv.ParseFloat(strings.TrimSpace(split[1]), 64)
+	if err != nil {
+		return HBond{}, fmt.Errorf("invalid H-bond energy %q", s)
+	}
+	return HBond{Offset: offset, Energy: energy}, nil
+}
+
+// labelKey identifies a residue by its mmCIF label chain and sequence IDs.
+type labelKey struct {
+	asym string
+	seq  string
+}
+
+type authID struct {
+	chain         string
+	pos           int64
+	insertionCode string
+}
+
+// parseCIF parses the _dssp_struct_summary, _dssp_struct_bridge_pairs and _dssp_struct_ladder categories of
+// the mmCIF output of mkdssp 4. Residues are identified by label IDs, translated to author IDs through the
+// _atom_site records of the same file, which can have chain IDs longer than one character.
+func parseCIF(p *pdb.PDB, out []byte) (results Results, err error) {
+	results = newResults()
+
+	cif, err := pdb.ParseCIF(out)
+	if err != nil {
+		return results, fmt.Errorf("parse CIF: %v", err)
+	}
+	if len(cif.Blocks) == 0 {
+		return results, errors.New("no data blocks")
+	}
+	block := cif.Blocks[0]
+
+	summary := block.Category("_dssp_struct_summary")
+	if summary == nil {
+		return results, errors.New("_dssp_struct_summary not found")
+	}
+
+	// Label to author IDs
+	auth := make(map[labelKey]authID)
+	if sites := block.Category("_atom_site"); sites != nil {
+		for i := 0; i < sites.Len(); i++ {
+			key := labelKey{sites.Get("label_asym_id", i), sites.Get("label_seq_id", i)}
+			if _, ok := auth[key]; ok {
+				continue
+			}
+			pos, _ := strconv.ParseInt(sites.Get("auth_seq_id", i), 10, 64)
+			auth[key] = authID{sites.Get("auth_asym_id", i), pos, sites.Get("pdbx_PDB_ins_code", i)}
+		}
+	}
+	residue := func(asym string, seq string) *pdb.Residue {
+		if id, ok := auth[labelKey{asym, seq}]; ok {
+			return p.ResidueAt(id.chain, id.pos, id.insertionCode)
+		}
+		pos, _ := strconv.ParseInt(seq, 10, 64)
+		return p.ResidueAt(asym, pos, "")
+	}
+
+	helixFlag := func(s string, n string) string {
+		switch s {
+		case "start":
+			return ">"
+		case "end":
+			return "<"
+		case "start/end":
+			return "X"
+		case "middle":
+			return n
+		}
+		return ""
+	}
+
+	records := make(map[labelKey]*Record)
+	for i := 0; i < summary.Len(); i++ {
+		key := labelKey{summary.Get("label_asym_id", i), summary.Get("label_seq_id", i)}
+		ss := summary.Get("secondary_structure", i)
+		if ss == "" {
+			ss = " "
+		}
+
+		r := &Record{
+			Number:        int64(i + 1),
+			SS:            ss,
+			Bend:          summary.Get("bend", i) == "bend",
+			Chirality:     summary.Get("chirality", i),
+			BridgeLabels:  [2]string{summary.Get("ladder_1", i), summary.Get("ladder_2", i)},
+			Sheet:         summary.Get("sheet", i),
+			Accessibility: cifFloat(summary.Get("accessibility", i), 0),
+			TCO:           cifFloat(summary.Get("tco", i), 0),
+			Kappa:         cifFloat(summary.Get("kappa", i), 360),
+			Alpha:         cifFloat(summary.Get("alpha", i), 360),
+			Phi:           cifFloat(summary.Get("phi", i), 360),
+			Psi:           cifFloat(summary.Get("psi", i), 360),
+			Helix: [4]string{
+				helixFlag(summary.Get("helix_3_10", i), "3"),
+				helixFlag(summary.Get("helix_alpha", i), "4"),
+				helixFlag(summary.Get("helix_pi", i), "5"),
+				helixFlag(summary.Get("helix_pp", i), "P"),
+			},
+		}
+		records[key] = r
+
+		if res := residue(key.asym, key.seq); res != nil {
+			results.Residues[res] = r.SS
+			results.Records[res] = r
+		}
+	}
+
+	if pairs := block.Category("_dssp_struct_bridge_pairs"); pairs != nil {
+		for i := 0; i < pairs.Len(); i++ {
+			r := records[labelKey{pairs.Get("label_asym_id", i), pairs.Get("label_seq_id", i)}]
+			if r == nil {
+				continue
+			}
+			hbond := func(prefix string) HBond {
+				key := labelKey{pairs.Get(prefix+"_label_asym_id", i), pairs.Get(prefix+"_label_seq_id", i)}
+				partner := records[key]
+				if partner == nil {
+					return HBond{}
+				}
+				return HBond{
+					Offset:  partner.Number - r.Number,
+					Energy:  cifFloat(pairs.Get(prefix+"_energy", i), 0),
+					Partner: residue(key.asym, key.seq),
+				}
+			}
+			r.NHO = [2]HBond{hbond("acceptor_1"), hbond("acceptor_2")}
+			r.OHN = [2]HBond{hbond("donor_1"), hbond("donor_2")}
+		}
+	}
+
+	// Bridge partners from the ladder ranges, which are traversed in opposite directions when antiparallel
+	if ladders := block.Category("_dssp_struct_ladder"); ladders != nil {
+		for i := 0; i < ladders.Len(); i++ {
+			asym1, asym2 := ladders.Get("beg_1_label_asym_id", i), ladders.Get("beg_2_label_asym_id", i)
+			beg1, _ := strconv.Atoi(ladders.Get("beg_1_label_seq_id", i))
+			end1, _ := strconv.Atoi(ladders.Get("end_1_label_seq_id", i))
+			beg2, _ := strconv.Atoi(ladders.Get("beg_2_label_seq_id", i))
+			end2, _ := strconv.Atoi(ladders.Get("end_2_label_seq_id", i))
+			antiparallel := ladders.Get("type", i) == "anti-parallel"
+
+			for k := 0; beg1+k <= end1; k++ {
+				seq2 := beg2 + k
+				if antiparallel {
+					seq2 = end2 - k
+				}
+				r1 := records[labelKey{asym1, strconv.Itoa(beg1 + k)}]
+				r2 := records[labelKey{asym2, strconv.Itoa(seq2)}]
+				if r1 == nil || r2 == nil {
+					continue
+				}
+				addBridgePartner(r1, residue(asym2, strconv.Itoa(seq2)))
+				addBridgePartner(r2, residue(asym1, strconv.Itoa(beg1+k)))
+			}
+		}
+	}
+
+	return results, nil
+}
+
+func addBridgePartner(r *Record, partner *pdb.Residue) {
+	for i := range r.BridgePartners {
+		if r.BridgePartners[i] == partner {
+			return
+		}
+		if r.BridgePartners[i] == nil {
+			r.BridgePartners[i] = partner
+			return
+		}
+	}
+}
+
+func cifFloat(s string, null float64) float64 {
+	if s == "" {
+		return null
+	}
+	return parseFloat(s)
+}
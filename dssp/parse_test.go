@@ -0,0 +1,169 @@
+package dssp
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/tikz/bio/pdb"
+)
+
+const classicOutput = `==== Secondary Structure Definition by the program DSSP, CMBI version 2.2.1                          ==== DATE=2021-03-01        .
+REFERENCE W. KABSCH AND C.SANDER, BIOPOLYMERS 22 (1983) 2577-2637                                                              .
+  #  RESIDUE AA STRUCTURE BP1 BP2  ACC     N-H-->O    O-->H-N    N-H-->O    O-->H-N    TCO  KAPPA ALPHA  PHI   PSI    X-CA   Y-CA   Z-CA
+    1   23 B G              0   0   40      0, 0.0     2,-0.2     0, 0.0     0, 0.0   0.000 360.0 360.0 360.0 150.0    1.0    2.0    3.0
+    2   24 B F  E     -A    4   0A  80      2,-2.5     2,-2.0     1,-0.2     1,-0.2  -0.900  20.1-150.2-120.0 130.5    1.0    2.0    3.0
+    3        !              0   0    0      0, 0.0     0, 0.0     0, 0.0     0, 0.0   0.000 360.0 360.0 360.0 360.0    0.0    0.0    0.0
+    4   24 D F  E     -A    2   0A  60     -2,-2.5    -2,-2.0     0, 0.0     0, 0.0  -0.800  10.0-160.0-110.0 140.0    1.0    2.0    3.0
+`
+
+const classicV4Output = `==== Secondary Structure Definition by the program DSSP, NKI version 4.0.4                          ==== DATE=2021-03-01        .
+REFERENCE W. KABSCH AND C.SANDER, BIOPOLYMERS 22 (1983) 2577-2637                                                              .
+  #  RESIDUE AA STRUCTURE BP1 BP2  ACC     N-H-->O    O-->H-N    N-H-->O    O-->H-N    TCO  KAPPA ALPHA  PHI   PSI    X-CA   Y-CA   Z-CA            CHAIN AUTHCHAIN
+    1   10 B H  H> 4 S+     0   0   80      2,-2.5     2,-2.0     1,-0.2     1,-0.2  -0.900  20.1-150.2-120.0 130.5    1.0    2.0    3.0            B          B
+`
+
+const cifOutput = `data_1MSO
+#
+loop_
+_atom_site.group_PDB
+_atom_site.id
+_atom_site.label_atom_id
+_atom_site.label_asym_id
+_atom_site.label_seq_id
+_atom_site.auth_asym_id
+_atom_site.auth_seq_id
+_atom_site.pdbx_PDB_ins_code
+ATOM 1 CA X 24 B 24 ?
+ATOM 2 CA Y 24 D 24 ?
+#
+loop_
+_dssp_struct_summary.entry_id
+_dssp_struct_summary.label_comp_id
+_dssp_struct_summary.label_asym_id
+_dssp_struct_summary.label_seq_id
+_dssp_struct_summary.secondary_structure
+_dssp_struct_summary.helix_alpha
+_dssp_struct_summary.bend
+_dssp_struct_summary.sheet
+_dssp_struct_summary.ladder_1
+_dssp_struct_summary.accessibility
+_dssp_struct_summary.kappa
+_dssp_struct_summary.phi
+_dssp_struct_summary.psi
+1MSO PHE X 24 E . . A A 80 20.1 -120.0 130.5
+1MSO PHE Y 24 E middle bend A A 60 . . 140.0
+#
+loop_
+_dssp_struct_bridge_pairs.id
+_dssp_struct_bridge_pairs.label_asym_id
+_dssp_struct_bridge_pairs.label_seq_id
+_dssp_struct_bridge_pairs.acceptor_1_label_asym_id
+_dssp_struct_bridge_pairs.acceptor_1_label_seq_id
+_dssp_struct_bridge_pairs.acceptor_1_energy
+_dssp_struct_bridge_pairs.donor_1_label_asym_id
+_dssp_struct_bridge_pairs.donor_1_label_seq_id
+_dssp_struct_bridge_pairs.donor_1_energy
+1 X 24 Y 24 -2.5 Y 24 -2.0
+#
+loop_
+_dssp_struct_ladder.id
+_dssp_struct_ladder.type
+_dssp_struct_ladder.beg_1_label_asym_id
+_dssp_struct_ladder.beg_1_label_seq_id
+_dssp_struct_ladder.end_1_label_seq_id
+_dssp_struct_ladder.beg_2_label_asym_id
+_dssp_struct_ladder.beg_2_label_seq_id
+_dssp_struct_ladder.end_2_label_seq_id
+A anti-parallel X 24 24 Y 24 24
+#
+`
+
+func loadTestPDB(t *testing.T) *pdb.PDB {
+	raw, err := ioutil.ReadFile("../pdb/testdata/1mso.pdb")
+	if err != nil {
+		t.Fatalf("cannot open file: %s", err)
+	}
+
+	p, err := pdb.NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestParse(t *testing.T) {
+	p := loadTestPDB(t)
+	b24, d24 := p.Chains["B"][24], p.Chains["D"][24]
+
+	for _, out := range []string{classicOutput, cifOutput} {
+		results, err := Parse(p, []byte(out))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r := results.Records[b24]
+		if r == nil || results.Residues[b24] != "E" {
+			t.Fatalf("expected strand in B-24")
+		}
+		if r.Accessibility != 80 || r.Phi != -120 || r.Psi != 130.5 || r.Kappa != 20.1 || r.Sheet != "A" || r.BridgeLabels[0] != "A" {
+			t.Errorf("unexpected record %+v", r)
+		}
+		if r.BridgePartners[0] != d24 || r.BridgePartners[1] != nil {
+			t.Errorf("expected D-24 as bridge partner")
+		}
+		if r.NHO[0].Partner != d24 || r.NHO[0].Energy != -2.5 || r.OHN[0].Partner != d24 || r.OHN[0].Energy != -2.0 {
+			t.Errorf("unexpected H-bonds %+v %+v", r.NHO[0], r.OHN[0])
+		}
+		if results.Records[d24].BridgePartners[0] != b24 {
+			t.Errorf("expected B-24 as bridge partner")
+		}
+	}
+
+	results, _ := Parse(p, []byte(classicOutput))
+	if r := results.Records[p.Chains["B"][23]]; r == nil || r.SS != " " || r.Phi != 360 || r.OHN[0].Offset != 2 {
+		t.Errorf("unexpected record for B-23 %+v", r)
+	}
+	if len(results.Records) != 3 {
+		t.Errorf("expected 3 records, got %d", len(results.Records))
+	}
+
+	// DSSP 4 has the polyproline column before the 3-10, alpha and pi ones
+	results, _ = Parse(p, []byte(classicV4Output))
+	if r := results.Records[p.Chains["B"][10]]; r == nil || r.Helix != [4]string{"", "4", "", ">"} || !r.Bend || r.Chirality != "+" {
+		t.Errorf("unexpected record for B-10 %+v", r)
+	}
+
+	results, _ = Parse(p, []byte(cifOutput))
+	if r := results.Records[d24]; r.Helix[1] != "4" || !r.Bend || r.Phi != 360 {
+		t.Errorf("unexpected record for D-24 %+v", r)
+	}
+}
+
+// TestParseReference checks the mkdssp outputs for 1mso under testdata, in the classic format of each major version.
+func TestParseReference(t *testing.T) {
+	for _, name := range []string{"1mso.dssp", "1mso.dssp4.dssp"} {
+		t.Run(name, func(t *testing.T) {
+			out, err := ioutil.ReadFile("testdata/" + name)
+			if err != nil {
+				t.Skipf("testdata/%s not available", name)
+			}
+
+			p := loadTestPDB(t)
+			results, err := Parse(p, out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results.Records) != 102 {
+				t.Errorf("expected 102 records, got %d", len(results.Records))
+			}
+			for res, r := range results.Records {
+				if r.SS == "H" && r.Helix[1] == "" {
+					t.Errorf("%s-%d: alpha helix without alpha helix flag %+v", res.Chain, res.StructPosition, r.Helix)
+				}
+				if r.SS == "G" && r.Helix[0] == "" {
+					t.Errorf("%s-%d: 3-10 helix without 3-10 helix flag %+v", res.Chain, res.StructPosition, r.Helix)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package fasta
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LineWidth is the number of residues per sequence line when writing.
+const LineWidth = 60
+
+// Record is a single FASTA sequence, with the header split into the identifier (up to the first space)
+// and the rest as description.
+type Record struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	Sequence    string `json:"sequence"`
+}
+
+// Header returns the header line of the record, without the leading >.
+func (r *Record) Header() string {
+	if r.Description == "" {
+		return r.ID
+	}
+	return r.ID + " " + r.Description
+}
+
+// Read parses all the records from a FASTA stream. Blank lines and ; comments are ignored,
+// and sequence lines are concatenated with any whitespace removed.
+func Read(r io.Reader) ([]*Record, error) {
+	var records []*Record
+	var rec *Record
+	var seq strings.Builder
+
+	flush := func() {
+		if rec != nil {
+			rec.Sequence = seq.String()
+			records = append(records, rec)
+		}
+		seq.Reset()
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case strings.TrimSpace(line) == "", strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, ">"):
+			flush()
+			fields := strings.SplitN(strings.TrimSpace(line[1:]), " ", 2)
+			rec = &Record{ID: fields[0]}
+			if len(fields) > 1 {
+				rec.Description = strings.TrimSpace(fields[1])
+			}
+		default:
+			if rec == nil {
+				return nil, fmt.Errorf("line %d: sequence before header", n)
+			}
+			seq.WriteString(strings.Join(strings.Fields(line), ""))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read FASTA: %v", err)
+	}
+	flush()
+
+	return records, nil
+}
+
+// Parse parses all the records from raw FASTA bytes.
+func Parse(raw []byte) ([]*Record, error) {
+	return Read(bytes.NewReader(raw))
+}
+
+// Write writes the records in FASTA format, wrapping sequences at LineWidth.
+func Write(w io.Writer, records ...*Record) error {
+	bw := bufio.NewWriter(w)
+	for _, r := range records {
+		if r.ID == "" {
+			return errors.New("record without ID")
+		}
+		fmt.Fprintf(bw, ">%s\n", r.Header())
+		for i := 0; i < len(r.Sequence); i += LineWidth {
+			end := i + LineWidth
+			if end > len(r.Sequence) {
+				end = len(r.Sequence)
+			}
+			fmt.Fprintf(bw, "%s\n", r.Sequence[i:end])
+		}
+	}
+	return bw.Flush()
+}
+
+// Format returns the records in FASTA format.
+func Format(records ...*Record) ([]byte, error) {
+	var b bytes.Buffer
+	err := Write(&b, records...)
+	return b.Bytes(), err
+}
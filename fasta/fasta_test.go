@@ -0,0 +1,55 @@
+package fasta
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	records := []*Record{
+		{ID: "sp|P01308|INS_HUMAN", Description: "Insulin OS=Homo sapiens OX=9606 GN=INS PE=1 SV=1", Sequence: strings.Repeat("MALWMRLLPLLALLALWGPDPAAA", 5)},
+		{ID: "1mso_A", Sequence: "GIVEQCCTSICSLYQLENYCN"},
+		{ID: "empty"},
+	}
+
+	raw, err := Format(records...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(string(raw), "\n") {
+		if len(line) > LineWidth && !strings.HasPrefix(line, ">") {
+			t.Errorf("line longer than %d: %s", LineWidth, line)
+		}
+	}
+
+	parsed, err := Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != len(records) {
+		t.Fatalf("expected %d records, got %d", len(records), len(parsed))
+	}
+	for i, r := range parsed {
+		if *r != *records[i] {
+			t.Errorf("record %d does not round-trip: %+v", i, r)
+		}
+	}
+}
+
+func TestRead(t *testing.T) {
+	raw := "; comment\r\n>seq1  first one\r\nACD EF\r\n\r\nGH\n>seq2\nKL-M\n"
+	records, err := Read(strings.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].ID != "seq1" || records[0].Description != "first one" || records[0].Sequence != "ACDEFGH" {
+		t.Errorf("unexpected first record %+v", records[0])
+	}
+	if records[1].Sequence != "KL-M" || records[1].Description != "" {
+		t.Errorf("unexpected second record %+v", records[1])
+	}
+
+	if _, err := Parse([]byte("ACDEF\n>seq\nAC\n")); err == nil {
+		t.Errorf("expected error for sequence before header")
+	}
+}
@@ -93,6 +93,9 @@ func (foldx *FoldX) Repair(p *pdb.PDB) (outFile string, err error) {
 func (foldx *FoldX) BuildModelUniProt(repairedPath string, p *pdb.PDB, unpID string, pos int64, aa string) (string, float64, error) {
 	residues := p.UniProtPositions[unpID][int64(pos)]
 	if len(residues) == 0 {
+		if len(p.UniProtUnobserved[unpID][pos]) > 0 {
+			return "", 0, errors.New("position not resolved in structure")
+		}
 		return "", 0, errors.New("no coverage")
 	}
 	res := residues[0]
@@ -99,9 +99,9 @@ func walkPocketDir(p *pdb.PDB, dir string) (pockets []Pocket, err error) {
 			}
 
 			var residues []*pdb.Residue
-			for chain, chainPos := range pocketPDB.Chains {
-				for pos := range chainPos {
-					residues = append(residues, p.Chains[chain][pos])
+			for _, chain := range pocketPDB.Residues {
+				for _, res := range chain {
+					residues = append(residues, p.ResidueAt(res.Chain, res.StructPosition, res.InsertionCode))
 				}
 			}
 
@@ -7,6 +7,21 @@ import (
 	"time"
 )
 
+// StatusError is returned by Get for responses other than 200 OK.
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP status code %d", e.Code)
+}
+
+// IsNotFound reports whether the error is an HTTP 404 response.
+func IsNotFound(err error) bool {
+	e, ok := err.(*StatusError)
+	return ok && e.Code == http.StatusNotFound
+}
+
 func Get(url string) ([]byte, error) {
 	timeout := 120
 
@@ -25,7 +40,7 @@ func Get(url string) ([]byte, error) {
 		return nil, err
 	}
 	if res.StatusCode != 200 {
-		return nil, fmt.Errorf("HTTP status code %d", res.StatusCode)
+		return nil, &StatusError{Code: res.StatusCode}
 	}
 
 	body, err := ioutil.ReadAll(res.Body)
@@ -0,0 +1,31 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.Write([]byte("ok"))
+		case "/error":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	if body, err := Get(srv.URL + "/ok"); err != nil || string(body) != "ok" {
+		t.Errorf("unexpected response %q: %v", body, err)
+	}
+	if _, err := Get(srv.URL + "/missing"); !IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+	if _, err := Get(srv.URL + "/error"); err == nil || IsNotFound(err) {
+		t.Errorf("expected a non 404 error, got %v", err)
+	}
+}
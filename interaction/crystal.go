@@ -0,0 +1,136 @@
+package interaction
+
+import (
+	"math"
+	"sort"
+
+	"github.com/tikz/bio/pdb"
+)
+
+// SymmetryContact is a residue of a crystal symmetry mate near a residue of the asymmetric unit.
+type SymmetryContact struct {
+	Residue  *pdb.Residue `json:"-"`        // residue of the asymmetric unit the symmetry copy was generated from
+	Operator string       `json:"operator"` // symmetry operator of the mate, i.e. 2_565
+	Distance float64      `json:"distance"` // minimum distance between atoms
+	Assembly bool         `json:"assembly"` // the copy is part of a biological assembly, so the contact is not a lattice artifact
+}
+
+// Crystal receives a structure with a unit cell and a cutoff distance, and returns a map of residues of the
+// asymmetric unit to residues of symmetry mates that are near, ordered by distance.
+func Crystal(p *pdb.PDB, distance float64) (map[*pdb.Residue][]*SymmetryContact, error) {
+	mates, err := p.SymmetryMates(distance)
+	if err != nil {
+		return nil, err
+	}
+
+	type contactKey struct {
+		res      *pdb.Residue
+		operator string
+	}
+
+	contacts := make(map[*pdb.Residue][]*SymmetryContact)
+	nearest := make(map[*pdb.Residue]map[contactKey]*SymmetryContact)
+	idx := p.AtomIndex()
+	for _, mate := range mates {
+		for _, mateAtom := range mate.Atoms {
+			mateRes := p.ResidueAt(mateAtom.Chain, mateAtom.ResidueNumber, mateAtom.InsertionCode)
+			if mateRes == nil {
+				continue
+			}
+			for _, atom := range idx.WithinAtom(mateAtom, distance) {
+				res := atom.Parent()
+				d := pdb.AtomsDistance(atom, mateAtom)
+				if res == nil || d >= distance {
+					continue
+				}
+
+				if nearest[res] == nil {
+					nearest[res] = make(map[contactKey]*SymmetryContact)
+				}
+				key := contactKey{mateRes, mate.Operator}
+				c, ok := nearest[res][key]
+				if !ok {
+					c = &SymmetryContact{Residue: mateRes, Operator: mate.Operator, Distance: d,
+						Assembly: inAssembly(p, mate.Transform, mateRes.Chain)}
+					nearest[res][key] = c
+					contacts[res] = append(contacts[res], c)
+				}
+				c.Distance = math.Min(c.Distance, d)
+			}
+		}
+	}
+
+	for _, c := range contacts {
+		sort.SliceStable(c, func(i, j int) bool {
+			return c[i].Distance < c[j].Distance
+		})
+	}
+
+	return contacts, nil
+}
+
+// CrystalOnly returns the residues whose only contacts with other chains are lattice contacts, with symmetry mates
+// that are not part of a biological assembly, ordered as in the file. Interactions of these residues are likely
+// crystal packing artifacts rather than physiological interfaces.
+func CrystalOnly(p *pdb.PDB, distance float64) ([]*pdb.Residue, error) {
+	crystal, err := Crystal(p, distance)
+	if err != nil {
+		return nil, err
+	}
+	chains := Chains(p, distance)
+
+	var residues []*pdb.Residue
+	for res, contacts := range crystal {
+		if len(chains[res]) > 0 {
+			continue
+		}
+		lattice := true
+		for _, c := range contacts {
+			if c.Assembly {
+				lattice = false
+			}
+		}
+		if lattice {
+			residues = append(residues, res)
+		}
+	}
+	sort.Slice(residues, func(i, j int) bool {
+		return residues[i].Atoms[0].Number < residues[j].Atoms[0].Number
+	})
+
+	return residues, nil
+}
+
+// inAssembly reports whether the transformation applied to the chain is an operation of a biological assembly.
+func inAssembly(p *pdb.PDB, t *pdb.Transform, chain string) bool {
+	const rotationTolerance, translationTolerance = 1e-3, 0.1 // A
+	for _, a := range p.Assemblies {
+		for _, g := range a.Generators {
+			if !containsChain(g.Chains, chain) {
+				continue
+			}
+			for _, op := range g.Operations {
+				same := true
+				for i := 0; i < 3; i++ {
+					for j := 0; j < 3; j++ {
+						same = same && math.Abs(op.Rotation[i][j]-t.Rotation[i][j]) < rotationTolerance
+					}
+					same = same && math.Abs(op.Translation[i]-t.Translation[i]) < translationTolerance
+				}
+				if same {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func containsChain(chains []string, chain string) bool {
+	for _, c := range chains {
+		if c == chain {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package interaction
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/tikz/bio/pdb"
+)
+
+func TestCrystal(t *testing.T) {
+	raw, err := ioutil.ReadFile("../pdb/testdata/1mso.pdb")
+	if err != nil {
+		t.Fatalf("cannot open file: %s", err)
+	}
+
+	p, err := pdb.NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.ExtractCell(raw); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without assemblies every symmetry contact is considered a lattice contact
+	only, err := CrystalOnly(p, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := len(only)
+
+	if err := p.ExtractAssemblies(raw); err != nil {
+		t.Fatal(err)
+	}
+	crystal, err := Crystal(p, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// HIS B10 contacts its copies around the threefold axis of the hexamer, which are part of the assembly
+	his := p.ResidueAt("B", 10, "")
+	var assembly bool
+	for i, c := range crystal[his] {
+		if i > 0 && c.Distance < crystal[his][i-1].Distance {
+			t.Errorf("contacts not ordered by distance")
+		}
+		if c.Distance >= 4 {
+			t.Errorf("contact with %s at %.2f over cutoff", c.Operator, c.Distance)
+		}
+		if c.Assembly && (c.Operator == "2_555" || c.Operator == "3_555") {
+			assembly = true
+		}
+	}
+	if !assembly {
+		t.Errorf("expected HIS B10 contacts with assembly copies")
+	}
+
+	only, err = CrystalOnly(p, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(only) == 0 || len(only) >= all {
+		t.Errorf("expected fewer lattice only residues with the assembly, got %d of %d", len(only), all)
+	}
+
+	chains := Chains(p, 4)
+	for _, res := range only {
+		if len(chains[res]) > 0 {
+			t.Errorf("residue %s%d contacts other chains in the asymmetric unit", res.Chain, res.StructPosition)
+		}
+		if res == his {
+			t.Errorf("expected HIS B10 not to be a lattice only contact")
+		}
+	}
+}
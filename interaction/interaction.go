@@ -1,72 +1,110 @@
 package interaction
 
 import (
+	"sort"
+
 	"github.com/tikz/bio/pdb"
 )
 
 // Chains receives a structure and a cutoff distance, and returns a map of residues to residues in other chains that are near.
 func Chains(p *pdb.PDB, distance float64) map[*pdb.Residue][]*pdb.Residue {
 	interacts := make(map[*pdb.Residue][]*pdb.Residue)
-	var i1, i2 int
-	for chainName1, chain1 := range p.Chains {
-		for chainName2, chain2 := range p.Chains {
-			if i2 > i1 && chainName1 != chainName2 {
-				for _, res1 := range chain1 {
-					for _, res2 := range chain2 {
-						if pdb.ResiduesDistance(res1, res2) < distance {
-							interacts[res1] = append(interacts[res1], res2)
-							interacts[res2] = append(interacts[res2], res1)
-						}
+	idx := p.AtomIndex()
+	for _, chain := range p.Residues {
+		for _, res := range chain {
+			seen := make(map[*pdb.Residue]bool)
+			for _, atom := range res.Atoms {
+				for _, near := range idx.WithinAtom(atom, distance) {
+					nearRes := near.Parent()
+					if nearRes == nil || nearRes.Chain == res.Chain || seen[nearRes] || pdb.AtomsDistance(atom, near) >= distance {
+						continue
 					}
+					seen[nearRes] = true
+					interacts[res] = append(interacts[res], nearRes)
 				}
 			}
-			i2++
 		}
-		i1++
 	}
 	return interacts
 }
 
-// Hets receives a structure and a cutoff distance, and returns a map of residues to near ligand names.
-func Hets(p *pdb.PDB, distance float64) map[*pdb.Residue][]string {
-	interacts := make(map[*pdb.Residue][]string)
-	for _, chain := range p.Chains {
+// HetContact is a het group instance near a residue, at the minimum distance between their atoms.
+type HetContact struct {
+	Het      *pdb.HetGroup `json:"het"`
+	Distance float64       `json:"distance"`
+}
+
+// Hets receives a structure and a cutoff distance, and returns a map of residues to near het group instances,
+// waters included.
+func Hets(p *pdb.PDB, distance float64) map[*pdb.Residue][]*HetContact {
+	interacts := make(map[*pdb.Residue][]*HetContact)
+	for _, chain := range p.Residues {
 		for _, res := range chain {
-			hets := NearHets(p, res, distance)
-			for het, near := range hets {
-				if near {
-					interacts[res] = append(interacts[res], het)
-				}
+			if hets := NearHets(p, res, distance); len(hets) > 0 {
+				interacts[res] = hets
 			}
 		}
 	}
 	return interacts
 }
 
-// NearHets returns a map of ligand names present in the structure (HOH, SO4, etc)
-// to a bool indicating if the ligand is near the given residue.
-func NearHets(p *pdb.PDB, r *pdb.Residue, distance float64) map[string]bool {
-	hets := make(map[string]bool)
-	for _, atom := range r.Atoms {
-		for _, hetAtom := range p.HetAtoms {
-			hets[hetAtom.Residue] = false
-			if pdb.AtomsDistance(atom, hetAtom) < distance {
-				hets[hetAtom.Residue] = true
-				break
+// Ligands receives a structure and a cutoff distance, and returns a map of residues to near ligands,
+// excluding waters and common crystallization or buffer additives (see pdb.IsArtifact).
+func Ligands(p *pdb.PDB, distance float64) map[*pdb.Residue][]*HetContact {
+	interacts := make(map[*pdb.Residue][]*HetContact)
+	for _, chain := range p.Residues {
+		for _, res := range chain {
+			if hets := NearLigands(p, res, distance); len(hets) > 0 {
+				interacts[res] = hets
 			}
 		}
 	}
-	return hets
+	return interacts
+}
+
+// NearHets returns the het group instances (each ligand copy, water molecule, etc) near the given residue,
+// ordered by distance.
+func NearHets(p *pdb.PDB, r *pdb.Residue, distance float64) []*HetContact {
+	return nearHets(p, r, distance, func(*pdb.HetGroup) bool { return true })
 }
 
-// NearWater returns if the given residue is near a HOH het.
-func NearWater(p *pdb.PDB, r *pdb.Residue, distance float64) bool {
+// NearWater returns the water molecules near the given residue, ordered by distance.
+func NearWater(p *pdb.PDB, r *pdb.Residue, distance float64) []*HetContact {
+	return nearHets(p, r, distance, (*pdb.HetGroup).IsWater)
+}
+
+// NearLigands returns the ligands near the given residue, excluding waters and artifacts, ordered by distance.
+func NearLigands(p *pdb.PDB, r *pdb.Residue, distance float64) []*HetContact {
+	return nearHets(p, r, distance, func(h *pdb.HetGroup) bool { return !h.IsWater() && !h.IsArtifact() })
+}
+
+func nearHets(p *pdb.PDB, r *pdb.Residue, distance float64, keep func(*pdb.HetGroup) bool) []*HetContact {
+	var contacts []*HetContact
+	nearest := make(map[*pdb.HetGroup]*HetContact)
+
+	idx := p.HetIndex()
 	for _, atom := range r.Atoms {
-		for _, hetAtom := range p.HetAtoms {
-			if hetAtom.Residue == "HOH" && pdb.AtomsDistance(atom, hetAtom) < distance {
-				return true
+		for _, hetAtom := range idx.WithinAtom(atom, distance) {
+			het := hetAtom.HetGroup()
+			d := pdb.AtomsDistance(atom, hetAtom)
+			if het == nil || d >= distance || !keep(het) {
+				continue
+			}
+
+			c, ok := nearest[het]
+			if !ok {
+				c = &HetContact{Het: het, Distance: d}
+				nearest[het] = c
+				contacts = append(contacts, c)
+			}
+			if d < c.Distance {
+				c.Distance = d
 			}
 		}
 	}
-	return false
+
+	sort.SliceStable(contacts, func(i, j int) bool {
+		return contacts[i].Distance < contacts[j].Distance
+	})
+	return contacts
 }
@@ -0,0 +1,160 @@
+package interaction
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/tikz/bio/pdb"
+)
+
+func TestNearHets(t *testing.T) {
+	raw, err := ioutil.ReadFile("../pdb/testdata/1mso.pdb")
+	if err != nil {
+		t.Fatalf("cannot open file: %s", err)
+	}
+
+	p, err := pdb.NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both zinc ions are different instances, each coordinated by a different histidine
+	his := map[string]*pdb.Residue{"B": p.ResidueAt("B", 10, ""), "D": p.ResidueAt("D", 10, "")}
+	zinc := make(map[string]*pdb.HetGroup)
+	for chain, res := range his {
+		hets := NearHets(p, res, 4)
+		for i, c := range hets {
+			if i > 0 && c.Distance < hets[i-1].Distance {
+				t.Errorf("het groups not ordered by distance")
+			}
+			if c.Het.Name == "ZN" {
+				if c.Distance > 2.5 {
+					t.Errorf("expected zinc coordination distance, got %.2f", c.Distance)
+				}
+				zinc[chain] = c.Het
+			}
+		}
+		if zinc[chain] == nil {
+			t.Fatalf("expected zinc near HIS %s10", chain)
+		}
+	}
+	if zinc["B"] == zinc["D"] {
+		t.Errorf("expected a different zinc ion for each histidine, got %s", zinc["B"].ID())
+	}
+
+	hets := Hets(p, 4)
+	var waters int
+	for _, contacts := range hets {
+		for _, c := range contacts {
+			if c.Het.IsWater() {
+				waters++
+			}
+		}
+	}
+	if waters < 10 {
+		t.Errorf("expected distinct water instances near residues, got %d contacts", waters)
+	}
+
+	for _, res := range p.Residues["A"] {
+		for _, c := range NearWater(p, res, 4) {
+			if !c.Het.IsWater() || c.Distance >= 4 {
+				t.Errorf("unexpected water contact %s at %.2f", c.Het.ID(), c.Distance)
+			}
+		}
+	}
+}
+
+func TestLigands(t *testing.T) {
+	raw, err := ioutil.ReadFile("../pdb/testdata/1mso.pdb")
+	if err != nil {
+		t.Fatalf("cannot open file: %s", err)
+	}
+
+	// Second zinc ion as a sulfate, a crystallization artifact
+	edited := strings.Replace(string(raw), "HETATM 1718 ZN    ZN D 502", "HETATM 1718  S   SO4 D 502", 1)
+	p, err := pdb.NewPDBFromRaw([]byte(edited))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ligands := Ligands(p, 4)
+	zinc := make(map[*pdb.HetGroup]bool)
+	for _, contacts := range ligands {
+		for _, c := range contacts {
+			if c.Het.IsWater() || c.Het.IsArtifact() {
+				t.Errorf("unexpected ligand %s", c.Het.ID())
+			}
+			if c.Het.Name == "ZN" {
+				zinc[c.Het] = true
+			}
+		}
+	}
+	if len(zinc) != 1 {
+		t.Errorf("expected a single zinc ion among ligands, got %d", len(zinc))
+	}
+
+	var sulfate bool
+	for _, res := range []*pdb.Residue{p.ResidueAt("B", 10, ""), p.ResidueAt("D", 10, "")} {
+		for _, c := range NearHets(p, res, 4) {
+			sulfate = sulfate || c.Het.Name == "SO4"
+		}
+	}
+	if !sulfate {
+		t.Errorf("expected sulfate among het groups")
+	}
+}
+
+func TestChainsModifiedResidue(t *testing.T) {
+	raw, err := ioutil.ReadFile("../pdb/testdata/1mso.pdb")
+	if err != nil {
+		t.Fatalf("cannot open file: %s", err)
+	}
+
+	// LEU B 6 as a selenomethionine in HETATM records
+	r := regexp.MustCompile("(?m)^ATOM  (.{11})LEU B   6")
+	edited := r.ReplaceAllString(string(raw), "HETATM${1}MSE B   6")
+
+	original, err := pdb.NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := pdb.NewPDBFromRaw([]byte(edited))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Contacts of the modified residue, and of the other chains with it, are those of the original residue
+	contacts := func(p *pdb.PDB) (from []string, to []string) {
+		chains := Chains(p, 5)
+		res := p.ResidueAt("B", 6, "")
+		for _, near := range chains[res] {
+			from = append(from, fmt.Sprintf("%s-%d", near.Chain, near.StructPosition))
+		}
+		for other, near := range chains {
+			for _, n := range near {
+				if n == res {
+					to = append(to, fmt.Sprintf("%s-%d", other.Chain, other.StructPosition))
+				}
+			}
+		}
+		sort.Strings(from)
+		sort.Strings(to)
+		return from, to
+	}
+	expectedFrom, expectedTo := contacts(original)
+	from, to := contacts(p)
+	if len(expectedFrom) == 0 || !reflect.DeepEqual(from, expectedFrom) || !reflect.DeepEqual(to, expectedTo) {
+		t.Errorf("expected contacts %v and %v, got %v and %v", expectedFrom, expectedTo, from, to)
+	}
+
+	for _, c := range NearHets(p, p.ResidueAt("B", 5, ""), 5) {
+		if c.Het.Name == "MSE" {
+			t.Errorf("modified residue found as het group")
+		}
+	}
+}
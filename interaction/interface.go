@@ -0,0 +1,200 @@
+package interaction
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/tikz/bio/pdb"
+	"github.com/tikz/bio/sasa"
+)
+
+// InterfaceClass is the classification of an interface residue by Levy 2010.
+// https://doi.org/10.1016/j.jmb.2010.09.028
+type InterfaceClass string
+
+const (
+	// Core residues are exposed in the isolated chain and buried in the complex.
+	Core InterfaceClass = "core"
+	// Rim residues remain exposed in the complex.
+	Rim InterfaceClass = "rim"
+	// Support residues are already buried in the isolated chain.
+	Support InterfaceClass = "support"
+)
+
+// exposedRelSASA is the relative SASA threshold (%) for a residue to be considered exposed.
+const exposedRelSASA = 25
+
+// contactDistance is the maximum distance between two atoms whose expanded spheres can overlap
+// (two 1.9 A carbons and a 1.4 A probe on each).
+const contactDistance = 6.6
+
+// InterfaceResidue holds the surface of a residue in its isolated chain and in the complex with the partner chain.
+type InterfaceResidue struct {
+	Residue        *pdb.Residue   `json:"-"`
+	SASAMonomer    float64        `json:"sasaMonomer"`
+	SASAComplex    float64        `json:"sasaComplex"`
+	Buried         float64        `json:"buried"`
+	RelSASAMonomer float64        `json:"relSasaMonomer"`
+	RelSASAComplex float64        `json:"relSasaComplex"`
+	Class          InterfaceClass `json:"class"` // empty for residues without a max ASA reference
+}
+
+// Interface is the interface between a pair of chains.
+type Interface struct {
+	Chain1     string              `json:"chain1"`
+	Chain2     string              `json:"chain2"`
+	Residues   []*InterfaceResidue `json:"residues"`   // residues of both chains with buried surface, ordered as in the file
+	BuriedArea float64             `json:"buriedArea"` // total SASA buried by the complex, sum over both chains
+	Area       float64             `json:"area"`       // interface area, half of the buried area
+}
+
+// Interfaces calculates the buried surface area between every pair of chains in contact, comparing the SASA
+// of each chain in isolation with the SASA of the pair complex, and classifies the residues with buried surface
+// as core, rim or support. Only ATOM records are considered, see sasa.Native for the options.
+func Interfaces(p *pdb.PDB, opts sasa.Options) ([]*Interface, error) {
+	var chains []string
+	for chain := range p.Residues {
+		chains = append(chains, chain)
+	}
+	sort.Strings(chains)
+
+	monomers := make(map[string]sasa.Results)
+	monomer := func(chain string) (sasa.Results, error) {
+		if r, ok := monomers[chain]; ok {
+			return r, nil
+		}
+		r, err := chainsSASA(p, opts, chain)
+		if err == nil {
+			monomers[chain] = r
+		}
+		return r, err
+	}
+
+	var interfaces []*Interface
+	for i, chain1 := range chains {
+		for _, chain2 := range chains[i+1:] {
+			if !chainsInContact(p, chain1, chain2) {
+				continue
+			}
+
+			m1, err := monomer(chain1)
+			if err != nil {
+				return nil, fmt.Errorf("chain %s SASA: %v", chain1, err)
+			}
+			m2, err := monomer(chain2)
+			if err != nil {
+				return nil, fmt.Errorf("chain %s SASA: %v", chain2, err)
+			}
+			pair, err := chainsSASA(p, opts, chain1, chain2)
+			if err != nil {
+				return nil, fmt.Errorf("chains %s-%s SASA: %v", chain1, chain2, err)
+			}
+
+			in := &Interface{Chain1: chain1, Chain2: chain2}
+			for _, m := range []sasa.Results{m1, m2} {
+				for _, res := range orderedResidues(m) {
+					if ir := interfaceResidue(res, m.Residues[res], pair.Residues[res]); ir != nil {
+						in.Residues = append(in.Residues, ir)
+						in.BuriedArea += ir.Buried
+					}
+				}
+			}
+			in.Area = in.BuriedArea / 2
+
+			if len(in.Residues) > 0 {
+				interfaces = append(interfaces, in)
+			}
+		}
+	}
+
+	return interfaces, nil
+}
+
+// interfaceResidue classifies a residue by its monomer and complex SASA, or returns nil if it has no buried surface.
+// Residues without relative SASA, such as non standard ones, are left unclassified.
+func interfaceResidue(res *pdb.Residue, m sasa.ResidueSASA, c sasa.ResidueSASA) *InterfaceResidue {
+	buried := m.All - c.All
+	if buried <= 0 {
+		return nil
+	}
+
+	ir := &InterfaceResidue{
+		Residue:        res,
+		SASAMonomer:    m.All,
+		SASAComplex:    c.All,
+		Buried:         buried,
+		RelSASAMonomer: m.RelAll,
+		RelSASAComplex: c.RelAll,
+	}
+
+	switch {
+	case math.IsNaN(m.RelAll) || math.IsNaN(c.RelAll):
+	case c.RelAll > exposedRelSASA:
+		ir.Class = Rim
+	case m.RelAll < exposedRelSASA:
+		ir.Class = Support
+	default:
+		ir.Class = Core
+	}
+
+	return ir
+}
+
+// chainsSASA calculates the SASA of the given chains in isolation from the rest of the structure.
+// Results are keyed by the residues of the original structure.
+func chainsSASA(p *pdb.PDB, opts sasa.Options, chains ...string) (sasa.Results, error) {
+	var atoms []*pdb.Atom
+	for _, chain := range chains {
+		for _, res := range p.Residues[chain] {
+			atoms = append(atoms, res.Atoms...)
+		}
+	}
+
+	subset, err := pdb.NewPDBFromAtoms(atoms, nil)
+	if err != nil {
+		return sasa.Results{}, err
+	}
+
+	results, err := sasa.Native(subset, opts)
+	if err != nil {
+		return results, err
+	}
+
+	residues := make(map[*pdb.Residue]sasa.ResidueSASA)
+	for res, r := range results.Residues {
+		if orig := p.ResidueAt(res.Chain, res.StructPosition, res.InsertionCode); orig != nil {
+			residues[orig] = r
+		}
+	}
+	results.Residues = residues
+
+	return results, nil
+}
+
+// chainsInContact reports whether any atoms of the chains are close enough to bury surface.
+func chainsInContact(p *pdb.PDB, chain1 string, chain2 string) bool {
+	idx := p.AtomIndex()
+	for _, res := range p.Residues[chain1] {
+		for _, atom := range res.Atoms {
+			for _, near := range idx.WithinAtom(atom, contactDistance) {
+				if near.Chain == chain2 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// orderedResidues returns the residues of the results ordered as in the file.
+func orderedResidues(r sasa.Results) []*pdb.Residue {
+	var residues []*pdb.Residue
+	for res := range r.Residues {
+		residues = append(residues, res)
+	}
+	sort.Slice(residues, func(i, j int) bool {
+		return residues[i].Atoms[0].Number < residues[j].Atoms[0].Number
+	})
+	return residues
+}
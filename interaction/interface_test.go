@@ -0,0 +1,85 @@
+package interaction
+
+import (
+	"io/ioutil"
+	"math"
+	"testing"
+
+	"github.com/tikz/bio/pdb"
+	"github.com/tikz/bio/sasa"
+)
+
+func TestInterfaces(t *testing.T) {
+	raw, err := ioutil.ReadFile("../pdb/testdata/1mso.pdb")
+	if err != nil {
+		t.Fatalf("cannot open file: %s", err)
+	}
+
+	p, err := pdb.NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	interfaces, err := Interfaces(p, sasa.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Chains A and B are the two chains of the same insulin molecule, bonded by disulfides
+	var ab *Interface
+	for _, in := range interfaces {
+		if in.Chain1 == "A" && in.Chain2 == "B" {
+			ab = in
+		}
+		if in.Chain1 >= in.Chain2 {
+			t.Errorf("expected ordered chain pair, got %s-%s", in.Chain1, in.Chain2)
+		}
+	}
+	if ab == nil {
+		t.Fatal("expected interface between chains A and B")
+	}
+	if ab.Area < 500 || math.Abs(ab.Area*2-ab.BuriedArea) > 1e-6 {
+		t.Errorf("unexpected A-B interface area %f", ab.Area)
+	}
+
+	classes := make(map[InterfaceClass]int)
+	var sum float64
+	for _, r := range ab.Residues {
+		classes[r.Class]++
+		sum += r.Buried
+		if r.Buried <= 0 || r.SASAComplex >= r.SASAMonomer {
+			t.Errorf("expected buried surface in %s-%d", r.Residue.Chain, r.Residue.StructPosition)
+		}
+		if r.Residue.Chain != "A" && r.Residue.Chain != "B" {
+			t.Errorf("unexpected residue of chain %s", r.Residue.Chain)
+		}
+	}
+	if classes[Core] == 0 || classes[Rim] == 0 {
+		t.Errorf("expected core and rim residues, got %v", classes)
+	}
+	if math.Abs(sum-ab.BuriedArea) > 1e-6 {
+		t.Errorf("expected buried area to be the sum of residues")
+	}
+}
+
+func TestInterfaceResidue(t *testing.T) {
+	res := &pdb.Residue{}
+	for _, c := range []struct {
+		monomer, complex float64
+		class            InterfaceClass
+	}{
+		{60, 40, Rim},
+		{60, 10, Core},
+		{20, 10, Support},
+		{math.NaN(), math.NaN(), ""},
+	} {
+		ir := interfaceResidue(res, sasa.ResidueSASA{All: 100, RelAll: c.monomer}, sasa.ResidueSASA{All: 50, RelAll: c.complex})
+		if ir == nil || ir.Class != c.class {
+			t.Errorf("expected class %q for %f and %f, got %+v", c.class, c.monomer, c.complex, ir)
+		}
+	}
+
+	if interfaceResidue(res, sasa.ResidueSASA{All: 50}, sasa.ResidueSASA{All: 50}) != nil {
+		t.Errorf("expected nil for a residue without buried surface")
+	}
+}
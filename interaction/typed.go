@@ -0,0 +1,638 @@
+package interaction
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/tikz/bio/pdb"
+)
+
+// Type is the type of a non-covalent interaction.
+type Type string
+
+const (
+	HydrogenBond      Type = "hydrogen bond"
+	SaltBridge        Type = "salt bridge"
+	CationPi          Type = "cation-pi"
+	PiStacking        Type = "pi-stacking"
+	Hydrophobic       Type = "hydrophobic"
+	MetalCoordination Type = "metal coordination"
+	WaterBridge       Type = "water bridge"
+)
+
+// Geometric criteria, as in PLIP (https://doi.org/10.1093/nar/gkv315).
+const (
+	hydrophobicMaxDistance = 4.0   // A, between carbons
+	hbondMaxDistance       = 4.1   // A, between donor and acceptor
+	hbondMinAngle          = 100.0 // degrees, at the donor hydrogen or donor heavy atom
+	saltBridgeMaxDistance  = 5.5   // A, between charge centers
+	cationPiMaxDistance    = 6.0   // A, between charge center and ring centroid
+	piStackingMaxDistance  = 5.5   // A, between ring centroids
+	piStackingMaxAngleDev  = 30.0  // degrees, from parallel or perpendicular ring planes
+	ringMaxOffset          = 2.0   // A, between a ring centroid and the projection of the other group on its plane
+	metalMaxDistance       = 3.0   // A, between metal ion and coordinating atom
+	waterBridgeMinDistance = 2.5   // A, between water oxygen and polar atoms
+	waterBridgeMaxDistance = 4.1   // A, between water oxygen and polar atoms
+	ringMaxPlaneDeviation  = 0.2   // A, for ring atoms to be considered coplanar
+	hydroxylMinLength      = 1.3   // A, single bond between oxygen and its heavy neighbor
+	searchDistance         = 7.5   // A, around the ligand atoms for candidate residues
+)
+
+// Interaction is a non-covalent interaction between a protein residue and a ligand.
+type Interaction struct {
+	Type         Type         `json:"type"`
+	Residue      *pdb.Residue `json:"-"`
+	ProteinAtoms []*pdb.Atom  `json:"-"`        // protein atom, or atoms of the charged group or aromatic ring
+	LigandAtoms  []*pdb.Atom  `json:"-"`        // ligand atom, or atoms of the charged group or aromatic ring
+	Water        *pdb.Atom    `json:"-"`        // bridging water oxygen, for water bridges
+	Distance     float64      `json:"distance"` // between atoms, charge centers or ring centroids in A; for water bridges, the longest of both
+	Angle        float64      `json:"angle"`    // donor angle for H-bonds, angle between ring planes for pi-stacking, NaN otherwise
+	ProteinDonor bool         `json:"proteinDonor"`
+	Detail       string       `json:"detail"` // pi-stacking type: parallel or T-shaped
+}
+
+var (
+	proteinDonors = map[string][]string{
+		"ARG": {"NE", "NH1", "NH2"}, "ASN": {"ND2"}, "GLN": {"NE2"}, "HIS": {"ND1", "NE2"}, "LYS": {"NZ"},
+		"SER": {"OG"}, "THR": {"OG1"}, "TRP": {"NE1"}, "TYR": {"OH"}, "CYS": {"SG"},
+	}
+	proteinAcceptors = map[string][]string{
+		"ASN": {"OD1"}, "ASP": {"OD1", "OD2"}, "GLN": {"OE1"}, "GLU": {"OE1", "OE2"}, "HIS": {"ND1", "NE2"},
+		"SER": {"OG"}, "THR": {"OG1"}, "TYR": {"OH"}, "MET": {"SD"},
+	}
+	proteinPositive = map[string][]string{"ARG": {"NE", "NH1", "NH2"}, "LYS": {"NZ"}, "HIS": {"ND1", "NE2"}}
+	proteinNegative = map[string][]string{"ASP": {"OD1", "OD2"}, "GLU": {"OE1", "OE2"}}
+	proteinRings    = map[string][][]string{
+		"PHE": {{"CG", "CD1", "CD2", "CE1", "CE2", "CZ"}},
+		"TYR": {{"CG", "CD1", "CD2", "CE1", "CE2", "CZ"}},
+		"HIS": {{"CG", "ND1", "CD2", "CE1", "NE2"}},
+		"TRP": {{"CG", "CD1", "NE1", "CE2", "CD2"}, {"CD2", "CE2", "CE3", "CZ2", "CZ3", "CH2"}},
+	}
+	metals = map[string]bool{
+		"LI": true, "NA": true, "K": true, "MG": true, "CA": true, "MN": true, "FE": true, "CO": true, "NI": true,
+		"CU": true, "ZN": true, "CD": true, "HG": true, "SR": true, "BA": true, "PT": true, "AU": true, "AG": true,
+	}
+	covalentRadii = map[string]float64{"H": 0.31, "C": 0.76, "N": 0.71, "O": 0.66, "S": 1.05, "P": 1.07, "F": 0.57, "CL": 1.02, "BR": 1.2, "I": 1.39}
+)
+
+func element(a *pdb.Atom) string {
+	if a.Element != "" {
+		return strings.ToUpper(a.Element)
+	}
+	name := strings.TrimLeft(a.Name, "0123456789")
+	if name == "" {
+		return ""
+	}
+	return strings.ToUpper(name[:1])
+}
+
+// bonded reports whether two atoms are covalently bonded, by their distance.
+func bonded(a *pdb.Atom, b *pdb.Atom) bool {
+	ra, ok1 := covalentRadii[element(a)]
+	rb, ok2 := covalentRadii[element(b)]
+	if !ok1 || !ok2 || a == b {
+		return false
+	}
+	return pdb.AtomsDistance(a, b) <= ra+rb+0.4
+}
+
+// group is a set of atoms acting as a single interaction center, such as a charged group or an aromatic ring.
+type group struct {
+	atoms  []*pdb.Atom
+	center [3]float64
+	normal [3]float64 // for rings
+}
+
+func newGroup(atoms []*pdb.Atom) *group {
+	g := &group{atoms: atoms}
+	for _, a := range atoms {
+		g.center = add(g.center, scale(pos(a), 1/float64(len(atoms))))
+	}
+	return g
+}
+
+// newRing returns the group of the ring atoms (in cyclic order), or nil if not planar.
+func newRing(atoms []*pdb.Atom) *group {
+	g := newGroup(atoms)
+	for i := range atoms {
+		u := sub(pos(atoms[i]), g.center)
+		v := sub(pos(atoms[(i+1)%len(atoms)]), g.center)
+		g.normal = add(g.normal, cross(u, v))
+	}
+	if norm(g.normal) == 0 {
+		return nil
+	}
+	g.normal = scale(g.normal, 1/norm(g.normal))
+
+	for _, a := range atoms {
+		if math.Abs(dot(sub(pos(a), g.center), g.normal)) > ringMaxPlaneDeviation {
+			return nil
+		}
+	}
+	return g
+}
+
+// offset returns the distance between the ring centroid and the projection of the point on the ring plane.
+func (g *group) offset(p [3]float64) float64 {
+	d := sub(p, g.center)
+	return norm(sub(d, scale(g.normal, dot(d, g.normal))))
+}
+
+// ligand holds the atom types of a ligand, derived from its bond graph.
+type ligand struct {
+	atoms              []*pdb.Atom
+	neighbors          map[*pdb.Atom][]*pdb.Atom
+	donors, acceptors  []*pdb.Atom
+	hydrophobic        []*pdb.Atom
+	metals             []*pdb.Atom
+	positive, negative []*group
+	rings              []*group
+}
+
+func newLigand(atoms []*pdb.Atom) *ligand {
+	l := &ligand{atoms: atoms, neighbors: make(map[*pdb.Atom][]*pdb.Atom)}
+	for i, a := range atoms {
+		for _, b := range atoms[i+1:] {
+			if bonded(a, b) {
+				l.neighbors[a] = append(l.neighbors[a], b)
+				l.neighbors[b] = append(l.neighbors[b], a)
+			}
+		}
+	}
+
+	// Without explicit hydrogens, hydroxyls are told apart from carbonyls by their bond length
+	var hydrogens bool
+	for _, a := range atoms {
+		if element(a) == "H" {
+			hydrogens = true
+		}
+	}
+
+	heavy := func(a *pdb.Atom) (n int, hydrogens int) {
+		for _, b := range l.neighbors[a] {
+			if element(b) == "H" {
+				hydrogens++
+			} else {
+				n++
+			}
+		}
+		return
+	}
+
+	for _, a := range atoms {
+		n, h := heavy(a)
+		switch element(a) {
+		case "C":
+			onlyCarbons := true
+			for _, b := range l.neighbors[a] {
+				if e := element(b); e != "C" && e != "H" {
+					onlyCarbons = false
+				}
+			}
+			if onlyCarbons {
+				l.hydrophobic = append(l.hydrophobic, a)
+			}
+		case "N":
+			l.acceptors = append(l.acceptors, a)
+			if h > 0 || n < 3 {
+				l.donors = append(l.donors, a)
+			}
+			if a.Charge == "1+" || n == 4 {
+				l.positive = append(l.positive, newGroup([]*pdb.Atom{a}))
+			}
+		case "O":
+			l.acceptors = append(l.acceptors, a)
+			if h > 0 || (!hydrogens && n == 1 && pdb.AtomsDistance(a, l.neighbors[a][0]) > hydroxylMinLength) {
+				l.donors = append(l.donors, a)
+			}
+		default:
+			if metals[element(a)] {
+				l.metals = append(l.metals, a)
+			}
+		}
+	}
+
+	// Charged groups: carboxylates, phosphates, sulfonates, amidines and guanidines
+	for _, a := range atoms {
+		var oxygens, nitrogens []*pdb.Atom
+		for _, b := range l.neighbors[a] {
+			if n, _ := heavy(b); n == 1 {
+				switch element(b) {
+				case "O":
+					oxygens = append(oxygens, b)
+				case "N":
+					nitrogens = append(nitrogens, b)
+				}
+			}
+		}
+		switch {
+		case element(a) == "C" && len(oxygens) == 2:
+			l.negative = append(l.negative, newGroup(oxygens))
+		case (element(a) == "P" || element(a) == "S") && len(oxygens) >= 3:
+			l.negative = append(l.negative, newGroup(oxygens))
+		case element(a) == "C" && len(nitrogens) >= 2 && len(oxygens) == 0:
+			l.positive = append(l.positive, newGroup(nitrogens))
+		}
+	}
+
+	l.rings = l.findRings()
+
+	return l
+}
+
+// findRings returns the planar 5 and 6 membered rings of the ligand.
+func (l *ligand) findRings() []*group {
+	var rings []*group
+	seen := make(map[string]bool)
+
+	var path []*pdb.Atom
+	var visit func(a *pdb.Atom)
+	visit = func(a *pdb.Atom) {
+		for _, b := range l.neighbors[a] {
+			if element(b) == "H" {
+				continue
+			}
+			if b == path[0] && len(path) >= 5 {
+				key := ringKey(path)
+				if !seen[key] {
+					seen[key] = true
+					ring := make([]*pdb.Atom, len(path))
+					copy(ring, path)
+					if g := newRing(ring); g != nil {
+						rings = append(rings, g)
+					}
+				}
+				continue
+			}
+			if len(path) == 6 || b.Number < path[0].Number || contains(path, b) {
+				continue
+			}
+			path = append(path, b)
+			visit(b)
+			path = path[:len(path)-1]
+		}
+	}
+
+	for _, a := range l.atoms {
+		if element(a) == "H" {
+			continue
+		}
+		path = []*pdb.Atom{a}
+		visit(a)
+	}
+
+	return rings
+}
+
+func ringKey(atoms []*pdb.Atom) string {
+	numbers := make([]int, len(atoms))
+	for i, a := range atoms {
+		numbers[i] = int(a.Number)
+	}
+	sort.Ints(numbers)
+	return fmt.Sprint(numbers)
+}
+
+func contains(atoms []*pdb.Atom, a *pdb.Atom) bool {
+	for _, b := range atoms {
+		if a == b {
+			return true
+		}
+	}
+	return false
+}
+
+// residueAtoms returns the atoms of the residue with the given names, or nil if any is missing.
+func residueAtoms(res *pdb.Residue, names []string) []*pdb.Atom {
+	var atoms []*pdb.Atom
+	for _, n := range names {
+		a := res.Atom(n)
+		if a == nil {
+			return nil
+		}
+		atoms = append(atoms, a)
+	}
+	return atoms
+}
+
+func residueName(res *pdb.Residue) string {
+	return strings.ToUpper(res.Atoms[0].Residue)
+}
+
+// isProteinHydrophobic reports whether the atom is a side chain carbon bonded only to carbons.
+func isProteinHydrophobic(res *pdb.Residue, a *pdb.Atom) bool {
+	if element(a) != "C" || a.Name == "C" || a.Name == "CA" {
+		return false
+	}
+	for _, b := range res.Atoms {
+		if e := element(b); e != "C" && e != "H" && bonded(a, b) {
+			return false
+		}
+	}
+	return true
+}
+
+func isProteinDonor(res *pdb.Residue, a *pdb.Atom) bool {
+	if a.Name == "N" {
+		return residueName(res) != "PRO"
+	}
+	return containsName(proteinDonors[residueName(res)], a.Name)
+}
+
+func isProteinAcceptor(res *pdb.Residue, a *pdb.Atom) bool {
+	if a.Name == "O" || a.Name == "OXT" {
+		return true
+	}
+	return containsName(proteinAcceptors[residueName(res)], a.Name)
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// donorAngle returns the angle at the donor hydrogen (D-H...A) if the donor has hydrogens,
+// or else the largest angle at the donor heavy atom (X-D...A) with any of its heavy neighbors.
+func donorAngle(donor *pdb.Atom, neighbors []*pdb.Atom, acceptor *pdb.Atom) float64 {
+	best := math.NaN()
+	for _, h := range neighbors {
+		if element(h) == "H" {
+			if a := angle(pos(donor), pos(h), pos(acceptor)); math.IsNaN(best) || a > best {
+				best = a
+			}
+		}
+	}
+	if !math.IsNaN(best) {
+		return best
+	}
+	for _, x := range neighbors {
+		if a := angle(pos(x), pos(donor), pos(acceptor)); math.IsNaN(best) || a > best {
+			best = a
+		}
+	}
+	return best
+}
+
+// residueNeighbors returns the atoms bonded to the given one within its residue and the peptide bond,
+// i.e. the C of the previous residue for a backbone N.
+func residueNeighbors(res *pdb.Residue, a *pdb.Atom) []*pdb.Atom {
+	var neighbors []*pdb.Atom
+	for _, r := range []*pdb.Residue{res.Prev(), res, res.Next()} {
+		if r == nil {
+			continue
+		}
+		for _, b := range r.Atoms {
+			if bonded(a, b) {
+				neighbors = append(neighbors, b)
+			}
+		}
+	}
+	return neighbors
+}
+
+// Ligand detects the interactions between the given ligand atoms (i.e. the HETATM records of a het group)
+// and the protein residues of the structure. Donor, acceptor and charge types of the ligand are derived
+// from its bond graph by distance, and hydrogens are used for H-bond angles if present.
+// Interactions are ordered by type, then by residue.
+func Ligand(p *pdb.PDB, ligandAtoms []*pdb.Atom) []*Interaction {
+	l := newLigand(ligandAtoms)
+	isLigand := make(map[*pdb.Atom]bool)
+	for _, a := range ligandAtoms {
+		isLigand[a] = true
+	}
+
+	// Candidate residues near the ligand
+	var residues []*pdb.Residue
+	seen := make(map[*pdb.Residue]bool)
+	idx := p.AtomIndex()
+	for _, a := range ligandAtoms {
+		for _, near := range idx.WithinAtom(a, searchDistance) {
+			if res := near.Parent(); res != nil && !seen[res] && !isLigand[near] {
+				seen[res] = true
+				residues = append(residues, res)
+			}
+		}
+	}
+	sort.Slice(residues, func(i, j int) bool {
+		return residues[i].Atoms[0].Number < residues[j].Atoms[0].Number
+	})
+
+	var interactions []*Interaction
+	found := func(i *Interaction) {
+		interactions = append(interactions, i)
+	}
+
+	for _, res := range residues {
+		name := residueName(res)
+
+		for _, pa := range res.Atoms {
+			if isLigand[pa] {
+				continue
+			}
+
+			// Hydrophobic contacts, the closest ligand carbon for each protein carbon
+			if isProteinHydrophobic(res, pa) {
+				var closest *pdb.Atom
+				for _, la := range l.hydrophobic {
+					if d := pdb.AtomsDistance(pa, la); d <= hydrophobicMaxDistance && (closest == nil || d < pdb.AtomsDistance(pa, closest)) {
+						closest = la
+					}
+				}
+				if closest != nil {
+					found(&Interaction{Type: Hydrophobic, Residue: res, ProteinAtoms: []*pdb.Atom{pa}, LigandAtoms: []*pdb.Atom{closest},
+						Distance: pdb.AtomsDistance(pa, closest), Angle: math.NaN()})
+				}
+			}
+
+			// Hydrogen bonds
+			if isProteinDonor(res, pa) {
+				for _, la := range l.acceptors {
+					if d := pdb.AtomsDistance(pa, la); d <= hbondMaxDistance {
+						if a := donorAngle(pa, residueNeighbors(res, pa), la); a >= hbondMinAngle {
+							found(&Interaction{Type: HydrogenBond, Residue: res, ProteinAtoms: []*pdb.Atom{pa}, LigandAtoms: []*pdb.Atom{la},
+								Distance: d, Angle: a, ProteinDonor: true})
+						}
+					}
+				}
+			}
+			if isProteinAcceptor(res, pa) {
+				for _, la := range l.donors {
+					if d := pdb.AtomsDistance(pa, la); d <= hbondMaxDistance {
+						if a := donorAngle(la, l.neighbors[la], pa); a >= hbondMinAngle {
+							found(&Interaction{Type: HydrogenBond, Residue: res, ProteinAtoms: []*pdb.Atom{pa}, LigandAtoms: []*pdb.Atom{la},
+								Distance: d, Angle: a})
+						}
+					}
+				}
+			}
+
+			// Metal coordination
+			if e := element(pa); e == "O" || e == "N" || e == "S" {
+				for _, m := range l.metals {
+					if d := pdb.AtomsDistance(pa, m); d <= metalMaxDistance {
+						found(&Interaction{Type: MetalCoordination, Residue: res, ProteinAtoms: []*pdb.Atom{pa}, LigandAtoms: []*pdb.Atom{m},
+							Distance: d, Angle: math.NaN()})
+					}
+				}
+			}
+		}
+
+		// Salt bridges
+		saltBridges := func(protein []string, ligand []*group) {
+			atoms := residueAtoms(res, protein)
+			if atoms == nil {
+				return
+			}
+			pg := newGroup(atoms)
+			for _, lg := range ligand {
+				if d := norm(sub(pg.center, lg.center)); d <= saltBridgeMaxDistance {
+					found(&Interaction{Type: SaltBridge, Residue: res, ProteinAtoms: atoms, LigandAtoms: lg.atoms, Distance: d, Angle: math.NaN()})
+				}
+			}
+		}
+		if names, ok := proteinPositive[name]; ok {
+			saltBridges(names, l.negative)
+		}
+		if names, ok := proteinNegative[name]; ok {
+			saltBridges(names, l.positive)
+		}
+
+		// Cation-pi, protein cations with ligand rings and ligand cations with protein rings
+		if names, ok := proteinPositive[name]; ok && name != "HIS" {
+			if atoms := residueAtoms(res, names); atoms != nil {
+				pg := newGroup(atoms)
+				for _, ring := range l.rings {
+					if d := norm(sub(pg.center, ring.center)); d <= cationPiMaxDistance && ring.offset(pg.center) <= ringMaxOffset {
+						found(&Interaction{Type: CationPi, Residue: res, ProteinAtoms: atoms, LigandAtoms: ring.atoms, Distance: d, Angle: math.NaN()})
+					}
+				}
+			}
+		}
+		for _, names := range proteinRings[name] {
+			atoms := residueAtoms(res, names)
+			if atoms == nil {
+				continue
+			}
+			ring := newRing(atoms)
+			if ring == nil {
+				continue
+			}
+			for _, lg := range l.positive {
+				if d := norm(sub(lg.center, ring.center)); d <= cationPiMaxDistance && ring.offset(lg.center) <= ringMaxOffset {
+					found(&Interaction{Type: CationPi, Residue: res, ProteinAtoms: atoms, LigandAtoms: lg.atoms, Distance: d, Angle: math.NaN()})
+				}
+			}
+
+			// Pi-stacking
+			for _, lr := range l.rings {
+				d := norm(sub(lr.center, ring.center))
+				if d > piStackingMaxDistance {
+					continue
+				}
+				a := math.Acos(math.Min(1, math.Abs(dot(ring.normal, lr.normal)))) * 180 / math.Pi
+				offset := math.Min(ring.offset(lr.center), lr.offset(ring.center))
+				var detail string
+				switch {
+				case a <= piStackingMaxAngleDev:
+					detail = "parallel"
+				case a >= 90-piStackingMaxAngleDev:
+					detail = "T-shaped"
+				}
+				if detail != "" && offset <= ringMaxOffset {
+					found(&Interaction{Type: PiStacking, Residue: res, ProteinAtoms: atoms, LigandAtoms: lr.atoms, Distance: d, Angle: a, Detail: detail})
+				}
+			}
+		}
+	}
+
+	// Water bridges between ligand polar atoms and protein donors or acceptors
+	hetIdx := p.HetIndex()
+	for _, la := range append(append([]*pdb.Atom{}, l.acceptors...), l.donors...) {
+		for _, w := range hetIdx.WithinAtom(la, waterBridgeMaxDistance) {
+			if het := w.HetGroup(); het == nil || !het.IsWater() || element(w) != "O" || isLigand[w] ||
+				pdb.AtomsDistance(la, w) < waterBridgeMinDistance {
+				continue
+			}
+			for _, pa := range idx.WithinAtom(w, waterBridgeMaxDistance) {
+				res := pa.Parent()
+				if res == nil || isLigand[pa] || pdb.AtomsDistance(pa, w) < waterBridgeMinDistance ||
+					(!isProteinDonor(res, pa) && !isProteinAcceptor(res, pa)) {
+					continue
+				}
+				found(&Interaction{Type: WaterBridge, Residue: res, ProteinAtoms: []*pdb.Atom{pa}, LigandAtoms: []*pdb.Atom{la}, Water: w,
+					Distance: math.Max(pdb.AtomsDistance(la, w), pdb.AtomsDistance(pa, w)), Angle: math.NaN(), ProteinDonor: isProteinDonor(res, pa)})
+			}
+		}
+	}
+	interactions = uniqueInteractions(interactions)
+
+	order := map[Type]int{HydrogenBond: 0, SaltBridge: 1, CationPi: 2, PiStacking: 3, Hydrophobic: 4, MetalCoordination: 5, WaterBridge: 6}
+	sort.SliceStable(interactions, func(i, j int) bool {
+		if interactions[i].Type != interactions[j].Type {
+			return order[interactions[i].Type] < order[interactions[j].Type]
+		}
+		return interactions[i].ProteinAtoms[0].Number < interactions[j].ProteinAtoms[0].Number
+	})
+
+	return interactions
+}
+
+// uniqueInteractions removes duplicated water bridges from ligand atoms that are both donors and acceptors.
+func uniqueInteractions(interactions []*Interaction) []*Interaction {
+	type key struct {
+		t       Type
+		protein *pdb.Atom
+		ligand  *pdb.Atom
+		water   *pdb.Atom
+	}
+	seen := make(map[key]bool)
+	var unique []*Interaction
+	for _, i := range interactions {
+		k := key{i.Type, i.ProteinAtoms[0], i.LigandAtoms[0], i.Water}
+		if !seen[k] {
+			seen[k] = true
+			unique = append(unique, i)
+		}
+	}
+	return unique
+}
+
+func pos(a *pdb.Atom) [3]float64 {
+	return [3]float64{a.X, a.Y, a.Z}
+}
+
+func add(a [3]float64, b [3]float64) [3]float64 {
+	return [3]float64{a[0] + b[0], a[1] + b[1], a[2] + b[2]}
+}
+
+func sub(a [3]float64, b [3]float64) [3]float64 {
+	return [3]float64{a[0] - b[0], a[1] - b[1], a[2] - b[2]}
+}
+
+func scale(a [3]float64, s float64) [3]float64 {
+	return [3]float64{a[0] * s, a[1] * s, a[2] * s}
+}
+
+func dot(a [3]float64, b [3]float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+func cross(a [3]float64, b [3]float64) [3]float64 {
+	return [3]float64{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+
+func norm(a [3]float64) float64 {
+	return math.Sqrt(dot(a, a))
+}
+
+// angle returns the angle in degrees at b formed by a, b and c.
+func angle(a [3]float64, b [3]float64, c [3]float64) float64 {
+	u, v := sub(a, b), sub(c, b)
+	return math.Acos(math.Max(-1, math.Min(1, dot(u, v)/(norm(u)*norm(v))))) * 180 / math.Pi
+}
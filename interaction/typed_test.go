@@ -0,0 +1,107 @@
+package interaction
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/tikz/bio/pdb"
+)
+
+func TestLigand(t *testing.T) {
+	raw, err := ioutil.ReadFile("../pdb/testdata/1mso.pdb")
+	if err != nil {
+		t.Fatalf("cannot open file: %s", err)
+	}
+
+	p, err := pdb.NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var zinc []*pdb.Atom
+	for _, het := range p.Hets {
+		if het.Name == "ZN" {
+			zinc = append(zinc, het.Atoms...)
+		}
+	}
+	metal := make(map[string]bool)
+	for _, i := range Ligand(p, zinc) {
+		if i.Type != MetalCoordination {
+			t.Errorf("expected only metal coordination for zinc, got %s", i.Type)
+		}
+		if i.Distance > metalMaxDistance {
+			t.Errorf("metal coordination distance %.2f over threshold", i.Distance)
+		}
+		metal[i.Residue.Chain+i.Residue.Atoms[0].Residue+i.ProteinAtoms[0].Name] = true
+	}
+	// Zinc ions sit on the threefold axis, coordinated by HIS B10 and HIS D10 of the asymmetric unit
+	for _, want := range []string{"BHISNE2", "DHISNE2"} {
+		if !metal[want] {
+			t.Errorf("expected zinc coordinated by %s", want)
+		}
+	}
+
+	// Strand D24-26 forms an antiparallel sheet with B24-26
+	var strand []*pdb.Atom
+	for _, res := range p.Residues["D"] {
+		if res.StructPosition >= 24 && res.StructPosition <= 26 {
+			strand = append(strand, res.Atoms...)
+		}
+	}
+	var donor, acceptor bool
+	for _, i := range Ligand(p, strand) {
+		if i.Residue.Chain == "D" && i.Residue.StructPosition >= 24 && i.Residue.StructPosition <= 26 {
+			t.Errorf("ligand residue D%d reported as interacting", i.Residue.StructPosition)
+		}
+		if i.Type == HydrogenBond {
+			if i.Distance > hbondMaxDistance || i.Angle < hbondMinAngle {
+				t.Errorf("H-bond %s %s out of geometry: %.2f A, %.1f", i.ProteinAtoms[0].Name, i.LigandAtoms[0].Name, i.Distance, i.Angle)
+			}
+			if i.Residue.Chain == "B" && i.ProteinAtoms[0].Name == "N" && i.LigandAtoms[0].Name == "O" {
+				donor = true
+			}
+			if i.Residue.Chain == "B" && i.ProteinAtoms[0].Name == "O" && i.LigandAtoms[0].Name == "N" {
+				acceptor = true
+			}
+			if i.ProteinAtoms[0].Name == "O" && i.LigandAtoms[0].Name == "O" {
+				t.Errorf("unexpected H-bond between carbonyl oxygens")
+			}
+		}
+	}
+	if !donor || !acceptor {
+		t.Errorf("expected backbone H-bonds with chain B in both directions")
+	}
+}
+
+func TestResidueNeighbors(t *testing.T) {
+	raw, err := ioutil.ReadFile("../pdb/testdata/1mso.pdb")
+	if err != nil {
+		t.Fatalf("cannot open file: %s", err)
+	}
+
+	p, err := pdb.NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Neighbor names, prefixed by the residue number
+	names := func(res *pdb.Residue, name string) map[string]bool {
+		neighbors := make(map[string]bool)
+		for _, a := range residueNeighbors(res, res.Atom(name)) {
+			neighbors[fmt.Sprint(a.ResidueNumber, a.Name)] = true
+		}
+		return neighbors
+	}
+
+	b1, b2 := p.Chains["B"][1], p.Chains["B"][2]
+	if n := names(b2, "N"); len(n) != 3 || !n["2CA"] || !n["2H"] || !n["1C"] {
+		t.Errorf("expected CA, H and previous C as N neighbors, got %v", n)
+	}
+	if n := names(b2, "C"); len(n) != 3 || !n["2CA"] || !n["2O"] || !n["3N"] {
+		t.Errorf("expected CA, O and next N as C neighbors, got %v", n)
+	}
+	if n := names(b1, "N"); n["0C"] || len(n) != 4 {
+		t.Errorf("expected CA and H1-3 as N neighbors at the start of the chain, got %v", n)
+	}
+}
@@ -0,0 +1,204 @@
+package pdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/tikz/bio/http"
+)
+
+// Reference: https://alphafold.ebi.ac.uk/faq
+
+const alphaFoldURL = "https://alphafold.ebi.ac.uk/files/"
+
+// PredictedMethod is the method set for predicted models without one in the file.
+const PredictedMethod = "PREDICTED MODEL"
+
+// NewPDBFromAlphaFold constructs a new instance from the AlphaFold DB model of a UniProt accession and
+// the given model version (i.e. 4), fetching the CIF and PAE files. Only the first fragment (F1) is used,
+// which covers the whole sequence except for very long proteins.
+func NewPDBFromAlphaFold(unpID string, version int) (*PDB, error) {
+	id := "AF-" + unpID + "-F1"
+	cifPath := fmt.Sprintf("%s%s-model_v%d.cif", dataDir, id, version)
+	paePath := fmt.Sprintf("%s%s-predicted_aligned_error_v%d.json", dataDir, id, version)
+
+	for _, path := range []string{cifPath, paePath} {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			filename := filepath.Base(path)
+			raw, err := http.Get(alphaFoldURL + filename)
+			if err != nil {
+				return nil, fmt.Errorf("download %s: %v", filename, err)
+			}
+			writeFile(path, raw)
+		}
+	}
+
+	rawCIF, err := rawFile(cifPath)
+	if err != nil {
+		return nil, err
+	}
+	rawPAE, err := rawFile(paePath)
+	if err != nil {
+		return nil, err
+	}
+
+	pdb, err := NewPDBFromAlphaFoldRaw(unpID, rawCIF, rawPAE)
+	if err != nil {
+		return nil, err
+	}
+	pdb.ID = id
+	pdb.URL = "https://alphafold.ebi.ac.uk/entry/" + unpID
+	pdb.CIFURL = alphaFoldURL + fmt.Sprintf("%s-model_v%d.cif", id, version)
+	pdb.CIFPath = cifPath
+
+	return pdb, nil
+}
+
+// NewPDBFromAlphaFoldRaw constructs a new instance from the raw bytes of a predicted model of a UniProt accession,
+// in PDB or CIF format, and its PAE JSON, which can be nil. The pLDDT of each residue is taken from the B-factors,
+// and residue numbers are taken as UniProt positions.
+func NewPDBFromAlphaFoldRaw(unpID string, rawModel []byte, rawPAE []byte) (*PDB, error) {
+	var pdb *PDB
+	var err error
+	if bytes.HasPrefix(bytes.TrimSpace(rawModel), []byte("data_")) {
+		pdb, err = NewPDBFromCIF(rawModel)
+		if err != nil {
+			return nil, err
+		}
+		block, err := firstCIFBlock(rawModel)
+		if err != nil {
+			return nil, err
+		}
+		// Model files lack most of the experimental metadata, which is optional here
+		pdb.Title, _ = block.Value("_struct.title")
+		pdb.Method, _ = block.Value("_exptl.method")
+		pdb.Date, _ = extractCIFDate(block)
+	} else {
+		pdb, err = NewPDBFromRaw(rawModel)
+		if err != nil {
+			return nil, err
+		}
+		pdb.ExtractSeqRes(rawModel) // optional, SEQRES is only used to tell unmodeled residues
+	}
+	if pdb.Method == "" {
+		pdb.Method = PredictedMethod
+	}
+
+	pdb.ExtractPLDDT()
+	pdb.mapPredicted(unpID)
+
+	if rawPAE != nil {
+		pdb.PAE, pdb.MaxPAE, err = ParsePAE(rawPAE)
+		if err != nil {
+			return nil, fmt.Errorf("parse PAE: %v", err)
+		}
+	}
+
+	return pdb, nil
+}
+
+// ExtractPLDDT sets the pLDDT of each residue of a predicted model from the B-factor column,
+// where AlphaFold and similar tools store it. The CA atom is used, or the mean of all atoms if missing.
+func (pdb *PDB) ExtractPLDDT() {
+	for _, model := range pdb.Models {
+		for _, residues := range model.Residues {
+			for _, res := range residues {
+				if ca := res.Atom("CA"); ca != nil {
+					res.PLDDT = ca.BFactor
+				} else {
+					res.PLDDT = res.MeanBFactor
+				}
+			}
+		}
+	}
+}
+
+// mapPredicted maps the residues of a predicted model to UniProt positions by residue number,
+// since models are built on the full UniProt sequence.
+func (pdb *PDB) mapPredicted(unpID string) {
+	pdb.SeqResChains = make(map[string]map[int64]*Residue)
+	pdb.UniProtPositions = map[string]map[int64][]*Residue{unpID: {}}
+	pdb.UniProtUnobserved = map[string]map[int64][]*Residue{unpID: {}}
+	for chain, residues := range pdb.Residues {
+		pdb.SeqResChains[chain] = make(map[int64]*Residue)
+		for _, res := range residues {
+			res.Position = res.StructPosition
+			res.UnpID = unpID
+			res.UnpPosition = res.StructPosition
+			pdb.SeqResChains[chain][res.Position] = res
+			pdb.UniProtPositions[unpID][res.UnpPosition] = append(pdb.UniProtPositions[unpID][res.UnpPosition], res)
+		}
+	}
+	pdb.markModeled()
+}
+
+// ParsePAE parses an AlphaFold predicted aligned error JSON, returning the matrix indexed by residue
+// (0-based, row as the aligned residue and column as the scored one) and its maximum possible value.
+// Both the current format (predicted_aligned_error matrix) and the first one (residue1, residue2 and distance lists)
+// are supported.
+func ParsePAE(raw []byte) ([][]float64, float64, error) {
+	type pae struct {
+		PAE      [][]float64 `json:"predicted_aligned_error"`
+		MaxPAE   float64     `json:"max_predicted_aligned_error"`
+		Residue1 []int       `json:"residue1"`
+		Residue2 []int       `json:"residue2"`
+		Distance []float64   `json:"distance"`
+	}
+
+	// Files are a single element list, except for some early ones with the object alone
+	var entries []pae
+	err := json.Unmarshal(raw, &entries)
+	if err != nil {
+		var entry pae
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			return nil, 0, fmt.Errorf("unmarshal: %v", err)
+		}
+		entries = []pae{entry}
+	}
+	if len(entries) == 0 {
+		return nil, 0, errors.New("empty PAE")
+	}
+
+	e := entries[0]
+	if e.PAE != nil {
+		return e.PAE, e.MaxPAE, nil
+	}
+
+	if len(e.Residue1) != len(e.Distance) || len(e.Residue2) != len(e.Distance) || len(e.Distance) == 0 {
+		return nil, 0, errors.New("invalid PAE lists")
+	}
+	n := 0
+	for i := range e.Residue1 {
+		if e.Residue1[i] > n {
+			n = e.Residue1[i]
+		}
+		if e.Residue2[i] > n {
+			n = e.Residue2[i]
+		}
+	}
+	matrix := make([][]float64, n)
+	for i := range matrix {
+		matrix[i] = make([]float64, n)
+	}
+	for i, d := range e.Distance {
+		if e.Residue1[i] < 1 || e.Residue2[i] < 1 {
+			return nil, 0, errors.New("invalid PAE residue number")
+		}
+		matrix[e.Residue1[i]-1][e.Residue2[i]-1] = d
+	}
+
+	return matrix, e.MaxPAE, nil
+}
+
+// ResiduePAE returns the predicted aligned error of residue b when aligned on residue a, both in the first chain.
+func (pdb *PDB) ResiduePAE(a *Residue, b *Residue) (float64, error) {
+	i, j := a.Position-1, b.Position-1
+	if i < 0 || j < 0 || i >= int64(len(pdb.PAE)) || j >= int64(len(pdb.PAE[i])) {
+		return 0, errors.New("residue out of PAE range")
+	}
+	return pdb.PAE[i][j], nil
+}
@@ -0,0 +1,101 @@
+package pdb
+
+import (
+	"testing"
+)
+
+const alphaFoldCIF = `data_AF-P01308-F1
+#
+_struct.entry_id AF-P01308-F1
+_struct.title "AlphaFold monomer v2.0 prediction for Insulin (P01308)"
+#
+_pdbx_audit_revision_history.revision_date 2021-07-01
+#
+loop_
+_entity_poly_seq.entity_id
+_entity_poly_seq.hetero
+_entity_poly_seq.mon_id
+_entity_poly_seq.num
+1 n MET 1
+1 n ALA 2
+1 n LEU 3
+#
+loop_
+_atom_site.group_PDB
+_atom_site.id
+_atom_site.type_symbol
+_atom_site.label_atom_id
+_atom_site.label_alt_id
+_atom_site.label_comp_id
+_atom_site.label_asym_id
+_atom_site.label_entity_id
+_atom_site.label_seq_id
+_atom_site.pdbx_PDB_ins_code
+_atom_site.Cartn_x
+_atom_site.Cartn_y
+_atom_site.Cartn_z
+_atom_site.occupancy
+_atom_site.B_iso_or_equiv
+_atom_site.pdbx_formal_charge
+_atom_site.auth_seq_id
+_atom_site.auth_comp_id
+_atom_site.auth_asym_id
+_atom_site.auth_atom_id
+_atom_site.pdbx_PDB_model_num
+ATOM 1 N N . MET A 1 1 ? -2.1 1.3 0.5 1.0 35.21 ? 1 MET A N 1
+ATOM 2 C CA . MET A 1 1 ? -1.0 0.4 0.2 1.0 36.80 ? 1 MET A CA 1
+ATOM 3 N N . ALA A 1 2 ? 0.3 1.0 0.1 1.0 52.40 ? 2 ALA A N 1
+ATOM 4 C CA . ALA A 1 2 ? 1.5 0.2 -0.1 1.0 54.12 ? 2 ALA A CA 1
+ATOM 5 N N . LEU A 1 3 ? 2.7 0.9 -0.3 1.0 91.03 ? 3 LEU A N 1
+ATOM 6 C CA . LEU A 1 3 ? 3.9 0.1 -0.4 1.0 92.50 ? 3 LEU A CA 1
+#
+`
+
+func TestAlphaFold(t *testing.T) {
+	pae := []byte(`[{"predicted_aligned_error":[[0,1.5,8],[2,0,3.25],[9,4,0]],"max_predicted_aligned_error":31.75}]`)
+	pdb, err := NewPDBFromAlphaFoldRaw("P01308", []byte(alphaFoldCIF), pae)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pdb.Method != PredictedMethod || pdb.Resolution != 0 || pdb.Date == nil || pdb.Date.Year() != 2021 {
+		t.Errorf("unexpected metadata %s %.2f %v", pdb.Method, pdb.Resolution, pdb.Date)
+	}
+
+	for pos, plddt := range map[int64]float64{1: 36.80, 2: 54.12, 3: 92.50} {
+		residues := pdb.UniProtPositions["P01308"][pos]
+		if len(residues) != 1 || residues[0].PLDDT != plddt || residues[0].UnpPosition != pos {
+			t.Errorf("unexpected residue at UniProt %d", pos)
+		}
+	}
+	for _, res := range pdb.SeqRes["A"] {
+		if !res.Modeled {
+			t.Errorf("SEQRES %s not modeled", res.Name3)
+		}
+	}
+
+	if pdb.MaxPAE != 31.75 {
+		t.Errorf("unexpected max PAE %.2f", pdb.MaxPAE)
+	}
+	if v, err := pdb.ResiduePAE(pdb.Chains["A"][2], pdb.Chains["A"][3]); err != nil || v != 3.25 {
+		t.Errorf("unexpected PAE %.2f: %v", v, err)
+	}
+	if _, err := pdb.ResiduePAE(pdb.Chains["A"][1], &Residue{Position: 4}); err == nil {
+		t.Errorf("expected error out of PAE range")
+	}
+}
+
+func TestParsePAE(t *testing.T) {
+	lists := []byte(`[{"residue1":[1,1,2,2],"residue2":[1,2,1,2],"distance":[0.0,1.5,2.0,0.0],"max_predicted_aligned_error":31.75}]`)
+	matrix, max, err := ParsePAE(lists)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matrix) != 2 || matrix[0][1] != 1.5 || matrix[1][0] != 2 || max != 31.75 {
+		t.Errorf("unexpected PAE %v %.2f", matrix, max)
+	}
+
+	if _, _, err := ParsePAE([]byte(`[]`)); err == nil {
+		t.Errorf("expected error for empty PAE")
+	}
+}
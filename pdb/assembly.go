@@ -0,0 +1,337 @@
+package pdb
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Assembly is a biological assembly of the structure, built by applying symmetry operations to sets of chains.
+type Assembly struct {
+	ID         string               `json:"id"`
+	Oligomer   string               `json:"oligomer"` // oligomeric state, i.e. dimeric
+	Generators []*AssemblyGenerator `json:"generators"`
+}
+
+// AssemblyGenerator is a set of operations applied to a set of chains.
+type AssemblyGenerator struct {
+	Chains       []string             `json:"chains"`       // author chain IDs
+	LabelAsymIDs []string             `json:"labelAsymIds"` // mmCIF label chain IDs, only available when parsed from a CIF file
+	Operations   []*AssemblyOperation `json:"operations"`
+}
+
+// AssemblyOperation is a rotation and translation applied to the chains, in Cartesian coordinates.
+type AssemblyOperation struct {
+	ID string `json:"id"`
+	Transform
+}
+
+// ExtractAssemblies parses the biological assemblies from the PDB REMARK 350 records.
+func (pdb *PDB) ExtractAssemblies(rawPDB []byte) error {
+	r, _ := regexp.Compile("(?m)^REMARK 350.*$")
+	remarks := r.FindAllString(string(rawPDB), -1)
+
+	var assemblies []*Assembly
+	var assembly *Assembly
+	var generator *AssemblyGenerator
+	var software string
+	addChains := func(list string) {
+		for _, chain := range strings.Split(list, ",") {
+			if chain = strings.TrimSpace(chain); chain != "" {
+				generator.Chains = append(generator.Chains, chain)
+			}
+		}
+	}
+
+	for _, remark := range remarks {
+		// https://www.wwpdb.org/documentation/file-format-content/format33/remarks2.html#REMARK%20350
+		line := strings.TrimSpace(strings.TrimPrefix(remark, "REMARK 350"))
+		switch {
+		case strings.HasPrefix(line, "BIOMOLECULE:"):
+			if assembly != nil && assembly.Oligomer == "" {
+				assembly.Oligomer = software
+			}
+			assembly = &Assembly{ID: strings.TrimSpace(strings.TrimPrefix(line, "BIOMOLECULE:"))}
+			assemblies = append(assemblies, assembly)
+			generator, software = nil, ""
+		case assembly == nil:
+			continue
+		case strings.HasPrefix(line, "AUTHOR DETERMINED BIOLOGICAL UNIT:"):
+			assembly.Oligomer = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(line, "AUTHOR DETERMINED BIOLOGICAL UNIT:")))
+		case strings.HasPrefix(line, "SOFTWARE DETERMINED QUATERNARY STRUCTURE:"):
+			software = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(line, "SOFTWARE DETERMINED QUATERNARY STRUCTURE:")))
+		case strings.HasPrefix(line, "APPLY THE FOLLOWING TO CHAINS:"):
+			generator = &AssemblyGenerator{}
+			assembly.Generators = append(assembly.Generators, generator)
+			addChains(strings.TrimPrefix(line, "APPLY THE FOLLOWING TO CHAINS:"))
+		case strings.HasPrefix(line, "AND CHAINS:") && generator != nil:
+			addChains(strings.TrimPrefix(line, "AND CHAINS:"))
+		case strings.HasPrefix(line, "BIOMT") && generator != nil:
+			fields := strings.Fields(line)
+			if len(fields) != 6 || len(fields[0]) != 6 {
+				return fmt.Errorf("invalid BIOMT record: %s", remark)
+			}
+			row, err := strconv.Atoi(fields[0][5:])
+			if err != nil || row < 1 || row > 3 {
+				return fmt.Errorf("invalid BIOMT row: %s", remark)
+			}
+
+			if row == 1 {
+				generator.Operations = append(generator.Operations, &AssemblyOperation{ID: fields[1]})
+			}
+			op := generator.Operations[len(generator.Operations)-1]
+			for i, f := range fields[2:] {
+				v, err := strconv.ParseFloat(f, 64)
+				if err != nil {
+					return fmt.Errorf("invalid BIOMT value: %s", remark)
+				}
+				if i < 3 {
+					op.Rotation[row-1][i] = v
+				} else {
+					op.Translation[row-1] = v
+				}
+			}
+		}
+	}
+	if assembly != nil && assembly.Oligomer == "" {
+		assembly.Oligomer = software
+	}
+
+	pdb.Assemblies = assemblies
+	return nil
+}
+
+// ExtractCIFAssemblies parses the biological assemblies from the CIF _pdbx_struct_assembly,
+// _pdbx_struct_assembly_gen and _pdbx_struct_oper_list categories.
+func (pdb *PDB) ExtractCIFAssemblies(rawCIF []byte) error {
+	block, err := firstCIFBlock(rawCIF)
+	if err != nil {
+		return err
+	}
+
+	return pdb.extractCIFAssemblies(block)
+}
+
+func (pdb *PDB) extractCIFAssemblies(block *DataBlock) error {
+	gen := block.Category("pdbx_struct_assembly_gen")
+	opers := block.Category("pdbx_struct_oper_list")
+	if gen == nil || opers == nil {
+		pdb.Assemblies = nil
+		return nil
+	}
+
+	parseFloat := func(s string) float64 {
+		v, _ := strconv.ParseFloat(s, 64)
+		return v
+	}
+	operations := make(map[string]*AssemblyOperation)
+	for i := 0; i < opers.Len(); i++ {
+		op := &AssemblyOperation{ID: opers.Get("id", i)}
+		for r := 0; r < 3; r++ {
+			for c := 0; c < 3; c++ {
+				op.Rotation[r][c] = parseFloat(opers.Get(fmt.Sprintf("matrix[%d][%d]", r+1, c+1), i))
+			}
+			op.Translation[r] = parseFloat(opers.Get(fmt.Sprintf("vector[%d]", r+1), i))
+		}
+		operations[op.ID] = op
+	}
+
+	// Label to author chain IDs
+	authChains := make(map[string]string)
+	for _, atoms := range [][]*Atom{pdb.Atoms, pdb.HetAtoms} {
+		for _, a := range atoms {
+			authChains[a.LabelAsymID] = a.Chain
+		}
+	}
+
+	var assemblies []*Assembly
+	byID := make(map[string]*Assembly)
+	if info := block.Category("pdbx_struct_assembly"); info != nil {
+		for i := 0; i < info.Len(); i++ {
+			a := &Assembly{ID: info.Get("id", i), Oligomer: info.Get("oligomeric_details", i)}
+			byID[a.ID] = a
+			assemblies = append(assemblies, a)
+		}
+	}
+
+	for i := 0; i < gen.Len(); i++ {
+		id := gen.Get("assembly_id", i)
+		a, ok := byID[id]
+		if !ok {
+			a = &Assembly{ID: id}
+			byID[id] = a
+			assemblies = append(assemblies, a)
+		}
+
+		ops, err := operationExpression(gen.Get("oper_expression", i), operations)
+		if err != nil {
+			return fmt.Errorf("assembly %s: %v", id, err)
+		}
+
+		g := &AssemblyGenerator{Operations: ops}
+		seen := make(map[string]bool)
+		for _, asym := range strings.Split(gen.Get("asym_id_list", i), ",") {
+			if asym = strings.TrimSpace(asym); asym == "" {
+				continue
+			}
+			g.LabelAsymIDs = append(g.LabelAsymIDs, asym)
+			if chain, ok := authChains[asym]; ok && !seen[chain] {
+				seen[chain] = true
+				g.Chains = append(g.Chains, chain)
+			}
+		}
+		a.Generators = append(a.Generators, g)
+	}
+
+	pdb.Assemblies = assemblies
+	return nil
+}
+
+// operationExpression expands a CIF operation expression such as 1, 1,2,3, (1-60) or (1-5)(6-10)
+// into its operations. Consecutive parenthesized lists are combined as a Cartesian product,
+// where the rightmost operation is applied first.
+func operationExpression(expr string, operations map[string]*AssemblyOperation) ([]*AssemblyOperation, error) {
+	expr = strings.TrimSpace(expr)
+	groups := []string{expr}
+	if strings.HasPrefix(expr, "(") {
+		groups = strings.Split(strings.TrimSuffix(expr[1:], ")"), ")(")
+	}
+
+	var result []*AssemblyOperation
+	for i := len(groups) - 1; i >= 0; i-- {
+		var ops []*AssemblyOperation
+		for _, item := range strings.Split(groups[i], ",") {
+			item = strings.TrimSpace(item)
+			ids := []string{item}
+			if bounds := strings.Split(item, "-"); len(bounds) == 2 {
+				from, err1 := strconv.Atoi(bounds[0])
+				to, err2 := strconv.Atoi(bounds[1])
+				if err1 != nil || err2 != nil || from > to {
+					return nil, fmt.Errorf("invalid operation range %s", item)
+				}
+				ids = nil
+				for n := from; n <= to; n++ {
+					ids = append(ids, strconv.Itoa(n))
+				}
+			}
+			for _, id := range ids {
+				op, ok := operations[id]
+				if !ok {
+					return nil, fmt.Errorf("operation %s not found", id)
+				}
+				ops = append(ops, op)
+			}
+		}
+
+		if result == nil {
+			result = ops
+			continue
+		}
+		var product []*AssemblyOperation
+		for _, left := range ops {
+			for _, right := range result {
+				product = append(product, composeOperations(left, right))
+			}
+		}
+		result = product
+	}
+
+	return result, nil
+}
+
+// composeOperations returns the operation equivalent to applying right and then left.
+func composeOperations(left *AssemblyOperation, right *AssemblyOperation) *AssemblyOperation {
+	op := &AssemblyOperation{ID: left.ID + "x" + right.ID}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				op.Rotation[i][j] += left.Rotation[i][k] * right.Rotation[k][j]
+			}
+			op.Translation[i] += left.Rotation[i][j] * right.Translation[j]
+		}
+		op.Translation[i] += left.Translation[i]
+	}
+	return op
+}
+
+// BuildAssembly returns a new structure with the chains of the given assembly, transformed by its operations.
+// Chains keep their ID for identity operations, and are renamed as chain-operation for the rest (i.e. A-2).
+// Since renamed chains have more than one character, the assembly can only be written in CIF format.
+// Atoms are renumbered sequentially, and only the first model is used.
+func (pdb *PDB) BuildAssembly(id string) (*PDB, error) {
+	var assembly *Assembly
+	for _, a := range pdb.Assemblies {
+		if a.ID == id {
+			assembly = a
+		}
+	}
+	if assembly == nil {
+		return nil, fmt.Errorf("assembly %s not found", id)
+	}
+
+	var atoms, hetatms []*Atom
+	number := int64(1)
+	transform := func(source []*Atom, selected func(*Atom) bool, op *AssemblyOperation) (copies []*Atom) {
+		for _, a := range source {
+			if !selected(a) {
+				continue
+			}
+			c := *a
+			if !op.isIdentity() {
+				c.Chain += "-" + op.ID
+			}
+			c.Number = number
+			c.X, c.Y, c.Z = op.Apply(a.X, a.Y, a.Z)
+			copies = append(copies, &c)
+			number++
+		}
+		return copies
+	}
+
+	for _, g := range assembly.Generators {
+		selected := g.selector()
+		for _, op := range g.Operations {
+			atoms = append(atoms, transform(pdb.Atoms, selected, op)...)
+			hetatms = append(hetatms, transform(pdb.HetAtoms, selected, op)...)
+		}
+	}
+	if len(atoms) == 0 {
+		return nil, errors.New("empty assembly")
+	}
+
+	built, err := NewPDBFromAtoms(atoms, hetatms)
+	if err != nil {
+		return nil, fmt.Errorf("build assembly %s: %v", id, err)
+	}
+	built.ID = pdb.ID
+	built.Title = pdb.Title
+	built.Method = pdb.Method
+	built.Resolution = pdb.Resolution
+	built.Date = pdb.Date
+	built.Assemblies = []*Assembly{assembly}
+
+	return built, nil
+}
+
+// selector returns a predicate for the atoms the generator applies to, by label chain ID if available.
+func (g *AssemblyGenerator) selector() func(*Atom) bool {
+	if len(g.LabelAsymIDs) > 0 {
+		asyms := make(map[string]bool)
+		for _, asym := range g.LabelAsymIDs {
+			asyms[asym] = true
+		}
+		return func(a *Atom) bool {
+			return a.LabelAsymID != "" && asyms[a.LabelAsymID]
+		}
+	}
+
+	chains := make(map[string]bool)
+	for _, chain := range g.Chains {
+		chains[chain] = true
+	}
+	return func(a *Atom) bool {
+		return chains[a.Chain]
+	}
+}
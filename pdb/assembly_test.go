@@ -0,0 +1,100 @@
+package pdb
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAssemblies(t *testing.T) {
+	raw, err := LoadTestFile("./testdata/1mso.pdb")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+	pdb, err := NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pdb.ExtractAssemblies(raw); err != nil {
+		t.Fatal(err)
+	}
+
+	rawCIF, err := LoadTestFile("./testdata/1mso.cif")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+	fromCIF, err := NewPDBFromCIF(rawCIF)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []*PDB{pdb, fromCIF} {
+		if len(p.Assemblies) != 1 {
+			t.Fatalf("expected 1 assembly, got %d", len(p.Assemblies))
+		}
+		a := p.Assemblies[0]
+		if a.ID != "1" || a.Oligomer != "dodecameric" || len(a.Generators) != 1 {
+			t.Errorf("unexpected assembly %s %s with %d generators", a.ID, a.Oligomer, len(a.Generators))
+		}
+		g := a.Generators[0]
+		if len(g.Chains) != 4 || len(g.Operations) != 3 {
+			t.Fatalf("expected 4 chains and 3 operations, got %v and %d", g.Chains, len(g.Operations))
+		}
+		if op := g.Operations[1]; op.ID != "2" || math.Abs(op.Rotation[0][1]+0.866025) > 1e-5 {
+			t.Errorf("unexpected operation %s %v", op.ID, op.Rotation)
+		}
+
+		// The hexamer is built around the threefold axis
+		built, err := p.BuildAssembly("1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(built.Atoms) != 3*len(p.Atoms) || len(built.HetAtoms) != 3*len(p.HetAtoms) {
+			t.Errorf("expected %d atoms and %d het atoms, got %d and %d",
+				3*len(p.Atoms), 3*len(p.HetAtoms), len(built.Atoms), len(built.HetAtoms))
+		}
+		if len(built.Residues) != 12 {
+			t.Errorf("expected 12 chains, got %d", len(built.Residues))
+		}
+
+		orig := p.ResidueAt("B", 10, "").Atom("NE2")
+		mate := built.ResidueAt("B-2", 10, "").Atom("NE2")
+		if orig == nil || mate == nil {
+			t.Fatalf("HIS B10 NE2 not found")
+		}
+		if d := math.Abs(math.Hypot(orig.X, orig.Y) - math.Hypot(mate.X, mate.Y)); d > 1e-3 || mate.Z != orig.Z {
+			t.Errorf("expected symmetric copy around the z axis")
+		}
+		if built.ResidueAt("B", 10, "").Atom("NE2").X != orig.X {
+			t.Errorf("expected identity operation to keep the coordinates")
+		}
+	}
+
+	if _, err := pdb.BuildAssembly("2"); err == nil {
+		t.Errorf("expected error for missing assembly")
+	}
+}
+
+func TestOperationExpression(t *testing.T) {
+	ops := map[string]*AssemblyOperation{}
+	for i, id := range []string{"1", "2", "3", "X0"} {
+		op := &AssemblyOperation{ID: id}
+		op.Rotation = [3][3]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+		op.Translation[0] = float64(i)
+		ops[id] = op
+	}
+
+	expanded, err := operationExpression("(X0)(1-3)", ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expanded) != 3 || expanded[2].ID != "X0x3" || expanded[2].Translation[0] != 5 {
+		t.Errorf("unexpected product expansion")
+	}
+
+	if expanded, _ := operationExpression("1,3", ops); len(expanded) != 2 {
+		t.Errorf("expected 2 operations, got %d", len(expanded))
+	}
+	if _, err := operationExpression("(1-5)", ops); err == nil {
+		t.Errorf("expected error for missing operation")
+	}
+}
@@ -12,9 +12,12 @@ import (
 type Atom struct {
 	// PDB columns for the ATOM tag
 	Number        int64
+	Name          string
+	AltLoc        string
 	Residue       string
 	Chain         string
 	ResidueNumber int64
+	InsertionCode string
 	X             float64
 	Y             float64
 	Z             float64
@@ -22,27 +25,60 @@ type Atom struct {
 	BFactor       float64
 	Element       string
 	Charge        string
+
+	// Model number from the enclosing MODEL record, 1 if the structure has a single model.
+	Model int64
+
+	// Het is true for HETATM records.
+	Het bool
+
+	// SASA is the solvent accessible surface area of the atom, set by the native, JSON and XML results of package sasa.
+	SASA float64
+
+	residue *Residue  // residue in the structure the atom belongs to, nil for HETATM records except modified residues
+	het     *HetGroup // het group instance the atom belongs to, nil for ATOM records and modified residues
+	owner   *Model    // model the atom belongs to, whose spatial indexes are discarded when the atom is moved
+
+	// mmCIF label identifiers, only available when parsed from a CIF file.
+	LabelAsymID string
+	LabelSeqID  int64
+	EntityID    string
 }
 
-// extractPDBATMRecords extracts either ATOM or HETATM records.
+// Parent returns the residue the atom belongs to, or nil for HETATM records other than modified residues.
+func (a *Atom) Parent() *Residue {
+	return a.residue
+}
+
+// extractPDBATMRecords extracts either ATOM or HETATM records, along with the model they belong to.
 func (pdb *PDB) extractPDBATMRecords(rawPDB []byte, recordName string) ([]*Atom, error) {
 	var atoms []*Atom
 
-	r, _ := regexp.Compile("(?m)^" + recordName + ".*$")
+	r, _ := regexp.Compile("(?m)^(MODEL|" + recordName + ").*$")
 	matches := r.FindAllString(string(rawPDB), -1)
-	if len(matches) == 0 {
-		return atoms, errors.New("atoms not found")
-	}
 
 	var lastRes string
+	model := int64(1)
 	for _, match := range matches {
-		var atom Atom
+		if strings.HasPrefix(match, "MODEL") {
+			// https://www.wwpdb.org/documentation/file-format-content/format33/sect9.html#MODEL
+			model, _ = strconv.ParseInt(strings.TrimSpace(match[5:]), 10, 64)
+			continue
+		}
+		if len(match) < 80 {
+			match += strings.Repeat(" ", 80-len(match))
+		}
+
+		atom := Atom{Model: model, Het: recordName == "HETATM"}
 
 		// https://www.wwpdb.org/documentation/file-format-content/format23/sect9.html#ATOM
 		atom.Number, _ = strconv.ParseInt(strings.TrimSpace(match[6:11]), 10, 64)
+		atom.Name = strings.TrimSpace(match[12:16])
+		atom.AltLoc = strings.TrimSpace(match[16:17])
 		atom.Residue = strings.TrimSpace(match[17:20])
 		atom.Chain = match[21:22]
 		atom.ResidueNumber, _ = strconv.ParseInt(strings.TrimSpace(match[22:26]), 10, 64)
+		atom.InsertionCode = strings.TrimSpace(match[26:27])
 		atom.X, _ = strconv.ParseFloat(strings.TrimSpace(match[30:38]), 64)
 		atom.Y, _ = strconv.ParseFloat(strings.TrimSpace(match[38:46]), 64)
 		atom.Z, _ = strconv.ParseFloat(strings.TrimSpace(match[46:54]), 64)
@@ -55,17 +91,97 @@ func (pdb *PDB) extractPDBATMRecords(rawPDB []byte, recordName string) ([]*Atom,
 
 		if recordName == "HETATM" && atom.Residue != lastRes {
 			lastRes = atom.Residue
-			exists := false
-			for _, het := range pdb.HetGroups {
-				if het == lastRes {
-					exists = true
+			pdb.addHetGroup(lastRes)
+		}
+	}
+
+	if len(atoms) == 0 {
+		return atoms, errors.New("atoms not found")
+	}
+
+	return atoms, nil
+}
+
+// AltLocPolicy defines which alternate location (altLoc) atoms are kept when loading a structure.
+type AltLocPolicy int
+
+const (
+	AltLocHighestOccupancy AltLocPolicy = iota // keep the conformer with the highest mean occupancy in each residue
+	AltLocFirst                                // keep the first conformer listed in each residue
+	AltLocAll                                  // keep all conformers
+)
+
+// filterAltLocs returns the atoms without alternate locations, plus the ones from the
+// alternate location chosen for each residue according to the policy.
+func filterAltLocs(atoms []*Atom, policy AltLocPolicy) []*Atom {
+	if policy == AltLocAll {
+		return atoms
+	}
+
+	type occupancy struct {
+		sum float64
+		n   float64
+	}
+	type modelResidueKey struct {
+		model int64
+		residueKey
+	}
+	atomKey := func(atom *Atom) modelResidueKey {
+		return modelResidueKey{atom.Model, atomResidueKey(atom)}
+	}
+
+	// Alternate location labels in order of appearance for each residue
+	labels := make(map[modelResidueKey][]string)
+	occupancies := make(map[modelResidueKey]map[string]*occupancy)
+	for _, atom := range atoms {
+		if atom.AltLoc == "" {
+			continue
+		}
+		key := atomKey(atom)
+		if occupancies[key] == nil {
+			occupancies[key] = make(map[string]*occupancy)
+		}
+		occ, ok := occupancies[key][atom.AltLoc]
+		if !ok {
+			occ = &occupancy{}
+			occupancies[key][atom.AltLoc] = occ
+			labels[key] = append(labels[key], atom.AltLoc)
+		}
+		occ.sum += atom.Occupancy
+		occ.n++
+	}
+
+	chosen := make(map[modelResidueKey]string)
+	for key, ls := range labels {
+		chosen[key] = ls[0]
+		if policy == AltLocHighestOccupancy {
+			best := occupancies[key][ls[0]]
+			for _, l := range ls[1:] {
+				occ := occupancies[key][l]
+				if occ.sum/occ.n > best.sum/best.n {
+					best = occ
+					chosen[key] = l
 				}
 			}
-			if !exists {
-				pdb.HetGroups = append(pdb.HetGroups, lastRes)
-			}
 		}
 	}
 
-	return atoms, nil
+	var filtered []*Atom
+	for _, atom := range atoms {
+		if atom.AltLoc == "" || chosen[atomKey(atom)] == atom.AltLoc {
+			filtered = append(filtered, atom)
+		}
+	}
+
+	return filtered
+}
+
+// addHetGroup adds a HET group name to the structure if not already present.
+func (pdb *PDB) addHetGroup(name string) {
+	for _, het := range pdb.HetGroups {
+		if het == name {
+			return
+		}
+	}
+	pdb.HetGroups = append(pdb.HetGroups, name)
 }
@@ -2,6 +2,7 @@ package pdb
 
 import (
 	"math"
+	"sort"
 )
 
 // ResiduesDistance returns the distance between two residues as the minimum atom pair distance.
@@ -23,14 +24,24 @@ func AtomsDistance(a1 *Atom, a2 *Atom) float64 {
 	return math.Sqrt(math.Pow(a1.X-a2.X, 2) + math.Pow(a1.Y-a2.Y, 2) + math.Pow(a1.Z-a2.Z, 2))
 }
 
-// CloseResidues returns all residues within a given distance from the specified residue.
+// CloseResidues returns all residues within a given distance from the specified residue, ordered as in the file.
 func CloseResidues(p *PDB, r *Residue, distance float64) (residues []*Residue) {
-	for _, chain := range p.Chains {
-		for _, res := range chain {
-			if res != r && ResiduesDistance(res, r) < distance {
-				residues = append(residues, res)
+	idx := p.AtomIndex()
+	seen := make(map[*Residue]bool)
+	for _, atom := range r.Atoms {
+		for _, near := range idx.WithinAtom(atom, distance) {
+			res := near.Parent()
+			if res == nil || res == r || seen[res] || AtomsDistance(atom, near) >= distance {
+				continue
 			}
+			seen[res] = true
+			residues = append(residues, res)
 		}
 	}
+
+	sort.SliceStable(residues, func(i, j int) bool {
+		return residues[i].Atoms[0].Number < residues[j].Atoms[0].Number
+	})
+
 	return
 }
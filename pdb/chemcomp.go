@@ -0,0 +1,257 @@
+package pdb
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Reference: https://www.wwpdb.org/data/ccd
+
+// ChemComp is a chemical component from the wwPDB Chemical Component Dictionary.
+type ChemComp struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Type      string          `json:"type"` // i.e. NON-POLYMER or L-PEPTIDE LINKING
+	Formula   string          `json:"formula"`
+	Weight    float64         `json:"weight"` // formula weight in Da
+	Charge    int             `json:"charge"` // formal charge
+	Parent    string          `json:"parent"` // standard parent component, for modified residues
+	OneLetter string          `json:"oneLetter"`
+	SMILES    string          `json:"smiles"`
+	InChI     string          `json:"inchi"`
+	InChIKey  string          `json:"inchiKey"`
+	Atoms     []*ChemCompAtom `json:"atoms"`
+	Bonds     []*ChemCompBond `json:"bonds"`
+	Artifact  bool            `json:"artifact"` // common crystallization or buffer additive, see IsArtifact
+}
+
+// ChemCompAtom is an atom of the ideal chemical component.
+type ChemCompAtom struct {
+	Name     string `json:"name"`
+	Element  string `json:"element"`
+	Charge   int    `json:"charge"`
+	Aromatic bool   `json:"aromatic"`
+	Leaving  bool   `json:"leaving"` // lost when bonded to other components, i.e. the OXT of amino acids
+}
+
+// ChemCompBond is a bond between two atoms of the chemical component.
+type ChemCompBond struct {
+	Atom1    string `json:"atom1"`
+	Atom2    string `json:"atom2"`
+	Order    int    `json:"order"` // 1 to 4, single to quadruple, 0 if unknown
+	Aromatic bool   `json:"aromatic"`
+}
+
+// artifacts are common crystallization additives, cryoprotectants and buffer molecules,
+// which are rarely biologically relevant ligands.
+var artifacts = map[string]bool{
+	"SO4": true, "PO4": true, "GOL": true, "EDO": true, "PEG": true, "PGE": true, "PG4": true, "1PE": true,
+	"P6G": true, "2PE": true, "12P": true, "15P": true, "PE4": true, "MPD": true, "MRD": true, "ACT": true,
+	"ACY": true, "FMT": true, "DMS": true, "DMF": true, "CL": true, "NA": true, "K": true, "BR": true,
+	"IOD": true, "NO3": true, "SCN": true, "BME": true, "MES": true, "EPE": true, "TRS": true, "CIT": true,
+	"FLC": true, "TLA": true, "TAR": true, "MLI": true, "MLA": true, "IPA": true, "EOH": true, "MOH": true,
+	"BU3": true, "HEZ": true, "BTB": true, "B3P": true, "IMD": true, "NH4": true, "CAC": true, "AZI": true,
+	"DTT": true, "SIN": true, "BOG": true, "LDA": true, "CXS": true, "CPS": true, "NHE": true, "PEU": true,
+	"UNX": true, "UNL": true, "SUC": true, "TBU": true, "POL": true, "BCN": true,
+}
+
+// IsArtifact returns true if the component code is a common crystallization or buffer additive,
+// such as SO4, GOL or EDO. Metal ions other than Na and K are not considered artifacts.
+func IsArtifact(code string) bool {
+	return artifacts[strings.ToUpper(strings.TrimSpace(code))]
+}
+
+// LoadChemComps reads a Chemical Component Dictionary file, such as components.cif or a subset of it,
+// optionally gzipped. If IDs are given, only those components are kept.
+func LoadChemComps(path string, ids ...string) (map[string]*ChemComp, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("read CCD: %v", err)
+	}
+	defer f.Close()
+
+	return readChemComps(f, ids...)
+}
+
+// ParseChemComps parses the components of a raw Chemical Component Dictionary file, one per data block,
+// optionally gzipped. If IDs are given, only those components are kept.
+func ParseChemComps(raw []byte, ids ...string) (map[string]*ChemComp, error) {
+	return readChemComps(bytes.NewReader(raw), ids...)
+}
+
+// readChemComps reads the dictionary one data block at a time, so only the blocks of the given IDs
+// are kept in memory and parsed, and stops once all of them are found.
+func readChemComps(r io.Reader, ids ...string) (map[string]*ChemComp, error) {
+	r, err := gunzipReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := make(map[string]bool)
+	for _, id := range ids {
+		keep[strings.ToUpper(id)] = true
+	}
+
+	comps := make(map[string]*ChemComp)
+	var block []byte
+	parse := func() error {
+		if block == nil {
+			return nil
+		}
+		cif, err := ParseCIF(block)
+		if err != nil {
+			return fmt.Errorf("parse CCD: %v", err)
+		}
+		for _, b := range cif.Blocks {
+			c, err := newChemComp(b)
+			if err != nil {
+				return fmt.Errorf("component %s: %v", b.Name, err)
+			}
+			comps[c.ID] = c
+		}
+		block = nil
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	selected, text, found := false, false, 0
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// data_ lines inside a semicolon text field are not block headers
+		if strings.HasPrefix(line, ";") {
+			text = !text
+		}
+		if !text && hasPrefixFold(line, "data_") {
+			if err := parse(); err != nil {
+				return nil, err
+			}
+			if len(keep) > 0 && found == len(keep) {
+				break
+			}
+			name := strings.ToUpper(strings.TrimSpace(line[len("data_"):]))
+			selected = len(keep) == 0 || keep[name]
+			if len(keep) > 0 && selected {
+				found++
+			}
+		}
+
+		if selected {
+			block = append(block, line...)
+			block = append(block, '\n')
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read CCD: %v", err)
+	}
+	if err := parse(); err != nil {
+		return nil, err
+	}
+
+	return comps, nil
+}
+
+func newChemComp(block *DataBlock) (*ChemComp, error) {
+	value := func(tag string) string {
+		v, _ := block.Value(tag)
+		return v
+	}
+
+	c := &ChemComp{
+		ID:        value("_chem_comp.id"),
+		Name:      value("_chem_comp.name"),
+		Type:      value("_chem_comp.type"),
+		Formula:   value("_chem_comp.formula"),
+		Parent:    value("_chem_comp.mon_nstd_parent_comp_id"),
+		OneLetter: value("_chem_comp.one_letter_code"),
+	}
+	if c.ID == "" {
+		return nil, fmt.Errorf("missing _chem_comp.id")
+	}
+	c.Weight, _ = strconv.ParseFloat(value("_chem_comp.formula_weight"), 64)
+	c.Charge, _ = strconv.Atoi(value("_chem_comp.pdbx_formal_charge"))
+	c.Artifact = IsArtifact(c.ID)
+
+	if atoms := block.Category("chem_comp_atom"); atoms != nil {
+		for i := 0; i < atoms.Len(); i++ {
+			a := &ChemCompAtom{
+				Name:     atoms.Get("atom_id", i),
+				Element:  atoms.Get("type_symbol", i),
+				Aromatic: atoms.Get("pdbx_aromatic_flag", i) == "Y",
+				Leaving:  atoms.Get("pdbx_leaving_atom_flag", i) == "Y",
+			}
+			a.Charge, _ = strconv.Atoi(atoms.Get("charge", i))
+			c.Atoms = append(c.Atoms, a)
+		}
+	}
+
+	orders := map[string]int{"SING": 1, "DOUB": 2, "TRIP": 3, "QUAD": 4}
+	if bonds := block.Category("chem_comp_bond"); bonds != nil {
+		for i := 0; i < bonds.Len(); i++ {
+			// Unknown orders are kept as 0, so the bond graph is complete
+			order := orders[strings.ToUpper(bonds.Get("value_order", i))]
+			c.Bonds = append(c.Bonds, &ChemCompBond{
+				Atom1:    bonds.Get("atom_id_1", i),
+				Atom2:    bonds.Get("atom_id_2", i),
+				Order:    order,
+				Aromatic: bonds.Get("pdbx_aromatic_flag", i) == "Y",
+			})
+		}
+	}
+
+	// Canonical SMILES preferred over the plain ones, from the first program listed
+	if desc := block.Category("pdbx_chem_comp_descriptor"); desc != nil {
+		var canonical bool
+		for i := 0; i < desc.Len(); i++ {
+			d := desc.Get("descriptor", i)
+			switch desc.Get("type", i) {
+			case "SMILES_CANONICAL":
+				if !canonical {
+					c.SMILES, canonical = d, true
+				}
+			case "SMILES":
+				if c.SMILES == "" {
+					c.SMILES = d
+				}
+			case "InChI":
+				c.InChI = d
+			case "InChIKey":
+				c.InChIKey = d
+			}
+		}
+	}
+
+	return c, nil
+}
+
+// Bond returns the bond between two atoms of the component, or nil if not bonded.
+func (c *ChemComp) Bond(atom1 string, atom2 string) *ChemCompBond {
+	for _, b := range c.Bonds {
+		if (b.Atom1 == atom1 && b.Atom2 == atom2) || (b.Atom1 == atom2 && b.Atom2 == atom1) {
+			return b
+		}
+	}
+	return nil
+}
+
+// SetChemComps sets the chemical component of each het group instance in all models, if found.
+func (pdb *PDB) SetChemComps(comps map[string]*ChemComp) {
+	for _, m := range pdb.Models {
+		for _, het := range m.Hets {
+			if c, ok := comps[het.Name]; ok {
+				het.ChemComp = c
+			}
+		}
+	}
+	for _, het := range pdb.Hets {
+		if c, ok := comps[het.Name]; ok {
+			het.ChemComp = c
+		}
+	}
+}
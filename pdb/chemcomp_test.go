@@ -0,0 +1,108 @@
+package pdb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+)
+
+func TestChemComps(t *testing.T) {
+	comps, err := LoadChemComps("./testdata/components.cif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comps) != 3 {
+		t.Fatalf("expected 3 components, got %d", len(comps))
+	}
+
+	so4 := comps["SO4"]
+	if so4.Name != "SULFATE ION" || so4.Formula != "O4 S" || so4.Weight != 96.063 || so4.Charge != -2 || !so4.Artifact {
+		t.Errorf("unexpected SO4 %+v", so4)
+	}
+	if so4.SMILES != "[O-][S]([O-])(=O)=O" || so4.InChIKey != "QAOWNCQODCNURD-UHFFFAOYSA-L" {
+		t.Errorf("unexpected SO4 descriptors %s %s", so4.SMILES, so4.InChIKey)
+	}
+	if len(so4.Atoms) != 5 || so4.Atoms[3].Charge != -1 || len(so4.Bonds) != 4 {
+		t.Errorf("unexpected SO4 atoms and bonds")
+	}
+	if b := so4.Bond("O1", "S"); b == nil || b.Order != 2 {
+		t.Errorf("expected S=O1 double bond")
+	}
+
+	zn := comps["ZN"]
+	if zn.Name != "ZINC ION" || zn.Artifact || len(zn.Atoms) != 1 || zn.Atoms[0].Element != "ZN" || zn.InChI != "InChI=1S/Zn/q+2" {
+		t.Errorf("unexpected ZN %+v", zn)
+	}
+
+	mse := comps["MSE"]
+	if mse.Parent != "MET" || mse.OneLetter != "M" || !mse.Atoms[4].Leaving || mse.Atoms[4].Name != "OXT" {
+		t.Errorf("unexpected MSE %+v", mse)
+	}
+
+	only, err := LoadChemComps("./testdata/components.cif", "zn")
+	if err != nil || len(only) != 1 || only["ZN"] == nil {
+		t.Errorf("expected only ZN, got %d components: %v", len(only), err)
+	}
+
+	raw, err := LoadTestFile("./testdata/1mso.pdb")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+	pdb, err := NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdb.SetChemComps(comps)
+	for _, het := range pdb.Hets {
+		if het.Name == "ZN" && (het.ChemComp != zn || het.FullName() != "ZINC ION" || het.IsArtifact()) {
+			t.Errorf("unexpected component for %s", het.ID())
+		}
+		if het.IsWater() && (het.ChemComp != nil || het.FullName() != "HOH") {
+			t.Errorf("unexpected component for %s", het.ID())
+		}
+	}
+}
+
+func TestParseChemComps(t *testing.T) {
+	raw := []byte(`data_BAD
+loop_
+BAD 1
+data_XXX
+_chem_comp.id XXX
+_chem_comp.name
+;
+data_ in a text field
+;
+loop_
+_chem_comp_bond.atom_id_1
+_chem_comp_bond.atom_id_2
+_chem_comp_bond.value_order
+C1 C2 SING
+C2 C3 HYBRID
+C3 C4 DOUB
+`)
+
+	// The malformed block is never parsed if not requested
+	if _, err := ParseChemComps(raw); err == nil {
+		t.Errorf("expected error for malformed block")
+	}
+	comps, err := ParseChemComps(raw, "XXX")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Unknown bond orders are kept as 0
+	xxx := comps["XXX"]
+	if len(comps) != 1 || xxx.Name != "data_ in a text field" || len(xxx.Bonds) != 3 || xxx.Bond("C2", "C3").Order != 0 || xxx.Bond("C3", "C4").Order != 2 {
+		t.Errorf("unexpected XXX %+v", xxx)
+	}
+
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	w.Write(raw)
+	w.Close()
+	comps, err = ParseChemComps(b.Bytes(), "xxx")
+	if err != nil || comps["XXX"] == nil {
+		t.Errorf("expected XXX from gzipped dictionary: %v", err)
+	}
+}
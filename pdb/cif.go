@@ -0,0 +1,301 @@
+package pdb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Reference: https://www.iucr.org/resources/cif/spec/version1.1/cifsyntax
+
+// CIF represents a parsed CIF/mmCIF file as a list of data blocks.
+type CIF struct {
+	Blocks []*DataBlock
+}
+
+// DataBlock represents a single data_ block, holding every item as a column of values.
+// Single (non-looped) items are stored as a column of length one.
+type DataBlock struct {
+	Name  string
+	items map[string][]string // lowercase tag to values
+}
+
+// Category represents all the items of a block that share a category prefix (i.e. _atom_site),
+// viewed as a table of rows.
+type Category struct {
+	Name    string
+	columns map[string][]string // lowercase field name to values
+	length  int
+}
+
+// ParseCIF tokenizes the raw CIF text and returns all its data blocks.
+// Unquoted ? and . values (unknown and inapplicable) are stored as empty strings.
+func ParseCIF(raw []byte) (*CIF, error) {
+	lex := &cifLexer{src: string(raw)}
+	cif := &CIF{}
+
+	var block *DataBlock
+	newBlock := func(name string) {
+		block = &DataBlock{Name: name, items: make(map[string][]string)}
+		cif.Blocks = append(cif.Blocks, block)
+	}
+
+	tok, err := lex.next()
+	for err == nil && tok.kind != tokEOF {
+		switch tok.kind {
+		case tokData:
+			newBlock(tok.text)
+			tok, err = lex.next()
+
+		case tokSave:
+			// Save frames only appear in dictionaries, skip them entirely.
+			for err == nil && tok.kind != tokEOF {
+				tok, err = lex.next()
+				if tok.kind == tokSave && tok.text == "" {
+					tok, err = lex.next()
+					break
+				}
+			}
+
+		case tokLoop:
+			if block == nil {
+				newBlock("")
+			}
+			var tags []string
+			tok, err = lex.next()
+			for err == nil && tok.kind == tokTag {
+				tags = append(tags, strings.ToLower(tok.text))
+				tok, err = lex.next()
+			}
+			if len(tags) == 0 {
+				return nil, fmt.Errorf("line %d: loop_ without tags", lex.line)
+			}
+			n := 0
+			for err == nil && tok.kind == tokValue {
+				tag := tags[n%len(tags)]
+				block.items[tag] = append(block.items[tag], tok.value())
+				n++
+				tok, err = lex.next()
+			}
+			if n%len(tags) != 0 {
+				return nil, fmt.Errorf("line %d: loop of %s has %d values for %d tags", lex.line, tags[0], n, len(tags))
+			}
+
+		case tokTag:
+			if block == nil {
+				newBlock("")
+			}
+			tag := strings.ToLower(tok.text)
+			tok, err = lex.next()
+			if err != nil {
+				break
+			}
+			if tok.kind != tokValue {
+				return nil, fmt.Errorf("line %d: missing value for %s", lex.line, tag)
+			}
+			block.items[tag] = append(block.items[tag], tok.value())
+			tok, err = lex.next()
+
+		case tokValue:
+			return nil, fmt.Errorf("line %d: unexpected value %q", lex.line, tok.text)
+
+		default:
+			tok, err = lex.next()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cif.Blocks) == 0 {
+		return nil, errors.New("no data blocks found")
+	}
+
+	return cif, nil
+}
+
+// Block returns the data block with the given name (case-insensitive), or nil if not found.
+func (c *CIF) Block(name string) *DataBlock {
+	for _, b := range c.Blocks {
+		if strings.EqualFold(b.Name, name) {
+			return b
+		}
+	}
+	return nil
+}
+
+// Value returns the first value for the given tag (i.e. _struct.title),
+// and false if the tag is missing or its value is unknown/inapplicable.
+func (b *DataBlock) Value(tag string) (string, bool) {
+	values := b.items[strings.ToLower(tag)]
+	if len(values) == 0 || values[0] == "" {
+		return "", false
+	}
+	return values[0], true
+}
+
+// Values returns all the values for the given tag.
+func (b *DataBlock) Values(tag string) []string {
+	return b.items[strings.ToLower(tag)]
+}
+
+// Category returns the table of items sharing the given category name, with or without
+// the leading underscore (i.e. atom_site), or nil if the block has no such items.
+func (b *DataBlock) Category(name string) *Category {
+	prefix := "_" + strings.TrimPrefix(strings.ToLower(name), "_") + "."
+	cat := &Category{Name: strings.TrimSuffix(prefix, "."), columns: make(map[string][]string)}
+	for tag, values := range b.items {
+		if strings.HasPrefix(tag, prefix) {
+			cat.columns[tag[len(prefix):]] = values
+			if len(values) > cat.length {
+				cat.length = len(values)
+			}
+		}
+	}
+
+	if len(cat.columns) == 0 {
+		return nil
+	}
+	return cat
+}
+
+// Len returns the number of rows in the category.
+func (c *Category) Len() int {
+	return c.length
+}
+
+// Has returns true if the category contains the given field.
+func (c *Category) Has(field string) bool {
+	_, ok := c.columns[strings.ToLower(field)]
+	return ok
+}
+
+// Get returns the value of a field in the given row, or an empty string if not present.
+func (c *Category) Get(field string, row int) string {
+	values := c.columns[strings.ToLower(field)]
+	if row >= len(values) {
+		return ""
+	}
+	return values[row]
+}
+
+// Column returns all the values of a field.
+func (c *Category) Column(field string) []string {
+	return c.columns[strings.ToLower(field)]
+}
+
+type cifTokenKind int
+
+const (
+	tokEOF cifTokenKind = iota
+	tokData
+	tokLoop
+	tokSave
+	tokTag
+	tokValue
+)
+
+type cifToken struct {
+	kind   cifTokenKind
+	text   string
+	quoted bool
+}
+
+// value returns the token text, converting unquoted CIF null values to empty strings.
+func (t cifToken) value() string {
+	if !t.quoted && (t.text == "?" || t.text == ".") {
+		return ""
+	}
+	return t.text
+}
+
+type cifLexer struct {
+	src  string
+	pos  int
+	line int
+}
+
+// next returns the following token in the input.
+func (l *cifLexer) next() (cifToken, error) {
+	// Skip whitespace and comments
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == '#' {
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+			continue
+		}
+		if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			break
+		}
+		if c == '\n' {
+			l.line++
+		}
+		l.pos++
+	}
+
+	if l.pos >= len(l.src) {
+		return cifToken{kind: tokEOF}, nil
+	}
+
+	c := l.src[l.pos]
+	atLineStart := l.pos == 0 || l.src[l.pos-1] == '\n' || l.src[l.pos-1] == '\r'
+
+	// Multi-line text field, delimited by semicolons at the start of a line.
+	if c == ';' && atLineStart {
+		start := l.pos + 1
+		end := strings.Index(l.src[start:], "\n;")
+		if end == -1 {
+			return cifToken{}, fmt.Errorf("line %d: unterminated text field", l.line)
+		}
+		text := l.src[start : start+end]
+		l.line += strings.Count(text, "\n") + 1
+		l.pos = start + end + 2
+		return cifToken{kind: tokValue, text: strings.TrimSpace(text), quoted: true}, nil
+	}
+
+	// Quoted value, which only ends at a matching quote followed by whitespace.
+	if c == '\'' || c == '"' {
+		start := l.pos + 1
+		for i := start; i < len(l.src); i++ {
+			if l.src[i] == '\n' {
+				break
+			}
+			if l.src[i] == c && (i+1 == len(l.src) || isCIFSpace(l.src[i+1])) {
+				l.pos = i + 1
+				return cifToken{kind: tokValue, text: l.src[start:i], quoted: true}, nil
+			}
+		}
+		return cifToken{}, fmt.Errorf("line %d: unterminated quoted value", l.line)
+	}
+
+	start := l.pos
+	for l.pos < len(l.src) && !isCIFSpace(l.src[l.pos]) {
+		l.pos++
+	}
+	word := l.src[start:l.pos]
+
+	switch {
+	case word[0] == '_':
+		return cifToken{kind: tokTag, text: word}, nil
+	case hasPrefixFold(word, "data_"):
+		return cifToken{kind: tokData, text: word[5:]}, nil
+	case strings.EqualFold(word, "loop_"):
+		return cifToken{kind: tokLoop}, nil
+	case hasPrefixFold(word, "save_"):
+		return cifToken{kind: tokSave, text: word[5:]}, nil
+	case strings.EqualFold(word, "global_"), strings.EqualFold(word, "stop_"):
+		return cifToken{}, fmt.Errorf("line %d: unsupported %s", l.line, word)
+	}
+
+	return cifToken{kind: tokValue, text: word}, nil
+}
+
+func isCIFSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func hasPrefixFold(s string, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
@@ -0,0 +1,124 @@
+package pdb
+
+import (
+	"testing"
+)
+
+func TestParseCIF(t *testing.T) {
+	raw := []byte(`data_TEST
+# comment
+_struct.title 'It''s quoted'
+_exptl.method  "X-RAY DIFFRACTION"
+_struct.pdbx_descriptor
+;Multi-line
+text field
+;
+_refine.ls_d_res_high ?
+loop_
+_atom_type.symbol
+_atom_type.note
+C  .
+N  'a note'
+`)
+
+	cif, err := ParseCIF(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := cif.Block("test")
+	if block == nil {
+		t.Fatalf("expected block TEST")
+	}
+
+	if v, _ := block.Value("_struct.title"); v != "It''s quoted" {
+		t.Errorf("expected quoted value, got %s", v)
+	}
+
+	if v, _ := block.Value("_EXPTL.METHOD"); v != "X-RAY DIFFRACTION" {
+		t.Errorf("expected X-RAY DIFFRACTION, got %s", v)
+	}
+
+	if v, _ := block.Value("_struct.pdbx_descriptor"); v != "Multi-line\ntext field" {
+		t.Errorf("unexpected text field %q", v)
+	}
+
+	if _, ok := block.Value("_refine.ls_d_res_high"); ok {
+		t.Errorf("expected unknown value to be missing")
+	}
+
+	types := block.Category("atom_type")
+	if types.Len() != 2 || types.Get("symbol", 1) != "N" || types.Get("note", 1) != "a note" || types.Get("note", 0) != "" {
+		t.Errorf("unexpected loop values")
+	}
+}
+
+func TestCIFStructure(t *testing.T) {
+	rawPDB, err := LoadTestFile("./testdata/1mso.pdb")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+
+	rawCIF, err := LoadTestFile("./testdata/1mso.cif")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+
+	fromPDB, err := NewPDBFromRaw(rawPDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fromCIF, err := NewPDBFromCIF(rawCIF)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fromCIF.TotalLength != fromPDB.TotalLength {
+		t.Errorf("expected %d residues, got %d", fromPDB.TotalLength, fromCIF.TotalLength)
+	}
+
+	if len(fromCIF.Atoms) != len(fromPDB.Atoms) || len(fromCIF.HetAtoms) != len(fromPDB.HetAtoms) {
+		t.Errorf("expected %d atoms and %d hetatms, got %d and %d",
+			len(fromPDB.Atoms), len(fromPDB.HetAtoms), len(fromCIF.Atoms), len(fromCIF.HetAtoms))
+	}
+
+	for chain, residues := range fromPDB.Chains {
+		for pos, res := range residues {
+			cifRes, ok := fromCIF.Chains[chain][pos]
+			if !ok || cifRes.Name != res.Name || len(cifRes.Atoms) != len(res.Atoms) {
+				t.Errorf("residue mismatch at %s-%d", chain, pos)
+			}
+		}
+	}
+
+	atom := fromCIF.Chains["B"][1].Atoms[1]
+	if atom.Name != "CA" || atom.X != -20.267 {
+		t.Errorf("unexpected atom %s at %f", atom.Name, atom.X)
+	}
+
+	err = fromPDB.ExtractSeqRes(rawPDB)
+	if err != nil {
+		t.Error(err)
+	}
+
+	for chain, seqRes := range fromPDB.SeqRes {
+		if len(fromCIF.SeqRes[chain]) != len(seqRes) {
+			t.Fatalf("chain %s: expected SEQRES length %d, got %d", chain, len(seqRes), len(fromCIF.SeqRes[chain]))
+		}
+		for i, res := range seqRes {
+			if fromCIF.SeqRes[chain][i].Name != res.Name {
+				t.Errorf("chain %s: SEQRES mismatch at %d", chain, i)
+			}
+		}
+	}
+
+	err = fromCIF.ExtractCIFData(rawCIF)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if fromCIF.Method != "X-RAY DIFFRACTION" || fromCIF.Resolution != 1.0 {
+		t.Errorf("unexpected CIF metadata %s %f", fromCIF.Method, fromCIF.Resolution)
+	}
+}
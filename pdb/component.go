@@ -0,0 +1,156 @@
+package pdb
+
+import (
+	"strings"
+)
+
+// PolymerType is the kind of polymer a residue or chain belongs to.
+type PolymerType int
+
+const (
+	PolymerNone    PolymerType = iota // ligands, water and unknown components
+	PolymerProtein                    // polypeptide (L-amino acids)
+	PolymerDNA                        // polydeoxyribonucleotide
+	PolymerRNA                        // polyribonucleotide
+)
+
+func (t PolymerType) String() string {
+	switch t {
+	case PolymerProtein:
+		return "protein"
+	case PolymerDNA:
+		return "DNA"
+	case PolymerRNA:
+		return "RNA"
+	}
+	return "non-polymer"
+}
+
+// Component is a chemical component that can be part of a polymer chain.
+type Component struct {
+	ID     string      `json:"id"`     // component code as in the ATOM and HETATM records, i.e. MSE
+	Parent string      `json:"parent"` // standard parent component, i.e. MET, the same ID for standard ones
+	Letter string      `json:"letter"` // one letter code of the parent
+	Type   PolymerType `json:"type"`
+}
+
+// Standard returns true if the component is one of the standard amino acids or nucleotides.
+func (c *Component) Standard() bool {
+	return c.ID == c.Parent
+}
+
+// components are the standard residues plus the most common modified ones in the PDB,
+// with the parents from the wwPDB Chemical Component Dictionary.
+var components = map[string]*Component{}
+
+func init() {
+	for _, res := range residueNames {
+		code := strings.ToUpper(res[1])
+		components[code] = &Component{ID: code, Parent: code, Letter: res[2], Type: PolymerProtein}
+	}
+
+	modified := map[string]string{
+		"MSE": "MET", "FME": "MET", "CXM": "MET",
+		"SEP": "SER", "SAC": "SER", "OAS": "SER",
+		"TPO": "THR",
+		"PTR": "TYR", "TYS": "TYR", "TYI": "TYR",
+		"HYP": "PRO",
+		"MLY": "LYS", "M3L": "LYS", "MLZ": "LYS", "ALY": "LYS", "KCX": "LYS", "LLP": "LYS",
+		"CSO": "CYS", "CSD": "CYS", "CME": "CYS", "CSX": "CYS", "OCS": "CYS", "CAS": "CYS", "SMC": "CYS", "CSS": "CYS",
+		"PCA": "GLN",
+		"CGU": "GLU",
+		"MHS": "HIS", "HIC": "HIS", "NEP": "HIS",
+		"AIB": "ALA", "ABA": "ALA", "DAL": "ALA",
+		"NLE": "LEU", "MLE": "LEU",
+		"AGM": "ARG", "ARO": "ARG",
+		"IAS": "ASP", "BHD": "ASP",
+		"TRO": "TRP", "HTR": "TRP",
+		"MVA": "VAL",
+		"SAR": "GLY", "GL3": "GLY",
+		"PHI": "PHE",
+	}
+	for code, parent := range modified {
+		components[code] = &Component{ID: code, Parent: parent, Letter: components[parent].Letter, Type: PolymerProtein}
+	}
+
+	for _, n := range []string{"A", "C", "G", "U", "I"} {
+		components[n] = &Component{ID: n, Parent: n, Letter: n, Type: PolymerRNA}
+	}
+	for _, n := range []string{"A", "C", "G", "T", "U", "I"} {
+		code := "D" + n
+		components[code] = &Component{ID: code, Parent: code, Letter: n, Type: PolymerDNA}
+	}
+	modifiedNucleotides := map[string]string{
+		"PSU": "U", "H2U": "U", "5MU": "U", "4SU": "U", "OMU": "U", "5BU": "U",
+		"5MC": "C", "OMC": "C", "CBR": "C",
+		"7MG": "G", "2MG": "G", "M2G": "G", "OMG": "G", "1MG": "G", "YG": "G",
+		"1MA": "A", "MA6": "A", "6MA": "A",
+		"5CM": "DC", "5IC": "DC", "8OG": "DG", "6OG": "DG", "BRU": "DU", "5IU": "DU",
+	}
+	for code, parent := range modifiedNucleotides {
+		p := components[parent]
+		components[code] = &Component{ID: code, Parent: parent, Letter: p.Letter, Type: p.Type}
+	}
+}
+
+// LookupComponent returns the polymer component with the given code, case-insensitive.
+func LookupComponent(code string) (*Component, bool) {
+	c, ok := components[strings.ToUpper(strings.TrimSpace(code))]
+	return c, ok
+}
+
+// Component returns the polymer component of the residue, or nil if unknown.
+func (r *Residue) Component() *Component {
+	c, _ := LookupComponent(r.code())
+	return c
+}
+
+// newResidueFromCode constructs a residue from a component code as found in the coordinates and sequence records,
+// where A, C, G and U are nucleotides instead of one letter amino acid codes.
+func newResidueFromCode(chain string, pos int64, code string) *Residue {
+	c, ok := LookupComponent(code)
+	if !ok || c.Type == PolymerProtein {
+		return NewResidue(chain, pos, code)
+	}
+
+	code = strings.ToUpper(code)
+	return &Residue{
+		Chain:          chain,
+		StructPosition: pos,
+		Name:           code,
+		Name1:          c.Letter,
+		Name3:          code,
+	}
+}
+
+// componentLetter returns the one letter code of the parent of a component code, or X if unknown.
+func componentLetter(code string) string {
+	if c, ok := LookupComponent(code); ok {
+		return c.Letter
+	}
+	return "X"
+}
+
+// ChainType returns the polymer type of a chain, as the most common among its SEQRES residues
+// or the modeled ones if SEQRES is not available.
+func (pdb *PDB) ChainType(chain string) PolymerType {
+	residues := pdb.SeqRes[chain]
+	if len(residues) == 0 {
+		residues = pdb.Residues[chain]
+	}
+
+	counts := make(map[PolymerType]int)
+	for _, res := range residues {
+		if c := res.Component(); c != nil {
+			counts[c.Type]++
+		}
+	}
+
+	best := PolymerNone
+	for _, t := range []PolymerType{PolymerProtein, PolymerDNA, PolymerRNA} {
+		if counts[t] > counts[best] {
+			best = t
+		}
+	}
+	return best
+}
@@ -0,0 +1,157 @@
+package pdb
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestLookupComponent(t *testing.T) {
+	for _, c := range []struct {
+		code   string
+		parent string
+		letter string
+		t      PolymerType
+	}{
+		{"ALA", "ALA", "A", PolymerProtein},
+		{"mse", "MET", "M", PolymerProtein},
+		{"SEP", "SER", "S", PolymerProtein},
+		{"DG", "DG", "G", PolymerDNA},
+		{"A", "A", "A", PolymerRNA},
+		{"PSU", "U", "U", PolymerRNA},
+		{"5CM", "DC", "C", PolymerDNA},
+	} {
+		comp, ok := LookupComponent(c.code)
+		if !ok || comp.Parent != c.parent || comp.Letter != c.letter || comp.Type != c.t {
+			t.Errorf("unexpected component for %s: %+v", c.code, comp)
+		}
+	}
+	if _, ok := LookupComponent("HOH"); ok {
+		t.Errorf("HOH is not a polymer component")
+	}
+
+	if name, abbrv3, abbrv1 := AminoacidNames("MSE"); name != "Methionine" || abbrv3 != "Met" || abbrv1 != "M" {
+		t.Errorf("unexpected names for MSE: %s %s %s", name, abbrv3, abbrv1)
+	}
+}
+
+func TestModifiedResidues(t *testing.T) {
+	raw, err := LoadTestFile("./testdata/1mso.pdb")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+
+	// LEU B 6 as a selenomethionine in HETATM records
+	r := regexp.MustCompile("(?m)^ATOM  (.{11})LEU B   6")
+	edited := r.ReplaceAllString(string(raw), "HETATM${1}MSE B   6")
+
+	pdb, err := NewPDBFromRaw([]byte(edited))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := pdb.ResidueAt("B", 6, "")
+	if res == nil || res.Name1 != "M" || len(res.Atoms) == 0 || res.Component().ID != "MSE" {
+		t.Fatalf("modified residue not in chain: %+v", res)
+	}
+	if pdb.Residues["B"][5] != res || pdb.Residues["B"][4].StructPosition != 5 || pdb.Residues["B"][6].StructPosition != 7 {
+		t.Errorf("modified residue out of order")
+	}
+	if len(pdb.Residues["B"]) != 30 {
+		t.Errorf("expected 30 residues in chain B, got %d", len(pdb.Residues["B"]))
+	}
+	for _, het := range pdb.Hets {
+		if het.Name == "MSE" {
+			t.Errorf("modified residue listed as het group")
+		}
+	}
+	if pdb.ChainType("B") != PolymerProtein {
+		t.Errorf("expected protein chain, got %s", pdb.ChainType("B"))
+	}
+
+	// Modified residue atoms are ATOM records of the chain for atom lists and indexes, but written back as HETATM
+	ca := res.Atom("CA")
+	if !ca.Het || ca.HetGroup() != nil || len(pdb.Atoms) != len(pdb.Models[0].Atoms) {
+		t.Errorf("unexpected modified residue atom %+v", ca)
+	}
+	for _, a := range pdb.HetAtoms {
+		if a.Parent() != nil {
+			t.Errorf("modified residue atom %s listed in HETATM records", a.Name)
+		}
+	}
+	var inAtoms bool
+	for i, a := range pdb.Atoms {
+		if a == ca {
+			inAtoms = pdb.Atoms[i-1].Number < ca.Number && ca.Number < pdb.Atoms[i+1].Number
+		}
+	}
+	if !inAtoms {
+		t.Errorf("expected modified residue atoms in ATOM records, in file order")
+	}
+	if near := pdb.AtomIndex().WithinAtom(ca, 0.1); len(near) != 1 || near[0] != ca {
+		t.Errorf("expected modified residue atoms in the atom index")
+	}
+	if near := pdb.HetIndex().WithinAtom(ca, 0.1); len(near) != 0 {
+		t.Errorf("expected modified residue atoms out of the het index")
+	}
+
+	var b strings.Builder
+	if err := pdb.WritePDB(&b); err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile("(?m)^HETATM.{11}MSE B   6").MatchString(b.String()) {
+		t.Errorf("expected modified residue written as HETATM")
+	}
+	if strings.Count(b.String(), "\nTER") != 4 {
+		t.Errorf("expected no chain termination before the modified residue")
+	}
+	written, err := NewPDBFromRaw([]byte(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written.Residues["B"]) != 30 || written.ResidueAt("B", 6, "").Component().ID != "MSE" {
+		t.Errorf("modified residue not read back in its chain")
+	}
+}
+
+func TestNucleicAcids(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("SEQRES   1 X    3   DA  DG 5CM\n")
+	b.WriteString("SEQRES   1 Y    2    A   G\n")
+	n := 1
+	for _, r := range []struct {
+		name  string
+		chain string
+		num   int
+	}{{"DA", "X", 1}, {"DG", "X", 2}, {"A", "Y", 1}, {"G", "Y", 2}} {
+		for _, atom := range []string{"P", "C1'"} {
+			b.WriteString(fmt.Sprintf("ATOM  %5d  %-3s %3s %s%4d    %8.3f%8.3f%8.3f  1.00 20.00           %s  \n",
+				n, atom, r.name, r.chain, r.num, float64(n), 0.0, 0.0, atom[:1]))
+			n++
+		}
+	}
+	b.WriteString(fmt.Sprintf("HETATM%5d  P   5CM X   3    %8.3f%8.3f%8.3f  1.00 20.00           P  \n", n, float64(n), 0.0, 0.0))
+
+	raw := []byte(b.String())
+	pdb, err := NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pdb.ExtractSeqRes(raw); err != nil {
+		t.Fatal(err)
+	}
+
+	if pdb.ChainType("X") != PolymerDNA || pdb.ChainType("Y") != PolymerRNA {
+		t.Errorf("unexpected chain types %s and %s", pdb.ChainType("X"), pdb.ChainType("Y"))
+	}
+	if s := sequence(pdb.Residues["X"]); s != "AGC" {
+		t.Errorf("unexpected DNA sequence %s", s)
+	}
+	if s := sequence(pdb.SeqRes["X"]); s != "AGC" {
+		t.Errorf("unexpected DNA SEQRES sequence %s", s)
+	}
+	if s := sequence(pdb.Residues["Y"]); s != "AG" || pdb.Residues["Y"][0].Name != "A" {
+		t.Errorf("unexpected RNA sequence %s", s)
+	}
+}
@@ -0,0 +1,321 @@
+package pdb
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Cell is the crystallographic unit cell of the structure.
+type Cell struct {
+	A          float64  `json:"a"` // lengths in A
+	B          float64  `json:"b"`
+	C          float64  `json:"c"`
+	Alpha      float64  `json:"alpha"` // angles in degrees
+	Beta       float64  `json:"beta"`
+	Gamma      float64  `json:"gamma"`
+	SpaceGroup string   `json:"spaceGroup"` // Hermann-Mauguin symbol, i.e. P 21 21 21
+	Z          int64    `json:"z"`          // polymeric chains in the unit cell
+	Operators  []string `json:"operators"`  // symmetry operators in x,y,z notation, if listed in the CIF file
+}
+
+// ExtractCell parses the unit cell from the PDB CRYST1 record. Structures without a meaningful cell,
+// such as NMR and EM entries with the unitary cell, are left without one.
+func (pdb *PDB) ExtractCell(rawPDB []byte) error {
+	r, _ := regexp.Compile("(?m)^CRYST1.*$")
+	record := r.FindString(string(rawPDB))
+	pdb.Cell = nil
+	if record == "" {
+		return nil
+	}
+
+	// https://www.wwpdb.org/documentation/file-format-content/format33/sect8.html#CRYST1
+	record = fmt.Sprintf("%-70s", record)
+	parse := func(start int, end int) (float64, error) {
+		return strconv.ParseFloat(strings.TrimSpace(record[start:end]), 64)
+	}
+	var cell Cell
+	var err error
+	for i, f := range []*float64{&cell.A, &cell.B, &cell.C, &cell.Alpha, &cell.Beta, &cell.Gamma} {
+		bounds := [][2]int{{6, 15}, {15, 24}, {24, 33}, {33, 40}, {40, 47}, {47, 54}}[i]
+		*f, err = parse(bounds[0], bounds[1])
+		if err != nil {
+			return fmt.Errorf("invalid CRYST1 record: %s", record)
+		}
+	}
+	cell.SpaceGroup = strings.TrimSpace(record[55:66])
+	cell.Z, _ = strconv.ParseInt(strings.TrimSpace(record[66:70]), 10, 64)
+
+	if cell.A > 1 {
+		pdb.Cell = &cell
+	}
+	return nil
+}
+
+// ExtractCIFCell parses the unit cell from the CIF _cell and _symmetry (or _space_group) categories,
+// and the symmetry operators if listed.
+func (pdb *PDB) ExtractCIFCell(rawCIF []byte) error {
+	block, err := firstCIFBlock(rawCIF)
+	if err != nil {
+		return err
+	}
+
+	return pdb.extractCIFCell(block)
+}
+
+func (pdb *PDB) extractCIFCell(block *DataBlock) error {
+	pdb.Cell = nil
+	if _, ok := block.Value("_cell.length_a"); !ok {
+		return nil
+	}
+
+	var cell Cell
+	tags := []string{"length_a", "length_b", "length_c", "angle_alpha", "angle_beta", "angle_gamma"}
+	for i, f := range []*float64{&cell.A, &cell.B, &cell.C, &cell.Alpha, &cell.Beta, &cell.Gamma} {
+		v, _ := block.Value("_cell." + tags[i])
+		var err error
+		*f, err = strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("invalid cell %s: %s", tags[i], v)
+		}
+	}
+	z, _ := block.Value("_cell.Z_PDB")
+	cell.Z, _ = strconv.ParseInt(z, 10, 64)
+
+	for _, tag := range []string{"_symmetry.space_group_name_H-M", "_space_group.name_H-M_alt"} {
+		if v, ok := block.Value(tag); ok && cell.SpaceGroup == "" {
+			cell.SpaceGroup = v
+		}
+	}
+	for _, tag := range []string{"_space_group_symop.operation_xyz", "_symmetry_equiv.pos_as_xyz"} {
+		if ops := block.Values(tag); len(ops) > 0 && len(cell.Operators) == 0 {
+			cell.Operators = ops
+		}
+	}
+
+	if cell.A > 1 {
+		pdb.Cell = &cell
+	}
+	return nil
+}
+
+// SymmetryOperators returns the symmetry operators of the cell, as listed in the CIF file
+// or else from its space group.
+func (c *Cell) SymmetryOperators() ([]*SymmetryOperator, error) {
+	xyz := c.Operators
+	if len(xyz) == 0 {
+		var err error
+		hexagonal := math.Abs(c.Gamma-120) < 0.01 && math.Abs(c.Alpha-90) < 0.01
+		xyz, err = SpaceGroupOperators(c.SpaceGroup, hexagonal)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var ops []*SymmetryOperator
+	for _, s := range xyz {
+		op, err := ParseSymmetryOperator(s)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// Orthogonalization returns the matrix from fractional to Cartesian coordinates, with the a axis along x
+// and the b axis in the xy plane, as in the PDB convention.
+func (c *Cell) Orthogonalization() [3][3]float64 {
+	rad := math.Pi / 180
+	cosA, cosB, cosG := math.Cos(c.Alpha*rad), math.Cos(c.Beta*rad), math.Cos(c.Gamma*rad)
+	sinG := math.Sin(c.Gamma * rad)
+	volume := math.Sqrt(1 - cosA*cosA - cosB*cosB - cosG*cosG + 2*cosA*cosB*cosG)
+
+	return [3][3]float64{
+		{c.A, c.B * cosG, c.C * cosB},
+		{0, c.B * sinG, c.C * (cosA - cosB*cosG) / sinG},
+		{0, 0, c.C * volume / sinG},
+	}
+}
+
+// Fractionalization returns the matrix from Cartesian to fractional coordinates, the inverse of Orthogonalization.
+func (c *Cell) Fractionalization() [3][3]float64 {
+	o := c.Orthogonalization()
+	return [3][3]float64{
+		{1 / o[0][0], -o[0][1] / (o[0][0] * o[1][1]), (o[0][1]*o[1][2] - o[0][2]*o[1][1]) / (o[0][0] * o[1][1] * o[2][2])},
+		{0, 1 / o[1][1], -o[1][2] / (o[1][1] * o[2][2])},
+		{0, 0, 1 / o[2][2]},
+	}
+}
+
+// Cartesian returns the operator with the given lattice translation as a transformation in Cartesian coordinates.
+func (c *Cell) Cartesian(op *SymmetryOperator, translation [3]float64) *Transform {
+	o, f := c.Orthogonalization(), c.Fractionalization()
+	t := &Transform{}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			// O * R * F
+			for k := 0; k < 3; k++ {
+				for l := 0; l < 3; l++ {
+					t.Rotation[i][j] += o[i][k] * op.Rotation[k][l] * f[l][j]
+				}
+			}
+			t.Translation[i] += o[i][j] * (op.Translation[j] + translation[j])
+		}
+	}
+	return t
+}
+
+// SymmetryMate is a copy of the asymmetric unit generated by a crystal symmetry operator and a lattice translation.
+type SymmetryMate struct {
+	Operator  string     `json:"operator"` // operator number and lattice translation, i.e. 2_565, or 2_+5_-1_0 beyond 4 cells
+	Transform *Transform `json:"transform"`
+	Atoms     []*Atom    `json:"-"` // transformed copies of the ATOM records
+	HetAtoms  []*Atom    `json:"-"` // transformed copies of the HETATM records
+}
+
+// SymmetryMates generates the copies of the asymmetric unit (first model) with any atom within the given radius
+// of an atom of the asymmetric unit, excluding the asymmetric unit itself. Copied atoms are not bound to residues,
+// but keep the chain, residue number and insertion code of the originals.
+func (pdb *PDB) SymmetryMates(radius float64) ([]*SymmetryMate, error) {
+	if pdb.Cell == nil {
+		return nil, errors.New("structure has no unit cell")
+	}
+	if len(pdb.Atoms) == 0 {
+		return nil, errors.New("empty atoms list")
+	}
+
+	ops, err := pdb.Cell.SymmetryOperators()
+	if err != nil {
+		return nil, err
+	}
+
+	// Bounding box of the asymmetric unit, to discard far copies before looking at atoms
+	all := append(append([]*Atom{}, pdb.Atoms...), pdb.HetAtoms...)
+	min, max := boundingBox(all)
+	center := [3]float64{(min[0] + max[0]) / 2, (min[1] + max[1]) / 2, (min[2] + max[2]) / 2}
+	extent := pointsDistance(min[0], min[1], min[2], max[0], max[1], max[2]) / 2
+
+	// Fractional center of the asymmetric unit, to bring each copy to the neighboring cells
+	f := pdb.Cell.Fractionalization()
+	var fc [3]float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			fc[i] += f[i][j] * center[j]
+		}
+	}
+
+	idx := NewIndex(all, defaultCellSize)
+	var mates []*SymmetryMate
+	for n, op := range ops {
+		// Lattice translation that brings the transformed center closest to the original one
+		var shift [3]float64
+		for i := 0; i < 3; i++ {
+			var v float64
+			for j := 0; j < 3; j++ {
+				v += op.Rotation[i][j] * fc[j]
+			}
+			shift[i] = math.Round(fc[i] - v - op.Translation[i])
+		}
+
+		reach := [3]int{}
+		lengths := [3]float64{pdb.Cell.A, pdb.Cell.B, pdb.Cell.C}
+		for i := range reach {
+			reach[i] = int(math.Ceil((2*extent+radius)/lengths[i])) + 1
+		}
+
+		for da := -reach[0]; da <= reach[0]; da++ {
+			for db := -reach[1]; db <= reach[1]; db++ {
+				for dc := -reach[2]; dc <= reach[2]; dc++ {
+					translation := [3]float64{shift[0] + float64(da), shift[1] + float64(db), shift[2] + float64(dc)}
+					t := pdb.Cell.Cartesian(op, translation)
+					if t.isIdentity() {
+						continue
+					}
+
+					x, y, z := t.Apply(center[0], center[1], center[2])
+					if pointsDistance(x, y, z, center[0], center[1], center[2]) > 2*extent+radius {
+						continue
+					}
+
+					mate := &SymmetryMate{
+						Operator:  operatorLabel(n+1, translation),
+						Transform: t,
+						Atoms:     copyTransformed(pdb.Atoms, t),
+						HetAtoms:  copyTransformed(pdb.HetAtoms, t),
+					}
+					if mate.near(idx, radius) {
+						mates = append(mates, mate)
+					}
+				}
+			}
+		}
+	}
+
+	return mates, nil
+}
+
+// operatorLabel returns the symmetry operator label in the n_klm notation of PDB and PyMOL, where 5 is no lattice
+// translation. Translations that do not fit in a single digit are written explicitly, i.e. 1_+5_-1_0.
+func operatorLabel(n int, translation [3]float64) string {
+	var t [3]int
+	explicit := false
+	for i := range t {
+		t[i] = int(math.Round(translation[i]))
+		if t[i] < -5 || t[i] > 4 {
+			explicit = true
+		}
+	}
+	if explicit {
+		label := fmt.Sprint(n)
+		for _, v := range t {
+			if v == 0 {
+				label += "_0"
+			} else {
+				label += fmt.Sprintf("_%+d", v)
+			}
+		}
+		return label
+	}
+	return fmt.Sprintf("%d_%d%d%d", n, 5+t[0], 5+t[1], 5+t[2])
+}
+
+// near reports whether any atom of the mate is within the radius of an atom in the index.
+func (m *SymmetryMate) near(idx *Index, radius float64) bool {
+	for _, atoms := range [][]*Atom{m.Atoms, m.HetAtoms} {
+		for _, a := range atoms {
+			if len(idx.WithinAtom(a, radius)) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func copyTransformed(atoms []*Atom, t *Transform) []*Atom {
+	copies := make([]*Atom, len(atoms))
+	for i, a := range atoms {
+		c := *a
+		c.residue, c.het, c.owner = nil, nil, nil
+		c.X, c.Y, c.Z = t.Apply(a.X, a.Y, a.Z)
+		copies[i] = &c
+	}
+	return copies
+}
+
+func boundingBox(atoms []*Atom) (min [3]float64, max [3]float64) {
+	for i, a := range atoms {
+		for j, v := range [3]float64{a.X, a.Y, a.Z} {
+			if i == 0 || v < min[j] {
+				min[j] = v
+			}
+			if i == 0 || v > max[j] {
+				max[j] = v
+			}
+		}
+	}
+	return
+}
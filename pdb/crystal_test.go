@@ -0,0 +1,120 @@
+package pdb
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSymmetryMates(t *testing.T) {
+	raw, err := LoadTestFile("./testdata/1mso.pdb")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+	pdb, err := NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pdb.ExtractCell(raw); err != nil {
+		t.Fatal(err)
+	}
+
+	rawCIF, err := LoadTestFile("./testdata/1mso.cif")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+	fromCIF, err := NewPDBFromCIF(rawCIF)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []*Cell{pdb.Cell, fromCIF.Cell} {
+		if c == nil {
+			t.Fatal("expected unit cell")
+		}
+		if c.A != 81.286 || c.C != 33.714 || c.Gamma != 120 || c.SpaceGroup != "H 3" || c.Z != 18 {
+			t.Errorf("unexpected cell %+v", c)
+		}
+		ops, err := c.SymmetryOperators()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ops) != 9 {
+			t.Errorf("expected 9 operators for H 3, got %d", len(ops))
+		}
+	}
+
+	// Fractional and Cartesian conversions are inverse
+	o, f := pdb.Cell.Orthogonalization(), pdb.Cell.Fractionalization()
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			var v float64
+			for k := 0; k < 3; k++ {
+				v += o[i][k] * f[k][j]
+			}
+			if (i == j && math.Abs(v-1) > 1e-9) || (i != j && math.Abs(v) > 1e-9) {
+				t.Errorf("orthogonalization and fractionalization are not inverse")
+			}
+		}
+	}
+
+	mates, err := pdb.SymmetryMates(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pdb.ExtractAssemblies(raw); err != nil {
+		t.Fatal(err)
+	}
+
+	// The hexamer of the biological assembly is generated by the crystallographic threefold axis
+	found := make(map[string]bool)
+	for _, m := range mates {
+		found[m.Operator] = true
+		if m.Operator == "1_555" || len(m.Atoms) != len(pdb.Atoms) || len(m.HetAtoms) != len(pdb.HetAtoms) {
+			t.Errorf("unexpected mate %s with %d atoms", m.Operator, len(m.Atoms))
+		}
+		if m.Atoms[0].Parent() != nil {
+			t.Errorf("expected mate atoms not bound to residues")
+		}
+	}
+	for _, op := range pdb.Assemblies[0].Generators[0].Operations[1:] {
+		code := op.ID + "_555"
+		if !found[code] {
+			t.Errorf("expected symmetry mate %s", code)
+		}
+		for _, m := range mates {
+			if m.Operator != code {
+				continue
+			}
+			for i := 0; i < 3; i++ {
+				for j := 0; j < 3; j++ {
+					if math.Abs(m.Transform.Rotation[i][j]-op.Rotation[i][j]) > 1e-4 {
+						t.Errorf("mate %s rotation differs from assembly operation", code)
+					}
+				}
+				if math.Abs(m.Transform.Translation[i]-op.Translation[i]) > 1e-3 {
+					t.Errorf("mate %s translation differs from assembly operation", code)
+				}
+			}
+		}
+	}
+
+	if _, err := (&PDB{Atoms: pdb.Atoms}).SymmetryMates(5); err == nil {
+		t.Errorf("expected error without unit cell")
+	}
+}
+
+func TestOperatorLabel(t *testing.T) {
+	for _, c := range []struct {
+		translation [3]float64
+		label       string
+	}{
+		{[3]float64{0, 0, 0}, "2_555"},
+		{[3]float64{-5, 1, 4}, "2_069"},
+		{[3]float64{5, -1, 0}, "2_+5_-1_0"},
+		{[3]float64{0, -6, 0}, "2_0_-6_0"},
+	} {
+		if label := operatorLabel(2, c.translation); label != c.label {
+			t.Errorf("expected %s for %v, got %s", c.label, c.translation, label)
+		}
+	}
+}
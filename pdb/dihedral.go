@@ -0,0 +1,186 @@
+package pdb
+
+import (
+	"math"
+	"strings"
+)
+
+// peptideBondMaxLength is the maximum C-N distance in Angstroms between consecutive residues
+// to be considered bonded, beyond it there is a chain break.
+const peptideBondMaxLength = 2.0
+
+// chiAtoms are the atoms defining each side chain dihedral angle, from chi1 to chi4.
+var chiAtoms = map[string][][4]string{
+	"ARG": {{"N", "CA", "CB", "CG"}, {"CA", "CB", "CG", "CD"}, {"CB", "CG", "CD", "NE"}, {"CG", "CD", "NE", "CZ"}},
+	"ASN": {{"N", "CA", "CB", "CG"}, {"CA", "CB", "CG", "OD1"}},
+	"ASP": {{"N", "CA", "CB", "CG"}, {"CA", "CB", "CG", "OD1"}},
+	"CYS": {{"N", "CA", "CB", "SG"}},
+	"GLN": {{"N", "CA", "CB", "CG"}, {"CA", "CB", "CG", "CD"}, {"CB", "CG", "CD", "OE1"}},
+	"GLU": {{"N", "CA", "CB", "CG"}, {"CA", "CB", "CG", "CD"}, {"CB", "CG", "CD", "OE1"}},
+	"HIS": {{"N", "CA", "CB", "CG"}, {"CA", "CB", "CG", "ND1"}},
+	"ILE": {{"N", "CA", "CB", "CG1"}, {"CA", "CB", "CG1", "CD1"}},
+	"LEU": {{"N", "CA", "CB", "CG"}, {"CA", "CB", "CG", "CD1"}},
+	"LYS": {{"N", "CA", "CB", "CG"}, {"CA", "CB", "CG", "CD"}, {"CB", "CG", "CD", "CE"}, {"CG", "CD", "CE", "NZ"}},
+	"MET": {{"N", "CA", "CB", "CG"}, {"CA", "CB", "CG", "SD"}, {"CB", "CG", "SD", "CE"}},
+	"MSE": {{"N", "CA", "CB", "CG"}, {"CA", "CB", "CG", "SE"}, {"CB", "CG", "SE", "CE"}},
+	"PHE": {{"N", "CA", "CB", "CG"}, {"CA", "CB", "CG", "CD1"}},
+	"PRO": {{"N", "CA", "CB", "CG"}, {"CA", "CB", "CG", "CD"}},
+	"SER": {{"N", "CA", "CB", "OG"}},
+	"THR": {{"N", "CA", "CB", "OG1"}},
+	"TRP": {{"N", "CA", "CB", "CG"}, {"CA", "CB", "CG", "CD1"}},
+	"TYR": {{"N", "CA", "CB", "CG"}, {"CA", "CB", "CG", "CD1"}},
+	"VAL": {{"N", "CA", "CB", "CG1"}},
+}
+
+// Dihedral returns the dihedral angle in degrees, between -180 and 180, defined by four atoms.
+func Dihedral(a1 *Atom, a2 *Atom, a3 *Atom, a4 *Atom) float64 {
+	b1 := [3]float64{a2.X - a1.X, a2.Y - a1.Y, a2.Z - a1.Z}
+	b2 := [3]float64{a3.X - a2.X, a3.Y - a2.Y, a3.Z - a2.Z}
+	b3 := [3]float64{a4.X - a3.X, a4.Y - a3.Y, a4.Z - a3.Z}
+
+	n1, n2 := cross(b1, b2), cross(b2, b3)
+
+	return math.Atan2(math.Sqrt(dot(b2, b2))*dot(b1, n2), dot(n1, n2)) * 180 / math.Pi
+}
+
+func cross(a [3]float64, b [3]float64) [3]float64 {
+	return [3]float64{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+
+func dot(a [3]float64, b [3]float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+// Atom returns the first atom of the residue with the given name, or nil if missing.
+func (r *Residue) Atom(name string) *Atom {
+	for _, a := range r.Atoms {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// code returns the residue name as in the ATOM records, i.e. ARG or MSE.
+func (r *Residue) code() string {
+	if len(r.Atoms) == 0 {
+		return strings.ToUpper(r.Name3)
+	}
+	return strings.ToUpper(r.Atoms[0].Residue)
+}
+
+// bonded reports whether the residue is bonded to the next one in the chain.
+func bonded(r *Residue, next *Residue) bool {
+	if r == nil || next == nil {
+		return false
+	}
+	c, n := r.Atom("C"), next.Atom("N")
+	return c != nil && n != nil && AtomsDistance(c, n) <= peptideBondMaxLength
+}
+
+// dihedral returns the dihedral angle of the atoms, or NaN if any is missing.
+func dihedral(atoms ...*Atom) float64 {
+	for _, a := range atoms {
+		if a == nil {
+			return math.NaN()
+		}
+	}
+	return Dihedral(atoms[0], atoms[1], atoms[2], atoms[3])
+}
+
+// Phi returns the C(i-1)-N-CA-C dihedral angle in degrees, or NaN at the start of a chain, chain breaks or missing atoms.
+func (r *Residue) Phi() float64 {
+	if !bonded(r.prev, r) {
+		return math.NaN()
+	}
+	return dihedral(r.prev.Atom("C"), r.Atom("N"), r.Atom("CA"), r.Atom("C"))
+}
+
+// Psi returns the N-CA-C-N(i+1) dihedral angle in degrees, or NaN at the end of a chain, chain breaks or missing atoms.
+func (r *Residue) Psi() float64 {
+	if !bonded(r, r.next) {
+		return math.NaN()
+	}
+	return dihedral(r.Atom("N"), r.Atom("CA"), r.Atom("C"), r.next.Atom("N"))
+}
+
+// Omega returns the CA(i-1)-C(i-1)-N-CA dihedral angle in degrees of the peptide bond preceding the residue,
+// or NaN at the start of a chain, chain breaks or missing atoms. Values near 0 indicate a cis peptide bond.
+func (r *Residue) Omega() float64 {
+	if !bonded(r.prev, r) {
+		return math.NaN()
+	}
+	return dihedral(r.prev.Atom("CA"), r.prev.Atom("C"), r.Atom("N"), r.Atom("CA"))
+}
+
+// Chi returns the n-th (1 to 4) side chain dihedral angle in degrees, or NaN if not defined for the residue or missing atoms.
+func (r *Residue) Chi(n int) float64 {
+	chis := chiAtoms[r.code()]
+	if n < 1 || n > len(chis) {
+		return math.NaN()
+	}
+
+	names := chis[n-1]
+	return dihedral(r.Atom(names[0]), r.Atom(names[1]), r.Atom(names[2]), r.Atom(names[3]))
+}
+
+// RamachandranRegion is the classification of the backbone phi/psi angles of a residue.
+type RamachandranRegion string
+
+const (
+	RamachandranFavoured  RamachandranRegion = "favoured"
+	RamachandranAllowed   RamachandranRegion = "allowed"
+	RamachandranOutlier   RamachandranRegion = "outlier"
+	RamachandranUndefined RamachandranRegion = "" // phi or psi not defined
+)
+
+// ramachandranArea is a rectangular area of the Ramachandran plot, as phi and psi ranges in degrees.
+type ramachandranArea [4]float64
+
+func (a ramachandranArea) contains(phi float64, psi float64) bool {
+	return phi >= a[0] && phi <= a[1] && psi >= a[2] && psi <= a[3]
+}
+
+// ramachandranAreas are simplified favoured and allowed regions for general, glycine and proline residues,
+// approximating the contours of Lovell et al. 2003 (https://doi.org/10.1002/prot.10286) with rectangles.
+var ramachandranAreas = map[string][2][]ramachandranArea{
+	"general": {
+		{{-180, -45, 90, 180}, {-180, -45, -180, -170}, {-140, -30, -80, 10}},
+		{{-180, -20, -180, 180}, {30, 100, -20, 90}},
+	},
+	"GLY": {
+		{{-140, -40, -70, 10}, {40, 140, -10, 70}, {-180, -60, 150, 180}, {60, 180, -180, -150}, {-180, -60, -180, -150}, {60, 180, 150, 180}},
+		{{-180, -40, -180, 180}, {40, 180, -180, 180}},
+	},
+	"PRO": {
+		{{-90, -45, -60, -10}, {-90, -45, 110, 180}},
+		{{-110, -40, -70, 60}, {-110, -40, 90, 180}, {-110, -40, -180, -160}},
+	},
+}
+
+// Ramachandran classifies the residue phi/psi angles as favoured, allowed or outlier,
+// with separate regions for glycine, proline and the rest of the residues.
+func (r *Residue) Ramachandran() RamachandranRegion {
+	phi, psi := r.Phi(), r.Psi()
+	if math.IsNaN(phi) || math.IsNaN(psi) {
+		return RamachandranUndefined
+	}
+
+	areas, ok := ramachandranAreas[r.code()]
+	if !ok {
+		areas = ramachandranAreas["general"]
+	}
+
+	for _, a := range areas[0] {
+		if a.contains(phi, psi) {
+			return RamachandranFavoured
+		}
+	}
+	for _, a := range areas[1] {
+		if a.contains(phi, psi) {
+			return RamachandranAllowed
+		}
+	}
+
+	return RamachandranOutlier
+}
@@ -0,0 +1,62 @@
+package pdb
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDihedrals(t *testing.T) {
+	raw, err := LoadTestFile("./testdata/1mso.pdb")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+
+	pdb, err := NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, last := pdb.Chains["B"][1], pdb.Chains["B"][30]
+	if !math.IsNaN(first.Phi()) || !math.IsNaN(first.Omega()) || !math.IsNaN(last.Psi()) {
+		t.Errorf("expected undefined angles at chain ends")
+	}
+
+	// HELIX B 8-20
+	for pos := int64(10); pos <= 18; pos++ {
+		res := pdb.Chains["B"][pos]
+		phi, psi := res.Phi(), res.Psi()
+		if phi > -40 || phi < -90 || psi > -20 || psi < -65 {
+			t.Errorf("expected helical angles in B-%d, got %.1f %.1f", pos, phi, psi)
+		}
+		if math.Abs(res.Omega()) < 160 {
+			t.Errorf("expected trans peptide bond in B-%d, got %.1f", pos, res.Omega())
+		}
+		if r := res.Ramachandran(); r != RamachandranFavoured {
+			t.Errorf("expected favoured region in B-%d, got %s", pos, r)
+		}
+	}
+
+	var outliers int
+	for _, chain := range pdb.Residues {
+		for _, res := range chain {
+			if res.Ramachandran() == RamachandranOutlier {
+				outliers++
+			}
+		}
+	}
+	if outliers > 2 {
+		t.Errorf("expected few Ramachandran outliers, got %d", outliers)
+	}
+
+	// Chi angles
+	if chi := pdb.Chains["A"][2].Chi(1); math.IsNaN(chi) || !math.IsNaN(pdb.Chains["A"][2].Chi(3)) {
+		t.Errorf("expected chi1 and no chi3 for ILE A-2")
+	}
+	if !math.IsNaN(pdb.Chains["A"][1].Chi(1)) {
+		t.Errorf("expected no chi1 for GLY A-1")
+	}
+	if chi := pdb.Chains["A"][2].Chi(1); math.Abs(chi-Dihedral(
+		pdb.Chains["A"][2].Atom("N"), pdb.Chains["A"][2].Atom("CA"), pdb.Chains["A"][2].Atom("CB"), pdb.Chains["A"][2].Atom("CG1"))) > 1e-9 {
+		t.Errorf("unexpected chi1 %f", chi)
+	}
+}
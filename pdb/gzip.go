@@ -0,0 +1,47 @@
+package pdb
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// gunzip decompresses the raw bytes if gzipped, or returns them as they are otherwise.
+func gunzip(raw []byte) ([]byte, error) {
+	if !isGzip(raw) {
+		return raw, nil
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("gzip: %v", err)
+	}
+	raw, err = ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("gzip: %v", err)
+	}
+	return raw, nil
+}
+
+// gunzipReader returns a reader that decompresses r if gzipped, or reads it as it is otherwise.
+func gunzipReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(2)
+	if !isGzip(magic) {
+		return br, nil
+	}
+
+	gr, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, fmt.Errorf("gzip: %v", err)
+	}
+	return gr, nil
+}
+
+// isGzip reports whether the bytes start with the gzip magic number.
+func isGzip(raw []byte) bool {
+	return len(raw) >= 2 && raw[0] == 0x1f && raw[1] == 0x8b
+}
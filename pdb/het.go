@@ -0,0 +1,70 @@
+package pdb
+
+import "fmt"
+
+// HetGroup represents a single instance of a het group in the structure, such as one of the copies of a ligand
+// or a single water molecule, identified by its name, chain, residue number and insertion code.
+type HetGroup struct {
+	Name          string    `json:"name"`
+	Chain         string    `json:"chain"`
+	Number        int64     `json:"number"`
+	InsertionCode string    `json:"insertionCode"`
+	Atoms         []*Atom   `json:"-"`
+	ChemComp      *ChemComp `json:"chemComp"` // chemical component, only if set with SetChemComps
+}
+
+// ID returns the het group instance identifier, i.e. ATP A 501 or ATP A 501B.
+func (h *HetGroup) ID() string {
+	return fmt.Sprintf("%s %s %d%s", h.Name, h.Chain, h.Number, h.InsertionCode)
+}
+
+// IsWater reports whether the het group is a water molecule.
+func (h *HetGroup) IsWater() bool {
+	return len(h.Atoms) > 0 && Water()(h.Atoms[0])
+}
+
+// IsArtifact reports whether the het group is a common crystallization or buffer additive, such as SO4 or GOL.
+func (h *HetGroup) IsArtifact() bool {
+	if h.ChemComp != nil {
+		return h.ChemComp.Artifact
+	}
+	return IsArtifact(h.Name)
+}
+
+// FullName returns the chemical component name of the het group, i.e. ZINC ION, or its code if not available.
+func (h *HetGroup) FullName() string {
+	if h.ChemComp != nil && h.ChemComp.Name != "" {
+		return h.ChemComp.Name
+	}
+	return h.Name
+}
+
+// HetGroup returns the het group instance the atom belongs to, or nil for ATOM records.
+func (a *Atom) HetGroup() *HetGroup {
+	return a.het
+}
+
+// extractHets groups the model HETATM records into het group instances, in file order.
+func (m *Model) extractHets() {
+	type hetKey struct {
+		residueKey
+		name string
+	}
+
+	m.Hets = nil
+	seen := make(map[hetKey]*HetGroup)
+	for _, atom := range m.HetAtoms {
+		if atom.residue != nil {
+			continue // modified residue in a polymer chain
+		}
+		key := hetKey{atomResidueKey(atom), atom.Residue}
+		het, ok := seen[key]
+		if !ok {
+			het = &HetGroup{Name: atom.Residue, Chain: atom.Chain, Number: atom.ResidueNumber, InsertionCode: atom.InsertionCode}
+			seen[key] = het
+			m.Hets = append(m.Hets, het)
+		}
+		het.Atoms = append(het.Atoms, atom)
+		atom.het = het
+	}
+}
@@ -0,0 +1,55 @@
+package pdb
+
+import (
+	"testing"
+)
+
+func TestHets(t *testing.T) {
+	raw, err := LoadTestFile("./testdata/1mso.pdb")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+
+	pdb, err := NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2 zinc ions and 226 waters, alternate locations collapsed into a single instance
+	if len(pdb.Hets) != 228 {
+		t.Errorf("expected 228 het groups, got %d", len(pdb.Hets))
+	}
+
+	var zinc, water int
+	atoms := 0
+	for _, het := range pdb.Hets {
+		if het.IsWater() {
+			water++
+		}
+		if het.Name == "ZN" {
+			zinc++
+			if het.Chain != "D" || len(het.Atoms) != 1 {
+				t.Errorf("unexpected zinc %s with %d atoms", het.ID(), len(het.Atoms))
+			}
+		}
+		for _, a := range het.Atoms {
+			if a.HetGroup() != het {
+				t.Errorf("atom %d of %s points to another het group", a.Number, het.ID())
+			}
+		}
+		atoms += len(het.Atoms)
+	}
+	if zinc != 2 || water != 226 {
+		t.Errorf("expected 2 zinc ions and 226 waters, got %d and %d", zinc, water)
+	}
+	if atoms != len(pdb.HetAtoms) {
+		t.Errorf("expected %d het atoms, got %d", len(pdb.HetAtoms), atoms)
+	}
+
+	if id := pdb.Hets[0].ID(); id != "ZN D 501" {
+		t.Errorf("expected first het group ZN D 501, got %s", id)
+	}
+	if pdb.Atoms[0].HetGroup() != nil {
+		t.Errorf("expected no het group for ATOM records")
+	}
+}
@@ -1,6 +1,32 @@
 package pdb
 
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tikz/bio/align"
+	"github.com/tikz/bio/uniprot"
+)
+
+// MappingMode defines how the observed residues are mapped to SEQRES positions.
+type MappingMode int
+
+const (
+	MappingOffset    MappingMode = iota // slide the observed residues over SEQRES without gaps, by residue number
+	MappingAlignment                    // align the observed residues to SEQRES, allowing internal deletions and numbering jumps
+)
+
+// Minimum identity and fraction of the SEQRES aligned for a chain to be mapped to a UniProt sequence.
+const (
+	minUniProtIdentity = 0.9
+	minUniProtCoverage = 0.5
+)
+
 func (pdb *PDB) makeMappings() {
+	defer pdb.syncModelMappings()
+
 	pdb.ChainStartResNumber = make(map[string]int64)
 	pdb.ChainEndResNumber = make(map[string]int64)
 	for c := range pdb.Chains {
@@ -8,24 +34,39 @@ func (pdb *PDB) makeMappings() {
 		pdb.ChainEndResNumber[c] = pdb.maxChainPos(c)
 	}
 
-	pdb.calculateChainsOffset()
+	if pdb.MappingMode == MappingAlignment {
+		pdb.alignChains()
+	} else {
+		pdb.calculateChainsOffset()
 
-	// SEQRES chain and position to structure residues.
-	pdb.SeqResChains = make(map[string]map[int64]*Residue)
+		// SEQRES chain and position to structure residues.
+		pdb.SeqResChains = make(map[string]map[int64]*Residue)
 
-	for chain, offset := range pdb.SeqResOffsets {
-		pdb.SeqResChains[chain] = make(map[int64]*Residue)
-		minPos := pdb.ChainStartResNumber[chain]
-		for pos, res := range pdb.Chains[chain] {
-			pdb.SeqResChains[chain][pos-minPos+offset+1] = res
+		for chain, offset := range pdb.SeqResOffsets {
+			pdb.SeqResChains[chain] = make(map[int64]*Residue)
+			minPos := pdb.ChainStartResNumber[chain]
+			for pos, res := range pdb.Chains[chain] {
+				pdb.SeqResChains[chain][pos-minPos+offset+1] = res
+			}
 		}
 	}
 
+	pdb.markModeled()
+
 	// UniProt canonical sequence position to structure residues.
 	pdb.UniProtPositions = make(map[string]map[int64][]*Residue)
+	pdb.UniProtUnobserved = make(map[string]map[int64][]*Residue)
+	if pdb.SIFTS == nil {
+		return
+	}
+	if pdb.SIFTS.Residues != nil {
+		pdb.mapSIFTSResidues()
+		return
+	}
 	// chainMappings := pdb.SIFTS.UniProt[pdb.UniProtID].Mappings
 	for unpID, unp := range pdb.SIFTS.UniProt {
 		pdb.UniProtPositions[unpID] = make(map[int64][]*Residue)
+		pdb.UniProtUnobserved[unpID] = make(map[int64][]*Residue)
 		for _, m := range unp.Mappings {
 			var i int64
 			for i = m.UnpStart; i <= m.UnpEnd; i++ {
@@ -34,6 +75,8 @@ func (pdb *PDB) makeMappings() {
 					pdb.UniProtPositions[unpID][i] = append(pdb.UniProtPositions[unpID][i], res)
 					res.UnpPosition = i
 					res.UnpID = unpID
+				} else if seqRes := pdb.SeqRes[m.ChainID]; seqResPos >= 1 && seqResPos <= int64(len(seqRes)) && !seqRes[seqResPos-1].Modeled {
+					pdb.UniProtUnobserved[unpID][i] = append(pdb.UniProtUnobserved[unpID][i], seqRes[seqResPos-1])
 				}
 			}
 		}
@@ -55,6 +98,9 @@ func (pdb *PDB) calculateChainsOffset() {
 			score := 0
 			for pos, res := range pdb.Chains[chain] {
 				seqResPos := pos + int64(offset) - minPos
+				if seqResPos < 0 || seqResPos >= int64(len(pdb.SeqRes[chain])) {
+					continue
+				}
 				if res.Name1 == pdb.SeqRes[chain][seqResPos].Name1 {
 					score++
 				}
@@ -72,8 +118,127 @@ func (pdb *PDB) calculateChainsOffset() {
 	}
 }
 
+// mapSIFTSResidues maps UniProt positions residue by residue from the SIFTS XML mappings,
+// using the author numbering for observed residues and the SEQRES position for unobserved ones.
+func (pdb *PDB) mapSIFTSResidues() {
+	for _, r := range pdb.SIFTS.Residues {
+		if r.UniProt == "" {
+			continue
+		}
+		if _, ok := pdb.UniProtPositions[r.UniProt]; !ok {
+			pdb.UniProtPositions[r.UniProt] = make(map[int64][]*Residue)
+			pdb.UniProtUnobserved[r.UniProt] = make(map[int64][]*Residue)
+		}
+
+		if r.Observed {
+			if res := pdb.ResidueAt(r.Chain, r.AuthSeqID, r.InsertionCode); res != nil {
+				pdb.UniProtPositions[r.UniProt][r.UniProtPosition] = append(pdb.UniProtPositions[r.UniProt][r.UniProtPosition], res)
+				res.UnpPosition = r.UniProtPosition
+				res.UnpID = r.UniProt
+			}
+		} else if seqRes := pdb.SeqRes[r.Chain]; r.LabelSeqID >= 1 && r.LabelSeqID <= int64(len(seqRes)) {
+			pdb.UniProtUnobserved[r.UniProt][r.UniProtPosition] = append(pdb.UniProtUnobserved[r.UniProt][r.UniProtPosition], seqRes[r.LabelSeqID-1])
+		}
+	}
+}
+
+// alignChains maps the observed residues of each chain to SEQRES positions by sequence alignment.
+// Unlike calculateChainsOffset, this works for chains with internal deletions or arbitrary numbering.
+func (pdb *PDB) alignChains() {
+	pdb.SeqResOffsets = make(map[string]int64)
+	pdb.SeqResChains = make(map[string]map[int64]*Residue)
+	for chain, residues := range pdb.Residues {
+		seqRes := pdb.SeqRes[chain]
+		if len(seqRes) == 0 {
+			continue
+		}
+
+		pdb.SeqResChains[chain] = make(map[int64]*Residue)
+		pairs := align.SemiGlobal(sequence(residues), sequence(seqRes), align.DefaultScoring).Pairs()
+		first := true
+		for i, res := range residues {
+			j, ok := pairs[i]
+			if !ok {
+				continue
+			}
+			res.Position = int64(j + 1)
+			pdb.SeqResChains[chain][res.Position] = res
+			if first {
+				pdb.SeqResOffsets[chain] = res.Position - 1 - (res.StructPosition - pdb.ChainStartResNumber[chain])
+				first = false
+			}
+		}
+	}
+}
+
+// AlignUniProt maps the SEQRES residues of the chains matching the UniProt entry to positions in its canonical sequence
+// by local sequence alignment, without relying on SIFTS. A chain matches if at least half of its SEQRES aligns with
+// 90% identity, which allows for expression tags and engineered mutations. Previous positions for the entry are replaced.
+func (pdb *PDB) AlignUniProt(unp *uniprot.UniProt) error {
+	if unp.Sequence == "" {
+		return errors.New("empty UniProt sequence")
+	}
+	if len(pdb.SeqRes) == 0 {
+		return errors.New("SEQRES not available")
+	}
+	if pdb.SeqResChains == nil {
+		pdb.makeMappings()
+	}
+
+	var chains []string
+	for chain := range pdb.SeqRes {
+		chains = append(chains, chain)
+	}
+	sort.Strings(chains)
+
+	positions := make(map[int64][]*Residue)
+	unobserved := make(map[int64][]*Residue)
+	for _, chain := range chains {
+		seqRes := pdb.SeqRes[chain]
+		al := align.Local(sequence(seqRes), unp.Sequence, align.DefaultScoring)
+		if al.Identity() < minUniProtIdentity || float64(al.Aligned()) < minUniProtCoverage*float64(len(seqRes)) {
+			continue
+		}
+
+		for i, j := range al.Pairs() {
+			unpPos := int64(j + 1)
+			if res, ok := pdb.SeqResChains[chain][int64(i+1)]; ok {
+				positions[unpPos] = append(positions[unpPos], res)
+				res.UnpPosition = unpPos
+				res.UnpID = unp.ID
+			} else if !seqRes[i].Modeled {
+				unobserved[unpPos] = append(unobserved[unpPos], seqRes[i])
+			}
+		}
+	}
+	if len(positions) == 0 && len(unobserved) == 0 {
+		return fmt.Errorf("no chain matches UniProt %s", unp.ID)
+	}
+
+	if pdb.UniProtPositions == nil {
+		pdb.UniProtPositions = make(map[string]map[int64][]*Residue)
+	}
+	if pdb.UniProtUnobserved == nil {
+		pdb.UniProtUnobserved = make(map[string]map[int64][]*Residue)
+	}
+	pdb.UniProtPositions[unp.ID] = positions
+	pdb.UniProtUnobserved[unp.ID] = unobserved
+	pdb.syncModelMappings()
+
+	return nil
+}
+
 // Helpers
 
+// sequence returns the one letter sequence of the residues.
+func sequence(residues []*Residue) string {
+	var b strings.Builder
+	for _, res := range residues {
+		b.WriteString(res.Name1)
+	}
+	return b.String()
+}
+
 func (pdb *PDB) chainKeys(chain string) (k []int64) {
 	for pos := range pdb.Chains[chain] {
 		k = append(k, pos)
@@ -0,0 +1,89 @@
+package pdb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tikz/bio/uniprot"
+)
+
+const insulinSequence = "MALWMRLLPLLALLALWGPDPAAAFVNQHLCGSHLVEALYLVCGERGFFYTPKTRREAEDLQVGQVELGGGPGAGSLQPLALEGSLQKRGIVEQCCTSICSLYQLENYCN"
+
+// deletedChainB returns 1mso with residues 21-25 of chain B removed and the following ones renumbered
+// to close the gap, as in engineered constructs.
+func deletedChainB(raw []byte) []byte {
+	var lines []string
+	for _, line := range strings.Split(string(raw), "\n") {
+		if (strings.HasPrefix(line, "ATOM  ") || strings.HasPrefix(line, "ANISOU")) && line[21:22] == "B" {
+			number, _ := strconv.Atoi(strings.TrimSpace(line[22:26]))
+			if number >= 21 && number <= 25 {
+				continue
+			}
+			if number > 25 {
+				line = line[:22] + fmt.Sprintf("%4d", number-5) + line[26:]
+			}
+		}
+		lines = append(lines, line)
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+func TestAlignmentMapping(t *testing.T) {
+	raw, err := LoadTestFile("./testdata/1mso.pdb")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+	raw = deletedChainB(raw)
+
+	pdb := &PDB{MappingMode: MappingAlignment}
+	if err := pdb.ExtractSeqRes(raw); err != nil {
+		t.Fatal(err)
+	}
+	if err := pdb.ExtractResidues(raw); err != nil {
+		t.Fatal(err)
+	}
+	pdb.makeMappings()
+
+	for _, c := range []struct {
+		number   int64
+		position int64
+	}{{1, 1}, {20, 20}, {21, 26}, {25, 30}} {
+		res := pdb.Chains["B"][c.number]
+		if res.Position != c.position || pdb.SeqResChains["B"][c.position] != res {
+			t.Errorf("B %d mapped to SEQRES %d, expected %d", c.number, res.Position, c.position)
+		}
+	}
+	for pos := int64(21); pos <= 25; pos++ {
+		if _, ok := pdb.SeqResChains["B"][pos]; ok || pdb.SeqRes["B"][pos-1].Modeled {
+			t.Errorf("deleted SEQRES B %d is mapped", pos)
+		}
+	}
+
+	unp := &uniprot.UniProt{ID: "P01308", Sequence: insulinSequence}
+	if err := pdb.AlignUniProt(unp); err != nil {
+		t.Fatal(err)
+	}
+	aStart := int64(strings.Index(insulinSequence, "GIVEQ") + 1)
+	for _, c := range []struct {
+		chain       string
+		number      int64
+		unpPosition int64
+	}{{"A", 1, aStart}, {"C", 21, aStart + 20}, {"B", 1, 25}, {"D", 30, 54}, {"B", 21, 50}} {
+		res := pdb.Chains[c.chain][c.number]
+		if res.UnpID != unp.ID || res.UnpPosition != c.unpPosition {
+			t.Errorf("%s %d mapped to UniProt %d, expected %d", c.chain, c.number, res.UnpPosition, c.unpPosition)
+		}
+	}
+	if n := len(pdb.UniProtPositions[unp.ID][25]); n != 2 {
+		t.Errorf("expected chains B and D at UniProt 25, got %d residues", n)
+	}
+	if n := len(pdb.UniProtUnobserved[unp.ID][45]); n != 1 {
+		t.Errorf("expected the deleted B 21 unobserved at UniProt 45, got %d residues", n)
+	}
+
+	if err := pdb.AlignUniProt(&uniprot.UniProt{ID: "X", Sequence: "WWWWWWWWWWWWWWWWWWWW"}); err == nil {
+		t.Errorf("expected error for unrelated sequence")
+	}
+}
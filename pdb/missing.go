@@ -0,0 +1,244 @@
+package pdb
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// heavyAtoms are the side chain heavy atoms of the standard residues, besides the backbone N, CA, C and O.
+var heavyAtoms = map[string][]string{
+	"ALA": {"CB"},
+	"ARG": {"CB", "CG", "CD", "NE", "CZ", "NH1", "NH2"},
+	"ASN": {"CB", "CG", "OD1", "ND2"},
+	"ASP": {"CB", "CG", "OD1", "OD2"},
+	"CYS": {"CB", "SG"},
+	"GLN": {"CB", "CG", "CD", "OE1", "NE2"},
+	"GLU": {"CB", "CG", "CD", "OE1", "OE2"},
+	"GLY": {},
+	"HIS": {"CB", "CG", "ND1", "CD2", "CE1", "NE2"},
+	"ILE": {"CB", "CG1", "CG2", "CD1"},
+	"LEU": {"CB", "CG", "CD1", "CD2"},
+	"LYS": {"CB", "CG", "CD", "CE", "NZ"},
+	"MET": {"CB", "CG", "SD", "CE"},
+	"MSE": {"CB", "CG", "SE", "CE"},
+	"PHE": {"CB", "CG", "CD1", "CD2", "CE1", "CE2", "CZ"},
+	"PRO": {"CB", "CG", "CD"},
+	"SER": {"CB", "OG"},
+	"THR": {"CB", "OG1", "CG2"},
+	"TRP": {"CB", "CG", "CD1", "CD2", "NE1", "CE2", "CE3", "CZ2", "CZ3", "CH2"},
+	"TYR": {"CB", "CG", "CD1", "CD2", "CE1", "CE2", "CZ", "OH"},
+	"VAL": {"CB", "CG1", "CG2"},
+}
+
+// MissingResidue is a residue of the deposited sequence that was not observed in the experiment.
+type MissingResidue struct {
+	Model         int64  `json:"model"`
+	Name          string `json:"name"`
+	Chain         string `json:"chain"`
+	Number        int64  `json:"number"`
+	InsertionCode string `json:"insertionCode"`
+}
+
+// MissingHeavyAtoms returns the heavy atoms of a standard residue that are not present in the structure,
+// by comparison with the residue template, regardless of the REMARK 470 annotations (see MissingAtoms).
+// The terminal OXT is not considered.
+func (r *Residue) MissingHeavyAtoms() []string {
+	names, ok := heavyAtoms[r.code()]
+	if !ok {
+		return nil
+	}
+
+	var missing []string
+	for _, name := range append([]string{"N", "CA", "C", "O"}, names...) {
+		if r.Atom(name) == nil {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// ExtractMissing parses the unobserved residues and atoms of the first model from the PDB REMARK 465 and 470 records,
+// and sets whether each SEQRES residue is modeled in the structure.
+func (pdb *PDB) ExtractMissing(rawPDB []byte) error {
+	pdb.MissingResidues = nil
+
+	r, _ := regexp.Compile("(?m)^REMARK (465|470).*$")
+	var header465, header470 bool
+	for _, line := range r.FindAllString(string(rawPDB), -1) {
+		// https://www.wwpdb.org/documentation/file-format-content/format33/remarks2.html#REMARK%20465
+		line = fmt.Sprintf("%-80s", line)
+		remark := line[7:10]
+		content := strings.TrimSpace(line[10:])
+		switch {
+		case remark == "465" && strings.HasPrefix(content, "M RES C SSSEQI"):
+			header465 = true
+			continue
+		case remark == "470" && strings.HasPrefix(content, "M RES CSSEQI"):
+			header470 = true
+			continue
+		case (remark == "465" && !header465) || (remark == "470" && !header470):
+			continue
+		}
+
+		model := int64(1)
+		if m := strings.TrimSpace(line[10:15]); m != "" {
+			model, _ = strconv.ParseInt(m, 10, 64)
+		}
+		if model != 1 {
+			continue
+		}
+
+		// Fixed columns, REMARK 470 has no space between chain and residue number (CSSEQI)
+		name, chain := strings.TrimSpace(line[15:18]), strings.TrimSpace(line[19:20])
+		seq, insertionCode := line[21:26], strings.TrimSpace(line[26:27])
+		if remark == "470" {
+			seq, insertionCode = line[20:24], strings.TrimSpace(line[24:25])
+		}
+		number, err := strconv.ParseInt(strings.TrimSpace(seq), 10, 64)
+		if err != nil || name == "" {
+			continue
+		}
+
+		if remark == "465" {
+			pdb.MissingResidues = append(pdb.MissingResidues, &MissingResidue{
+				Model: model, Name: name, Chain: chain, Number: number, InsertionCode: insertionCode,
+			})
+		} else if res := pdb.ResidueAt(chain, number, insertionCode); res != nil {
+			res.MissingAtoms = append(res.MissingAtoms, strings.Fields(line[25:])...)
+		}
+	}
+
+	pdb.markModeled()
+	return nil
+}
+
+// ExtractCIFMissing parses the unobserved residues and atoms of the first model from the CIF
+// _pdbx_unobs_or_zero_occ_residues and _pdbx_unobs_or_zero_occ_atoms categories, and sets whether
+// each SEQRES residue is modeled in the structure.
+func (pdb *PDB) ExtractCIFMissing(rawCIF []byte) error {
+	block, err := firstCIFBlock(rawCIF)
+	if err != nil {
+		return err
+	}
+
+	return pdb.extractCIFMissing(block)
+}
+
+func (pdb *PDB) extractCIFMissing(block *DataBlock) error {
+	pdb.MissingResidues = nil
+
+	// Only unobserved records (occupancy_flag 1), zero occupancy ones are present in _atom_site
+	rows := func(c *Category, each func(i int, chain string, number int64, insertionCode string)) {
+		if c == nil {
+			return
+		}
+		pick := func(row int, auth string, label string) string {
+			if v := c.Get(auth, row); v != "" {
+				return v
+			}
+			return c.Get(label, row)
+		}
+		for i := 0; i < c.Len(); i++ {
+			model, err := strconv.ParseInt(c.Get("PDB_model_num", i), 10, 64)
+			if (err == nil && model != 1) || c.Get("occupancy_flag", i) == "0" {
+				continue
+			}
+			number, err := strconv.ParseInt(pick(i, "auth_seq_id", "label_seq_id"), 10, 64)
+			if err != nil {
+				continue
+			}
+			each(i, pick(i, "auth_asym_id", "label_asym_id"), number, c.Get("PDB_ins_code", i))
+		}
+	}
+
+	residues := block.Category("pdbx_unobs_or_zero_occ_residues")
+	rows(residues, func(i int, chain string, number int64, insertionCode string) {
+		name := residues.Get("auth_comp_id", i)
+		if name == "" {
+			name = residues.Get("label_comp_id", i)
+		}
+		pdb.MissingResidues = append(pdb.MissingResidues, &MissingResidue{
+			Model: 1, Name: name, Chain: chain, Number: number, InsertionCode: insertionCode,
+		})
+	})
+
+	atoms := block.Category("pdbx_unobs_or_zero_occ_atoms")
+	rows(atoms, func(i int, chain string, number int64, insertionCode string) {
+		if res := pdb.ResidueAt(chain, number, insertionCode); res != nil {
+			name := atoms.Get("auth_atom_id", i)
+			if name == "" {
+				name = atoms.Get("label_atom_id", i)
+			}
+			res.MissingAtoms = append(res.MissingAtoms, name)
+		}
+	})
+
+	pdb.markModeled()
+	return nil
+}
+
+// markModeled sets whether each SEQRES residue is modeled. The observed residues and the unobserved ones
+// are merged in order of residue number, which gives the author numbering of the whole SEQRES if the lengths
+// and residue names agree. Otherwise, it falls back to the SEQRES offset calculated for the position mappings.
+func (pdb *PDB) markModeled() {
+	type entry struct {
+		name          string
+		number        int64
+		insertionCode string
+		modeled       bool
+	}
+
+	for chain, seqRes := range pdb.SeqRes {
+		var entries []entry
+		for _, res := range pdb.Residues[chain] {
+			entries = append(entries, entry{res.Name1, res.StructPosition, res.InsertionCode, true})
+		}
+		for _, m := range pdb.MissingResidues {
+			if m.Chain == chain {
+				entries = append(entries, entry{componentLetter(m.Name), m.Number, m.InsertionCode, false})
+			}
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			if entries[i].number != entries[j].number {
+				return entries[i].number < entries[j].number
+			}
+			return entries[i].insertionCode < entries[j].insertionCode
+		})
+
+		matches := 0
+		if len(entries) == len(seqRes) {
+			for i, e := range entries {
+				if e.name == seqRes[i].Name1 {
+					matches++
+				}
+			}
+		}
+
+		if len(seqRes) > 0 && float64(matches)/float64(len(seqRes)) >= 0.9 {
+			for i, e := range entries {
+				seqRes[i].Modeled = e.modeled
+				seqRes[i].StructPosition = e.number
+				seqRes[i].InsertionCode = e.insertionCode
+			}
+			continue
+		}
+
+		for i, res := range seqRes {
+			_, ok := pdb.SeqResChains[chain][int64(i+1)]
+			res.Modeled = ok
+		}
+	}
+}
+
+// splitResidueNumber splits a residue number with an optional insertion code, i.e. 52A.
+func splitResidueNumber(s string) (int64, string, bool) {
+	insertionCode := ""
+	if n := len(s); n > 1 && (s[n-1] < '0' || s[n-1] > '9') {
+		s, insertionCode = s[:n-1], s[n-1:]
+	}
+	number, err := strconv.ParseInt(s, 10, 64)
+	return number, insertionCode, err == nil
+}
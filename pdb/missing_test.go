@@ -0,0 +1,135 @@
+package pdb
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestMissing(t *testing.T) {
+	raw, err := LoadTestFile("./testdata/1mso.pdb")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+	pdb, err := NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pdb.ExtractSeqRes(raw); err != nil {
+		t.Fatal(err)
+	}
+	if err := pdb.ExtractMissing(raw); err != nil {
+		t.Fatal(err)
+	}
+
+	rawCIF, err := LoadTestFile("./testdata/1mso.cif")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+	fromCIF, err := NewPDBFromCIF(rawCIF)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"CG", "CD1", "CD2", "CE1", "CE2", "CZ"}
+	for _, p := range []*PDB{pdb, fromCIF} {
+		if len(p.MissingResidues) != 0 {
+			t.Errorf("expected no missing residues, got %d", len(p.MissingResidues))
+		}
+
+		res := p.ResidueAt("D", 1, "")
+		if res == nil {
+			t.Fatal("residue PHE D 1 not found")
+		}
+		if !reflect.DeepEqual(res.MissingAtoms, expected) {
+			t.Errorf("PHE D 1 missing atoms %v, expected %v", res.MissingAtoms, expected)
+		}
+		if !reflect.DeepEqual(res.MissingHeavyAtoms(), expected) {
+			t.Errorf("PHE D 1 missing heavy atoms %v, expected %v", res.MissingHeavyAtoms(), expected)
+		}
+		if m := p.ResidueAt("D", 2, "").MissingHeavyAtoms(); len(m) != 0 {
+			t.Errorf("VAL D 2 has missing heavy atoms %v", m)
+		}
+
+		for chain, seqRes := range p.SeqRes {
+			for i, r := range seqRes {
+				if !r.Modeled {
+					t.Errorf("SEQRES %s %d not modeled", chain, i+1)
+				}
+			}
+		}
+	}
+}
+
+func TestMissingResidues(t *testing.T) {
+	raw, err := LoadTestFile("./testdata/1mso.pdb")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+
+	// Remove GLY A 1 and annotate it as unobserved
+	r := regexp.MustCompile("(?m)^(ATOM  |ANISOU).{11}GLY A   1 .*\n")
+	edited := r.ReplaceAllString(string(raw), "")
+	remark := "REMARK 465   M RES C SSSEQI\nREMARK 465     GLY A     1\n"
+	edited = strings.Replace(edited, "REMARK 470   ", remark+"REMARK 470   ", 1)
+
+	pdb, err := NewPDBFromRaw([]byte(edited))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pdb.ExtractSeqRes([]byte(edited)); err != nil {
+		t.Fatal(err)
+	}
+	if err := pdb.ExtractMissing([]byte(edited)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pdb.MissingResidues) != 1 {
+		t.Fatalf("expected 1 missing residue, got %d", len(pdb.MissingResidues))
+	}
+	m := pdb.MissingResidues[0]
+	if m.Name != "GLY" || m.Chain != "A" || m.Number != 1 {
+		t.Errorf("unexpected missing residue %s %s %d", m.Name, m.Chain, m.Number)
+	}
+
+	first := pdb.SeqRes["A"][0]
+	if first.Modeled || first.StructPosition != 1 {
+		t.Errorf("SEQRES A 1 modeled %v at %d, expected unmodeled at 1", first.Modeled, first.StructPosition)
+	}
+	if second := pdb.SeqRes["A"][1]; !second.Modeled || second.StructPosition != 2 {
+		t.Errorf("SEQRES A 2 modeled %v at %d, expected modeled at 2", second.Modeled, second.StructPosition)
+	}
+}
+
+func TestMissingFourDigitNumbers(t *testing.T) {
+	raw, err := LoadTestFile("./testdata/1mso.pdb")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+
+	// PHE D 1 renumbered as 1034, and an unobserved GLY A 1001
+	r := regexp.MustCompile("(?m)^(ATOM  .{11}|REMARK 470     )PHE D   1")
+	edited := r.ReplaceAllString(string(raw), "${1}PHE D1034")
+	remark := "REMARK 465   M RES C SSSEQI\nREMARK 465     GLY A  1001A\n"
+	edited = strings.Replace(edited, "REMARK 470   ", remark+"REMARK 470   ", 1)
+
+	pdb, err := NewPDBFromRaw([]byte(edited))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pdb.ExtractMissing([]byte(edited)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"CG", "CD1", "CD2", "CE1", "CE2", "CZ"}
+	if res := pdb.ResidueAt("D", 1034, ""); res == nil || !reflect.DeepEqual(res.MissingAtoms, expected) {
+		t.Errorf("expected missing atoms %v in PHE D 1034, got %+v", expected, res)
+	}
+	if len(pdb.MissingResidues) != 1 {
+		t.Fatalf("expected 1 missing residue, got %d", len(pdb.MissingResidues))
+	}
+	if m := pdb.MissingResidues[0]; m.Name != "GLY" || m.Chain != "A" || m.Number != 1001 || m.InsertionCode != "A" {
+		t.Errorf("unexpected missing residue %+v", m)
+	}
+}
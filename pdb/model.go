@@ -0,0 +1,227 @@
+package pdb
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Model represents a single model of the structure, such as each conformer of an NMR ensemble.
+type Model struct {
+	Number   int64                         `json:"number"`
+	Atoms    []*Atom                       `json:"-"` // ATOM records in the model, and HETATM records of modified residues
+	HetAtoms []*Atom                       `json:"-"` // HETATM records in the model, except modified residues
+	Hets     []*HetGroup                   `json:"-"` // het group instances in the model, in file order
+	Chains   map[string]map[int64]*Residue `json:"-"` // chain ID and position to residue in the model
+	Residues map[string][]*Residue         `json:"-"` // chain ID to residues in file order
+
+	indexMu   sync.Mutex
+	atomIndex *Index // spatial index of Atoms, built on the first query and discarded by Reindex
+	hetIndex  *Index // spatial index of HetAtoms, built on the first query and discarded by Reindex
+}
+
+// Reindex discards the spatial indexes of the model, so they are rebuilt on the next neighbor query.
+// It must be called after moving or replacing atoms by other means than the functions of this package.
+func (m *Model) Reindex() {
+	m.indexMu.Lock()
+	m.atomIndex, m.hetIndex = nil, nil
+	m.indexMu.Unlock()
+}
+
+// Reindex discards the spatial indexes of all models of the structure, see Model.Reindex.
+func (pdb *PDB) Reindex() {
+	for _, m := range pdb.Models {
+		m.Reindex()
+	}
+}
+
+// setModels splits ATOM and HETATM records by model number, in order of appearance,
+// and sets the first model as the default view of the structure.
+func (pdb *PDB) setModels(atoms []*Atom, hetatms []*Atom) {
+	pdb.Models = nil
+	models := make(map[int64]*Model)
+	model := func(number int64) *Model {
+		m, ok := models[number]
+		if !ok {
+			m = &Model{Number: number}
+			models[number] = m
+			pdb.Models = append(pdb.Models, m)
+		}
+		return m
+	}
+
+	for _, atom := range atoms {
+		m := model(atom.Model)
+		m.Atoms = append(m.Atoms, atom)
+		atom.owner = m
+	}
+	for _, atom := range hetatms {
+		m := model(atom.Model)
+		m.HetAtoms = append(m.HetAtoms, atom)
+		atom.owner = m
+	}
+
+	pdb.Atoms, pdb.HetAtoms = nil, nil
+	if len(pdb.Models) > 0 {
+		pdb.Atoms = pdb.Models[0].Atoms
+		pdb.HetAtoms = pdb.Models[0].HetAtoms
+	}
+}
+
+// ModelView returns a shallow copy of the structure where the given model is the default view,
+// so any function that receives a PDB can be run on it. Position mappings are translated to the
+// residues of the model, without modifying any residue.
+func (pdb *PDB) ModelView(number int64) (*PDB, error) {
+	var model *Model
+	for _, m := range pdb.Models {
+		if m.Number == number {
+			model = m
+		}
+	}
+	if model == nil {
+		return nil, fmt.Errorf("model %d not found", number)
+	}
+
+	view := *pdb
+	view.Models = []*Model{model}
+	view.Atoms = model.Atoms
+	view.HetAtoms = model.HetAtoms
+	view.Hets = model.Hets
+	view.Chains = model.Chains
+	view.Residues = model.Residues
+
+	if model == pdb.Models[0] {
+		return &view, nil
+	}
+
+	equivalent := pdb.equivalentResidues(model)
+
+	if pdb.SeqResChains != nil {
+		view.SeqResChains = make(map[string]map[int64]*Residue)
+		for chain, positions := range pdb.SeqResChains {
+			view.SeqResChains[chain] = make(map[int64]*Residue)
+			for pos, res := range positions {
+				if mRes, ok := equivalent[res]; ok {
+					view.SeqResChains[chain][pos] = mRes
+				}
+			}
+		}
+	}
+
+	if pdb.UniProtPositions != nil {
+		view.UniProtPositions = make(map[string]map[int64][]*Residue)
+		for unpID, positions := range pdb.UniProtPositions {
+			view.UniProtPositions[unpID] = make(map[int64][]*Residue)
+			for pos, residues := range positions {
+				for _, res := range residues {
+					if mRes, ok := equivalent[res]; ok {
+						view.UniProtPositions[unpID][pos] = append(view.UniProtPositions[unpID][pos], mRes)
+					}
+				}
+			}
+		}
+	}
+
+	if pdb.BindingSite != nil {
+		view.BindingSite = make(map[string][]*Residue)
+		for site, residues := range pdb.BindingSite {
+			for _, res := range residues {
+				if mRes, ok := equivalent[res]; ok {
+					view.BindingSite[site] = append(view.BindingSite[site], mRes)
+				}
+			}
+		}
+	}
+
+	return &view, nil
+}
+
+// equivalentResidues maps the residues of the default view to the residues of the given model
+// with the same chain, number and insertion code.
+func (pdb *PDB) equivalentResidues(model *Model) map[*Residue]*Residue {
+	equivalent := make(map[*Residue]*Residue)
+	for chain, residues := range pdb.Residues {
+		modelResidues := make(map[residueKey]*Residue)
+		for _, res := range model.Residues[chain] {
+			modelResidues[residueKey{chain, res.StructPosition, res.InsertionCode}] = res
+		}
+		for _, res := range residues {
+			if mRes, ok := modelResidues[residueKey{chain, res.StructPosition, res.InsertionCode}]; ok {
+				equivalent[res] = mRes
+			}
+		}
+	}
+	return equivalent
+}
+
+// syncModelMappings copies the SEQRES and UniProt positions of the default view residues to the
+// equivalent residues of the other models, once the mappings are made.
+func (pdb *PDB) syncModelMappings() {
+	for i, m := range pdb.Models {
+		if i == 0 {
+			continue
+		}
+		for res, mRes := range pdb.equivalentResidues(m) {
+			mRes.Position = res.Position
+			mRes.UnpID = res.UnpID
+			mRes.UnpPosition = res.UnpPosition
+		}
+	}
+}
+
+// RMSF calculates the root mean square fluctuation of each residue across all models of the structure,
+// as the mean RMSF of the residue atoms present in every model. Models are assumed to be already superposed,
+// as they are in deposited NMR ensembles. Residues are those of the first model.
+func (pdb *PDB) RMSF() (map[*Residue]float64, error) {
+	if len(pdb.Models) < 2 {
+		return nil, fmt.Errorf("RMSF needs at least 2 models, found %d", len(pdb.Models))
+	}
+
+	type atomKey struct {
+		residueKey
+		name   string
+		altLoc string
+	}
+
+	// Atom positions across models
+	positions := make(map[atomKey][][3]float64)
+	for _, m := range pdb.Models {
+		for _, atom := range m.Atoms {
+			key := atomKey{atomResidueKey(atom), atom.Name, atom.AltLoc}
+			positions[key] = append(positions[key], [3]float64{atom.X, atom.Y, atom.Z})
+		}
+	}
+
+	rmsf := make(map[*Residue]float64)
+	for _, residues := range pdb.Residues {
+		for _, res := range residues {
+			var sum, n float64
+			for _, atom := range res.Atoms {
+				coords := positions[atomKey{atomResidueKey(atom), atom.Name, atom.AltLoc}]
+				if len(coords) != len(pdb.Models) {
+					continue
+				}
+
+				var mean [3]float64
+				for _, c := range coords {
+					for i := range mean {
+						mean[i] += c[i] / float64(len(coords))
+					}
+				}
+
+				var msd float64
+				for _, c := range coords {
+					msd += math.Pow(c[0]-mean[0], 2) + math.Pow(c[1]-mean[1], 2) + math.Pow(c[2]-mean[2], 2)
+				}
+				sum += math.Sqrt(msd / float64(len(coords)))
+				n++
+			}
+
+			if n > 0 {
+				rmsf[res] = sum / n
+			}
+		}
+	}
+
+	return rmsf, nil
+}
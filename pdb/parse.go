@@ -3,7 +3,6 @@ package pdb
 import (
 	"errors"
 	"fmt"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -11,26 +10,73 @@ import (
 
 // ExtractCIFData parses the associated CIF file for the PDB entry.
 func (pdb *PDB) ExtractCIFData(rawCIF []byte) error {
-	title, err := extractCIFLine("title", "_struct.title", rawCIF)
+	block, err := firstCIFBlock(rawCIF)
 	if err != nil {
 		return err
 	}
 
-	method, err := extractCIFLine("method", "_refine.pdbx_refine_id", rawCIF)
+	return pdb.extractCIFData(block)
+}
+
+// ExtractCIFResidues extracts the atoms from the CIF _atom_site category and parses them into residues,
+// the same way ExtractResidues does for PDB ATOM and HETATM records.
+func (pdb *PDB) ExtractCIFResidues(rawCIF []byte) error {
+	block, err := firstCIFBlock(rawCIF)
 	if err != nil {
 		return err
 	}
 
-	resolutionStr, err := extractCIFLine("resolution", "_refine.ls_d_res_high", rawCIF)
+	return pdb.extractCIFResidues(block)
+}
+
+// ExtractCIFSeqRes parses the CIF _entity_poly_seq category for the primary sequence of each chain.
+func (pdb *PDB) ExtractCIFSeqRes(rawCIF []byte) error {
+	block, err := firstCIFBlock(rawCIF)
 	if err != nil {
 		return err
 	}
-	resolution, err := strconv.ParseFloat(resolutionStr, 64)
+
+	return pdb.extractCIFSeqRes(block)
+}
+
+func firstCIFBlock(rawCIF []byte) (*DataBlock, error) {
+	cif, err := ParseCIF(rawCIF)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("parse CIF: %v", err)
+	}
+
+	return cif.Blocks[0], nil
+}
+
+func (pdb *PDB) extractCIFData(block *DataBlock) error {
+	title, ok := block.Value("_struct.title")
+	if !ok {
+		return errors.New("CIF title not found")
 	}
 
-	date, err := extractCIFDate(rawCIF)
+	method, ok := block.Value("_exptl.method")
+	if !ok {
+		method, ok = block.Value("_refine.pdbx_refine_id")
+		if !ok {
+			return errors.New("CIF method not found")
+		}
+	}
+
+	// Resolution is not available for NMR entries and predicted models, left as 0
+	var resolution float64
+	resolutionStr, ok := block.Value("_refine.ls_d_res_high")
+	if !ok {
+		resolutionStr, ok = block.Value("_em_3d_reconstruction.resolution")
+	}
+	if ok {
+		var err error
+		resolution, err = strconv.ParseFloat(resolutionStr, 64)
+		if err != nil {
+			return err
+		}
+	}
+
+	date, err := extractCIFDate(block)
 	if err != nil {
 		return err
 	}
@@ -43,32 +89,155 @@ func (pdb *PDB) ExtractCIFData(rawCIF []byte) error {
 	return nil
 }
 
-func extractCIFLine(name string, pattern string, raw []byte) (string, error) {
-	r, _ := regexp.Compile("(?s)" + pattern + "[ ]*(.*?)_")
-	matches := r.FindAllStringSubmatch(string(raw), -1)
-	if len(matches) == 0 {
-		return "", errors.New("CIF " + name + " not found")
+// extractCIFDate parses the main publication date from the CIF file, or the first revision date
+// for files without deposition data, such as predicted models.
+func extractCIFDate(block *DataBlock) (*time.Time, error) {
+	dateStr, ok := block.Value("_pdbx_database_status.recvd_initial_deposition_date")
+	if !ok {
+		revisions := block.Values("_pdbx_audit_revision_history.revision_date")
+		if len(revisions) == 0 {
+			return nil, errors.New("CIF date not found")
+		}
+		dateStr = revisions[0]
 	}
 
-	match := matches[0][1]
-	match = strings.TrimSpace(match)
-	match = strings.Replace(match, "'", "", -1)
+	t, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return nil, fmt.Errorf("parse CIF date: %v", err)
+	}
 
-	return match, nil
+	return &t, nil
 }
 
-// extractCIFDate parses the main publication date from the CIF file.
-func extractCIFDate(raw []byte) (*time.Time, error) {
-	r, _ := regexp.Compile("_pdbx_database_status.recvd_initial_deposition_date[ ]*([0-9]*-[0-9]*-[0-9]*)")
-	matches := r.FindAllStringSubmatch(string(raw), -1)
-	if len(matches) == 0 {
-		return nil, errors.New("CIF date not found")
+// extractCIFResidues fills the ATOM and HETATM lists from the _atom_site category and builds the chains.
+func (pdb *PDB) extractCIFResidues(block *DataBlock) error {
+	sites := block.Category("atom_site")
+	if sites == nil {
+		return errors.New("atoms not found")
+	}
+
+	// Prefer author provided identifiers, which are the ones used in PDB files and by SIFTS.
+	pick := func(row int, auth string, label string) string {
+		if v := sites.Get(auth, row); v != "" {
+			return v
+		}
+		return sites.Get(label, row)
+	}
+	parseFloat := func(s string) float64 {
+		v, _ := strconv.ParseFloat(s, 64)
+		return v
 	}
 
-	t, err := time.Parse("2006-01-02", string(matches[0][1]))
+	var atoms, hetatms []*Atom
+	var lastRes string
+	var err error
+	for i := 0; i < sites.Len(); i++ {
+		var atom Atom
+
+		// https://mmcif.wwpdb.org/dictionaries/mmcif_pdbx_v50.dic/Categories/atom_site.html
+		atom.Number, _ = strconv.ParseInt(sites.Get("id", i), 10, 64)
+		atom.Name = pick(i, "auth_atom_id", "label_atom_id")
+		atom.AltLoc = sites.Get("label_alt_id", i)
+		atom.Residue = pick(i, "auth_comp_id", "label_comp_id")
+		atom.Chain = pick(i, "auth_asym_id", "label_asym_id")
+		atom.ResidueNumber, _ = strconv.ParseInt(pick(i, "auth_seq_id", "label_seq_id"), 10, 64)
+		atom.InsertionCode = sites.Get("pdbx_PDB_ins_code", i)
+		atom.X = parseFloat(sites.Get("Cartn_x", i))
+		atom.Y = parseFloat(sites.Get("Cartn_y", i))
+		atom.Z = parseFloat(sites.Get("Cartn_z", i))
+		atom.Occupancy = parseFloat(sites.Get("occupancy", i))
+		atom.BFactor = parseFloat(sites.Get("B_iso_or_equiv", i))
+		atom.Element = sites.Get("type_symbol", i)
+		atom.Charge = cifCharge(sites.Get("pdbx_formal_charge", i))
+		atom.Model, err = strconv.ParseInt(sites.Get("pdbx_PDB_model_num", i), 10, 64)
+		if err != nil {
+			atom.Model = 1
+		}
+
+		atom.LabelAsymID = sites.Get("label_asym_id", i)
+		atom.LabelSeqID, _ = strconv.ParseInt(sites.Get("label_seq_id", i), 10, 64)
+		atom.EntityID = sites.Get("label_entity_id", i)
+
+		atom.Het = sites.Get("group_PDB", i) == "HETATM"
+		if atom.Het {
+			hetatms = append(hetatms, &atom)
+			if atom.Residue != lastRes {
+				lastRes = atom.Residue
+				pdb.addHetGroup(lastRes)
+			}
+		} else {
+			atoms = append(atoms, &atom)
+		}
+	}
+
+	pdb.setModels(filterAltLocs(atoms, pdb.AltLocPolicy), filterAltLocs(hetatms, pdb.AltLocPolicy))
+
+	err = pdb.ExtractPDBChains()
 	if err != nil {
-		return nil, fmt.Errorf("parse CIF date: %v", err)
+		return fmt.Errorf("extract CIF chains: %v", err)
 	}
 
-	return &t, nil
+	return nil
+}
+
+// cifCharge converts a CIF formal charge (i.e. -1) to the PDB column notation (i.e. 1-).
+func cifCharge(charge string) string {
+	c, err := strconv.ParseInt(charge, 10, 64)
+	if err != nil || c == 0 {
+		return ""
+	}
+	if c < 0 {
+		return strconv.FormatInt(-c, 10) + "-"
+	}
+	return strconv.FormatInt(c, 10) + "+"
+}
+
+// extractCIFSeqRes builds SEQRES chains from the polymer entities sequences.
+func (pdb *PDB) extractCIFSeqRes(block *DataBlock) error {
+	seq := block.Category("entity_poly_seq")
+	if seq == nil {
+		return errors.New("entity_poly_seq not found")
+	}
+
+	// Entity ID to author chain IDs
+	entityChains := make(map[string][]string)
+	if poly := block.Category("entity_poly"); poly != nil {
+		for i := 0; i < poly.Len(); i++ {
+			for _, chain := range strings.Split(poly.Get("pdbx_strand_id", i), ",") {
+				if chain = strings.TrimSpace(chain); chain != "" {
+					entityID := poly.Get("entity_id", i)
+					entityChains[entityID] = append(entityChains[entityID], chain)
+				}
+			}
+		}
+	}
+	if len(entityChains) == 0 {
+		seen := make(map[string]bool)
+		for _, atom := range pdb.Atoms {
+			if atom.EntityID != "" && !seen[atom.Chain] {
+				seen[atom.Chain] = true
+				entityChains[atom.EntityID] = append(entityChains[atom.EntityID], atom.Chain)
+			}
+		}
+	}
+
+	pdb.SeqRes = make(map[string][]*Residue)
+	lastNum := make(map[string]int64)
+	for i := 0; i < seq.Len(); i++ {
+		entityID := seq.Get("entity_id", i)
+		num, _ := strconv.ParseInt(seq.Get("num", i), 10, 64)
+
+		// Microheterogeneity lists more than one monomer for the same position, keep the first one.
+		if n, ok := lastNum[entityID]; ok && n == num {
+			continue
+		}
+		lastNum[entityID] = num
+
+		for _, chain := range entityChains[entityID] {
+			res := newResidueFromCode(chain, num, seq.Get("mon_id", i))
+			pdb.SeqRes[chain] = append(pdb.SeqRes[chain], res)
+		}
+	}
+
+	return nil
 }
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"time"
 
@@ -28,20 +27,34 @@ type PDB struct {
 	Resolution  float64    `json:"resolution"`  // method resolution
 	TotalLength int64      `json:"totalLength"` // total length as sum of residues of all chains in the structure
 
-	Atoms     []*Atom  `json:"-"`         // ATOM records in the structure
-	HetAtoms  []*Atom  `json:"-"`         // HETATM records in the structure
-	HetGroups []string `json:"hetGroups"` // HET groups in the structure
+	Models    []*Model    `json:"-"`         // all models in the structure, the first one being the default view
+	Atoms     []*Atom     `json:"-"`         // ATOM records in the first model of the structure, and HETATM records of modified residues
+	HetAtoms  []*Atom     `json:"-"`         // HETATM records in the first model of the structure, except modified residues
+	HetGroups []string    `json:"hetGroups"` // HET groups in the structure
+	Hets      []*HetGroup `json:"-"`         // het group instances in the first model of the structure, in file order
 
 	// Position mapping
 	SIFTS               *SIFTS           // EBI SIFTS data for residue position mapping
+	SIFTSDir            string           `json:"-"`                   // local directory with the SIFTS XML files, used instead of the PDBe API if set
 	SeqResOffsets       map[string]int64 `json:"seqResOffsets"`       // Chain ID to SEQRES position offsets
 	ChainStartResNumber map[string]int64 `json:"chainStartResNumber"` // Chain ID to First residue number as informed in ATOM column.
 	ChainEndResNumber   map[string]int64 `json:"chainEndResNumber"`   // Chain ID to Last residue number as informed in ATOM column.
 
-	SeqRes           map[string][]*Residue           `json:"-"` // PDB SEQRES chain ID to residue pointers
-	SeqResChains     map[string]map[int64]*Residue   `json:"-"` // PDB SEQRES chain ID and PDB ATOM position to residue in structure
-	Chains           map[string]map[int64]*Residue   `json:"-"` // PDB ATOM chain ID and position to pointer in structure
-	UniProtPositions map[string]map[int64][]*Residue `json:"-"` // UniProt ID to sequence position to residue(s) (multiple chains) in structure
+	SeqRes            map[string][]*Residue           `json:"-"` // PDB SEQRES chain ID to residue pointers
+	SeqResChains      map[string]map[int64]*Residue   `json:"-"` // PDB SEQRES chain ID and PDB ATOM position to residue in structure
+	Chains            map[string]map[int64]*Residue   `json:"-"` // PDB ATOM chain ID and position to pointer in the first model
+	Residues          map[string][]*Residue           `json:"-"` // PDB ATOM chain ID to residues in file order of the first model, including insertion codes
+	UniProtPositions  map[string]map[int64][]*Residue `json:"-"` // UniProt ID to sequence position to residue(s) (multiple chains) in structure
+	UniProtUnobserved map[string]map[int64][]*Residue `json:"-"` // UniProt ID to sequence position to SEQRES residue(s) covered by the mapping but not modeled
+
+	MissingResidues []*MissingResidue `json:"missingResidues"` // unobserved residues of the first model, from REMARK 465 or the CIF file
+
+	Assemblies []*Assembly `json:"assemblies"` // biological assemblies from REMARK 350 or _pdbx_struct_assembly
+	Cell       *Cell       `json:"cell"`       // crystallographic unit cell, nil if not available
+
+	// Predicted models
+	PAE    [][]float64 `json:"-"`      // AlphaFold predicted aligned error in A, indexed by residue position (0-based)
+	MaxPAE float64     `json:"maxPae"` // maximum possible value of the predicted aligned error
 
 	// Extra data
 	// SITE records
@@ -50,6 +63,9 @@ type PDB struct {
 	// REMARK 800 site descriptions
 	BindingSiteDesc map[string]string `json:"bindingSiteDesc"` // binding site identifier to description
 
+	AltLocPolicy AltLocPolicy `json:"-"` // alternate locations to keep when parsing, highest occupancy by default
+	MappingMode  MappingMode  `json:"-"` // how observed residues are mapped to SEQRES positions, by residue number offset by default
+
 	PDBPath string `json:"-"` // local path for the PDB file
 	CIFPath string `json:"-"` // local path for the CIF file
 }
@@ -75,6 +91,46 @@ func NewPDBFromRaw(raw []byte) (*PDB, error) {
 	return &pdb, nil
 }
 
+// NewPDBFromCIF constructs a new instance from raw mmCIF bytes, extracting the atoms and, if available,
+// the polymer sequences. Unlike NewPDBFromRaw, this works for structures distributed only as mmCIF.
+func NewPDBFromCIF(raw []byte) (*PDB, error) {
+	pdb := PDB{}
+
+	block, err := firstCIFBlock(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	err = pdb.extractCIFResidues(block)
+	if err != nil {
+		return nil, fmt.Errorf("parse: %v", err)
+	}
+
+	if block.Category("entity_poly_seq") != nil {
+		err = pdb.extractCIFSeqRes(block)
+		if err != nil {
+			return nil, fmt.Errorf("parse SEQRES: %v", err)
+		}
+	}
+
+	err = pdb.extractCIFAssemblies(block)
+	if err != nil {
+		return nil, fmt.Errorf("parse assemblies: %v", err)
+	}
+
+	err = pdb.extractCIFCell(block)
+	if err != nil {
+		return nil, fmt.Errorf("parse cell: %v", err)
+	}
+
+	err = pdb.extractCIFMissing(block)
+	if err != nil {
+		return nil, fmt.Errorf("parse missing residues: %v", err)
+	}
+
+	return &pdb, nil
+}
+
 // Load fetches and parses the necessary data.
 func (pdb *PDB) Load() error {
 	err := pdb.Fetch()
@@ -91,12 +147,17 @@ func (pdb *PDB) Load() error {
 }
 
 // Parse parses the raw PDB text.
+// If there is no PDB file for the entry, the structure is parsed from the CIF file alone.
 func (pdb *PDB) Parse() error {
 	rawCIF, err := pdb.RawCIF()
 	if err != nil {
 		return err
 	}
 
+	if pdb.PDBPath == "" {
+		return pdb.parseCIF(rawCIF)
+	}
+
 	rawPDB, err := pdb.RawPDB()
 	if err != nil {
 		return err
@@ -117,9 +178,67 @@ func (pdb *PDB) Parse() error {
 		return fmt.Errorf("extract CIF data: %v", err)
 	}
 
+	err = pdb.ExtractMissing(rawPDB)
+	if err != nil {
+		return fmt.Errorf("extract missing residues: %v", err)
+	}
+
 	pdb.makeMappings()
 
 	pdb.extractSites(rawPDB)
+
+	err = pdb.ExtractAssemblies(rawPDB)
+	if err != nil {
+		return fmt.Errorf("extract assemblies: %v", err)
+	}
+
+	err = pdb.ExtractCell(rawPDB)
+	if err != nil {
+		return fmt.Errorf("extract cell: %v", err)
+	}
+
+	return nil
+}
+
+// parseCIF extracts the structure, sequences and metadata from the CIF file only.
+func (pdb *PDB) parseCIF(rawCIF []byte) error {
+	block, err := firstCIFBlock(rawCIF)
+	if err != nil {
+		return err
+	}
+
+	err = pdb.extractCIFResidues(block)
+	if err != nil {
+		return fmt.Errorf("extract CIF residues: %v", err)
+	}
+
+	err = pdb.extractCIFSeqRes(block)
+	if err != nil {
+		return fmt.Errorf("extract CIF SEQRES: %v", err)
+	}
+
+	err = pdb.extractCIFData(block)
+	if err != nil {
+		return fmt.Errorf("extract CIF data: %v", err)
+	}
+
+	err = pdb.extractCIFAssemblies(block)
+	if err != nil {
+		return fmt.Errorf("extract CIF assemblies: %v", err)
+	}
+
+	err = pdb.extractCIFCell(block)
+	if err != nil {
+		return fmt.Errorf("extract CIF cell: %v", err)
+	}
+
+	err = pdb.extractCIFMissing(block)
+	if err != nil {
+		return fmt.Errorf("extract CIF missing residues: %v", err)
+	}
+
+	pdb.makeMappings()
+
 	return nil
 }
 
@@ -139,6 +258,7 @@ func (pdb *PDB) RawPDB() ([]byte, error) {
 }
 
 // Fetch downloads all external data for the entry.
+// Large structures are only distributed as mmCIF, in which case PDBPath is left empty.
 func (pdb *PDB) Fetch() error {
 	pdb.URL = "https://www.rcsb.org/structure/" + pdb.ID
 
@@ -153,15 +273,24 @@ func (pdb *PDB) Fetch() error {
 	}
 
 	pdb.PDBPath = dataDir + pdb.ID + ".pdb"
+	_, err = os.Stat(pdb.PDBPath)
 	if os.IsNotExist(err) {
 		rawPDB, err := http.Get("https://files.rcsb.org/download/" + pdb.ID + ".pdb")
-		if err != nil {
+		switch {
+		case http.IsNotFound(err):
+			pdb.PDBPath = "" // large or recent structures are only distributed as mmCIF
+		case err != nil:
 			return fmt.Errorf("download PDB file: %v", err)
+		default:
+			writeFile(pdb.PDBPath, rawPDB)
 		}
-		writeFile(pdb.PDBPath, rawPDB)
 	}
 
-	err = pdb.getSIFTSMappings()
+	if pdb.SIFTSDir != "" {
+		err = pdb.LoadSIFTSXML(pdb.SIFTSDir)
+	} else {
+		err = pdb.getSIFTSMappings()
+	}
 	if err != nil {
 		return fmt.Errorf("SIFTS: %v", err)
 	}
@@ -178,10 +307,23 @@ func writeFile(path string, data []byte) error {
 	return nil
 }
 
+// CopyPDB writes the in-memory structure as a PDB file in the given directory, named after the
+// original PDB file or the PDB ID, and returns its path. Since the file is written from the parsed records,
+// any change made to the structure (i.e. filtered atoms or edited B-factors) is reflected.
 func (pdb *PDB) CopyPDB(path string) (string, error) {
-	_, filename := filepath.Split(pdb.PDBPath)
+	filename := pdb.ID + ".pdb"
+	if pdb.PDBPath != "" {
+		_, filename = filepath.Split(pdb.PDBPath)
+	}
 	dstPath := filepath.Clean(path) + "/" + filename
-	_, err := exec.Command("cp", pdb.PDBPath, dstPath).Output()
+
+	f, err := os.Create(dstPath)
+	if err != nil {
+		return dstPath, err
+	}
+	defer f.Close()
+
+	err = pdb.WritePDB(f)
 	if err != nil {
 		return dstPath, err
 	}
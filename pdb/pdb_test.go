@@ -201,3 +201,118 @@ func TestMappings(t *testing.T) {
 		}
 	}
 }
+
+func TestAltLocs(t *testing.T) {
+	raw, err := LoadTestFile("./testdata/1mso.pdb")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+
+	pdb, err := NewPDBFromRaw(raw)
+	if err != nil {
+		t.Error(err)
+	}
+
+	res := pdb.Chains["B"][4]
+	if len(res.Atoms) != 17 {
+		t.Errorf("expected 17 atoms in B-4, got %d", len(res.Atoms))
+	}
+	for _, atom := range res.Atoms {
+		if atom.AltLoc != "A" {
+			t.Errorf("expected altLoc A in B-4, got %s", atom.AltLoc)
+		}
+	}
+
+	all := &PDB{AltLocPolicy: AltLocAll}
+	err = all.ExtractResidues(raw)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if n := len(all.Chains["B"][4].Atoms); n != 34 {
+		t.Errorf("expected 34 atoms in B-4 keeping all altLocs, got %d", n)
+	}
+}
+
+func TestInsertionCodes(t *testing.T) {
+	raw := []byte(`ATOM      1  CA  SER H  52      11.104   6.134  -6.504  1.00  0.00           C  
+ATOM      2  CA  GLY H  52A     11.639   6.071  -5.147  1.00  0.00           C  
+ATOM      3  CA  ASP H  52B     12.040   4.617  -4.830  1.00  0.00           C  
+ATOM      4  CA  TYR H  53      13.089   4.132  -5.219  1.00  0.00           C  
+`)
+
+	pdb, err := NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pdb.TotalLength != 4 || len(pdb.Residues["H"]) != 4 {
+		t.Errorf("expected 4 residues, got %d", pdb.TotalLength)
+	}
+
+	if res := pdb.Chains["H"][52]; res.Name3 != "Ser" || res.InsertionCode != "" {
+		t.Errorf("expected Ser in H-52, got %s%s", res.Name3, res.InsertionCode)
+	}
+
+	res := pdb.ResidueAt("H", 52, "A")
+	if res == nil || res.Name3 != "Gly" || len(res.Atoms) != 1 {
+		t.Errorf("expected Gly in H-52A")
+	}
+}
+
+func TestModels(t *testing.T) {
+	raw := []byte(`MODEL        1
+ATOM      1  CA  GLY A   1       0.000   0.000   0.000  1.00  0.00           C  
+ATOM      2  CA  ALA A   2       3.800   0.000   0.000  1.00  0.00           C  
+ENDMDL
+MODEL        2
+ATOM      1  CA  GLY A   1       0.000   2.000   0.000  1.00  0.00           C  
+ATOM      2  CA  ALA A   2       3.800   0.000   0.000  1.00  0.00           C  
+ENDMDL
+`)
+
+	pdb, err := NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pdb.Models) != 2 || pdb.TotalLength != 2 || len(pdb.Atoms) != 2 {
+		t.Fatalf("expected 2 models of 2 residues each")
+	}
+
+	view, err := pdb.ModelView(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view.Chains["A"][1].Atoms[0].Y != 2.0 {
+		t.Errorf("expected model 2 coordinates in view")
+	}
+
+	// Mappings are translated to the model residues without writing into them
+	first := pdb.Chains["A"][1]
+	first.UnpID, first.UnpPosition = "P00000", 90
+	pdb.UniProtPositions = map[string]map[int64][]*Residue{"P00000": {90: {first}}}
+	view, err = pdb.ModelView(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mRes := view.Chains["A"][1]
+	if got := view.UniProtPositions["P00000"][90]; len(got) != 1 || got[0] != mRes {
+		t.Errorf("expected UniProt position mapped to the model residue")
+	}
+	if mRes.UnpPosition != 0 {
+		t.Errorf("model residue modified by ModelView")
+	}
+	pdb.syncModelMappings()
+	if mRes.UnpID != "P00000" || mRes.UnpPosition != 90 {
+		t.Errorf("expected mapping copied to model residues, got %s %d", mRes.UnpID, mRes.UnpPosition)
+	}
+
+	rmsf, err := pdb.RMSF()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rmsf[pdb.Chains["A"][1]] != 1.0 || rmsf[pdb.Chains["A"][2]] != 0 {
+		t.Errorf("unexpected RMSF values %f, %f", rmsf[pdb.Chains["A"][1]], rmsf[pdb.Chains["A"][2]])
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"math"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -34,17 +35,23 @@ var residueNames = [...][3]string{
 
 // Residue represents a single residue from the PDB structure.
 type Residue struct {
-	Chain           string  `json:"chain"`
-	StructPosition  int64   `json:"structPosition"`
-	Position        int64   `json:"position"`
-	UnpID           string  `json:"unpId"`
-	UnpPosition     int64   `json:"unpPosition"`
-	Name            string  `json:"-"`
-	Name1           string  `json:"name1"`
-	Name3           string  `json:"-"`
-	Atoms           []*Atom `json:"-"`
-	MeanBFactor     float64 `json:"-"`
-	NormMeanBFactor float64 `json:"-"`
+	Chain           string   `json:"chain"`
+	StructPosition  int64    `json:"structPosition"`
+	InsertionCode   string   `json:"insertionCode"`
+	Position        int64    `json:"position"`
+	UnpID           string   `json:"unpId"`
+	UnpPosition     int64    `json:"unpPosition"`
+	Name            string   `json:"-"`
+	Name1           string   `json:"name1"`
+	Name3           string   `json:"-"`
+	Atoms           []*Atom  `json:"-"`
+	MeanBFactor     float64  `json:"-"`
+	NormMeanBFactor float64  `json:"-"`
+	Modeled         bool     `json:"modeled"`      // observed in the structure, false for unobserved SEQRES residues
+	MissingAtoms    []string `json:"missingAtoms"` // unobserved atoms, from REMARK 470 or the CIF file
+	PLDDT           float64  `json:"plddt"`        // predicted local distance difference test, only for predicted models
+
+	prev, next *Residue // neighbor residues in the chain, bonded or not
 }
 
 // IsAminoacid returns true if the given letter is an aminoacid, false otherwise.
@@ -58,6 +65,7 @@ func IsAminoacid(letter string) bool {
 }
 
 // AminoacidNames receives a name and returns a 3-sized array of all the possible representations as a string.
+// Modified amino acids, such as MSE, return the names of their standard parent.
 func AminoacidNames(input string) (string, string, string) {
 	s := strings.Title(strings.ToLower(input))
 	for _, res := range residueNames {
@@ -68,6 +76,10 @@ func AminoacidNames(input string) (string, string, string) {
 		}
 	}
 
+	if c, ok := LookupComponent(input); ok && c.Type == PolymerProtein && !c.Standard() {
+		return AminoacidNames(c.Parent)
+	}
+
 	return input, "Unk", "X"
 }
 
@@ -89,7 +101,7 @@ func NewResidue(chain string, pos int64, input string) *Residue {
 
 // ExtractSeqRes parses the raw PDB for SEQRES records containing the primary sequence.
 func (pdb *PDB) ExtractSeqRes(rawPDB []byte) error {
-	regex, _ := regexp.Compile("SEQRES[ ]*.*?[ ]+(.*?)[ ]+([0-9]*)[ ]*([A-Z ]*)")
+	regex, _ := regexp.Compile("SEQRES[ ]*.*?[ ]+(.*?)[ ]+([0-9]*)[ ]*([A-Z0-9 ]*)")
 	matches := regex.FindAllStringSubmatch(string(rawPDB), -1)
 	if len(matches) == 0 {
 		return errors.New("SEQRES not found")
@@ -101,7 +113,7 @@ func (pdb *PDB) ExtractSeqRes(rawPDB []byte) error {
 		resSplit := strings.Split(match[3], " ")
 		for i, resStr := range resSplit {
 			if resStr != "" {
-				res := NewResidue(chain, int64(i), resStr)
+				res := newResidueFromCode(chain, int64(i), resStr)
 				pdb.SeqRes[chain] = append(pdb.SeqRes[chain], res)
 			}
 		}
@@ -119,8 +131,7 @@ func (pdb *PDB) ExtractResidues(rawPDB []byte) error {
 
 	hetatms, _ := pdb.extractPDBATMRecords(rawPDB, "HETATM")
 
-	pdb.Atoms = atoms
-	pdb.HetAtoms = hetatms
+	pdb.setModels(filterAltLocs(atoms, pdb.AltLocPolicy), filterAltLocs(hetatms, pdb.AltLocPolicy))
 
 	err = pdb.ExtractPDBChains()
 	if err != nil {
@@ -130,43 +141,183 @@ func (pdb *PDB) ExtractResidues(rawPDB []byte) error {
 	return nil
 }
 
-// ExtractPDBChains parses the residue chains.
+// residueKey identifies a residue by chain, residue number and insertion code.
+type residueKey struct {
+	chain         string
+	number        int64
+	insertionCode string
+}
+
+func atomResidueKey(atom *Atom) residueKey {
+	return residueKey{atom.Chain, atom.ResidueNumber, atom.InsertionCode}
+}
+
+// ExtractPDBChains parses the residue chains of every model.
+// Residues are identified by chain, number and insertion code, so 52 and 52A are different residues.
+// Since Chains is keyed by residue number only, it holds the first residue listed for each number,
+// while Residues holds all of them in file order.
+// The first model atoms are taken from Atoms and HetAtoms, which are the default view of the structure.
 func (pdb *PDB) ExtractPDBChains() error {
-	atoms := pdb.Atoms
-	if len(atoms) == 0 {
+	if len(pdb.Atoms) == 0 {
 		return errors.New("empty atoms list")
 	}
 
+	if len(pdb.Models) == 0 {
+		pdb.Models = []*Model{{Number: pdb.Atoms[0].Model}}
+	}
+	pdb.Models[0].Atoms = pdb.Atoms
+	pdb.Models[0].HetAtoms = pdb.HetAtoms
+
+	for _, model := range pdb.Models {
+		model.extractChains()
+	}
+	pdb.Atoms = pdb.Models[0].Atoms
+	pdb.HetAtoms = pdb.Models[0].HetAtoms
+
+	pdb.Chains = pdb.Models[0].Chains
+	pdb.Residues = pdb.Models[0].Residues
+	pdb.Hets = pdb.Models[0].Hets
+	pdb.TotalLength = 0
+	for _, chain := range pdb.Residues {
+		pdb.TotalLength += int64(len(chain))
+	}
+
+	return nil
+}
+
+// extractChains groups the model atoms into residues.
+func (m *Model) extractChains() {
 	chains := make(map[string]map[int64]*Residue)
+	residues := make(map[string][]*Residue)
+	seen := make(map[residueKey]*Residue)
+
+	for _, atom := range m.Atoms {
+		key := atomResidueKey(atom)
+		res, ok := seen[key]
+		if !ok {
+			res = newResidueFromCode(atom.Chain, atom.ResidueNumber, atom.Residue)
+			res.InsertionCode = atom.InsertionCode
+			res.Modeled = true
+			seen[key] = res
 
-	var res *Residue
-	for _, atom := range atoms {
-		chain, chainOk := chains[atom.Chain]
-		pos, posOk := chain[atom.ResidueNumber]
+			if _, ok := chains[atom.Chain]; !ok {
+				chains[atom.Chain] = make(map[int64]*Residue)
+			}
+			if _, ok := chains[atom.Chain][atom.ResidueNumber]; !ok {
+				chains[atom.Chain][atom.ResidueNumber] = res
+			}
+			residues[atom.Chain] = append(residues[atom.Chain], res)
+		}
+		res.Atoms = append(res.Atoms, atom)
+		atom.residue = res
+	}
 
-		if !chainOk {
-			chains[atom.Chain] = make(map[int64]*Residue)
+	// Modified residues, such as MSE, are listed as HETATM records but belong to the polymer chains
+	for _, atom := range m.HetAtoms {
+		c, ok := LookupComponent(atom.Residue)
+		if !ok || c.Standard() || chains[atom.Chain] == nil {
+			continue
 		}
-		if !posOk {
-			res = NewResidue(atom.Chain, atom.ResidueNumber, atom.Residue)
-			res.Atoms = []*Atom{atom}
+
+		key := atomResidueKey(atom)
+		res, ok := seen[key]
+		if !ok {
+			res = newResidueFromCode(atom.Chain, atom.ResidueNumber, atom.Residue)
+			res.InsertionCode = atom.InsertionCode
+			res.Modeled = true
+			seen[key] = res
+
+			if _, ok := chains[atom.Chain][atom.ResidueNumber]; !ok {
+				chains[atom.Chain][atom.ResidueNumber] = res
+			}
+			residues[atom.Chain] = insertResidue(residues[atom.Chain], res)
+		}
+		res.Atoms = append(res.Atoms, atom)
+		atom.residue, atom.het = res, nil
+	}
+	m.moveResidueAtoms()
+
+	for _, chain := range residues {
+		for i, res := range chain {
 			res.calculateMeanBFactor()
-			chains[atom.Chain][atom.ResidueNumber] = res
+			if i > 0 {
+				res.prev = chain[i-1]
+				chain[i-1].next = res
+			}
+		}
+	}
+
+	m.Chains = chains
+	m.Residues = residues
+	m.calculateNormMeanBFactor()
+	m.extractHets()
+
+	m.Reindex()
+}
+
+// moveResidueAtoms moves the HETATM records of modified residues to the ATOM records of the model, in file order,
+// so they are part of the chains for every function working on Atoms and AtomIndex.
+func (m *Model) moveResidueAtoms() {
+	atoms := append([]*Atom{}, m.Atoms...)
+	var hetatms []*Atom
+	for _, atom := range m.HetAtoms {
+		if atom.residue != nil {
+			atoms = append(atoms, atom)
 		} else {
-			pos.Atoms = append(pos.Atoms, atom)
+			hetatms = append(hetatms, atom)
 		}
 	}
+	if len(hetatms) == len(m.HetAtoms) {
+		return
+	}
 
-	pdb.Chains = chains
-	for _, chain := range pdb.Chains {
-		pdb.TotalLength += int64(len(chain))
+	sort.SliceStable(atoms, func(i, j int) bool {
+		return atoms[i].Number < atoms[j].Number
+	})
+	m.Atoms, m.HetAtoms = atoms, hetatms
+}
+
+// insertResidue inserts the residue before the first one with a higher residue number, keeping the file order otherwise.
+func insertResidue(residues []*Residue, res *Residue) []*Residue {
+	i := len(residues)
+	for j, r := range residues {
+		if r.StructPosition > res.StructPosition {
+			i = j
+			break
+		}
+	}
+	residues = append(residues, nil)
+	copy(residues[i+1:], residues[i:])
+	residues[i] = res
+	return residues
+}
+
+// ResidueAt returns the residue in the given chain, position and insertion code, or nil if not found.
+func (pdb *PDB) ResidueAt(chain string, pos int64, insertionCode string) *Residue {
+	res, ok := pdb.Chains[chain][pos]
+	if ok && res.InsertionCode == insertionCode {
+		return res
 	}
 
-	pdb.calculateNormMeanBFactor()
+	for _, res := range pdb.Residues[chain] {
+		if res.StructPosition == pos && res.InsertionCode == insertionCode {
+			return res
+		}
+	}
 
 	return nil
 }
 
+// Prev returns the previous residue in the chain, bonded or not, or nil at the start of the chain.
+func (r *Residue) Prev() *Residue {
+	return r.prev
+}
+
+// Next returns the next residue in the chain, bonded or not, or nil at the end of the chain.
+func (r *Residue) Next() *Residue {
+	return r.next
+}
+
 // calculateMeanBFactor calculates the mean B-factor for the residue based on all its atoms.
 func (r *Residue) calculateMeanBFactor() {
 	var sum float64
@@ -189,11 +340,11 @@ func stddev(vals []float64, mean float64) float64 {
 }
 
 // calculateNormMeanBFactor calculates the z-score for residues mean B-factors.
-func (pdb *PDB) calculateNormMeanBFactor() {
+func (m *Model) calculateNormMeanBFactor() {
 	var sum float64
 	var n float64
 	var meanBfactors []float64
-	for _, residues := range pdb.Chains {
+	for _, residues := range m.Residues {
 		for _, residue := range residues {
 			sum += residue.MeanBFactor
 			meanBfactors = append(meanBfactors, residue.MeanBFactor)
@@ -203,7 +354,7 @@ func (pdb *PDB) calculateNormMeanBFactor() {
 	mean := sum / n
 	s := stddev(meanBfactors, mean)
 
-	for _, residues := range pdb.Chains {
+	for _, residues := range m.Residues {
 		for _, residue := range residues {
 			residue.NormMeanBFactor = (residue.MeanBFactor - mean) / s
 		}
@@ -0,0 +1,385 @@
+package pdb
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Predicate reports whether an atom is part of a selection.
+type Predicate func(a *Atom) bool
+
+// Selection holds the atoms matched by a predicate, and the residues they belong to.
+// Both slices are ordered as in the file.
+type Selection struct {
+	Atoms    []*Atom
+	Residues []*Residue
+}
+
+// Select returns the atoms of the structure (ATOM and HETATM records) that match the predicate.
+// Only the first model is considered, see ModelView for other models.
+func (pdb *PDB) Select(p Predicate) *Selection {
+	atoms := make([]*Atom, 0, len(pdb.Atoms)+len(pdb.HetAtoms))
+	atoms = append(atoms, pdb.Atoms...)
+	atoms = append(atoms, pdb.HetAtoms...)
+	sort.SliceStable(atoms, func(i, j int) bool {
+		return atoms[i].Number < atoms[j].Number
+	})
+
+	sel := &Selection{}
+	seen := make(map[*Residue]bool)
+	for _, atom := range atoms {
+		if p(atom) {
+			sel.Atoms = append(sel.Atoms, atom)
+			if res := atom.residue; res != nil && !seen[res] {
+				seen[res] = true
+				sel.Residues = append(sel.Residues, res)
+			}
+		}
+	}
+
+	return sel
+}
+
+// SelectQuery parses a selection in text syntax (see Compile) and returns the matched atoms and residues.
+func (pdb *PDB) SelectQuery(query string) (*Selection, error) {
+	p, err := pdb.Compile(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return pdb.Select(p), nil
+}
+
+// All matches every atom.
+func All() Predicate {
+	return func(a *Atom) bool { return true }
+}
+
+// ChainID matches atoms in any of the given chains.
+func ChainID(ids ...string) Predicate {
+	return func(a *Atom) bool {
+		for _, id := range ids {
+			if a.Chain == id {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ResidueRange matches atoms with residue numbers between from and to, inclusive.
+func ResidueRange(from int64, to int64) Predicate {
+	return func(a *Atom) bool {
+		return a.ResidueNumber >= from && a.ResidueNumber <= to
+	}
+}
+
+// ResidueName matches atoms in residues with any of the given names (i.e. HOH), case-insensitive.
+func ResidueName(names ...string) Predicate {
+	return func(a *Atom) bool {
+		for _, n := range names {
+			if strings.EqualFold(a.Residue, n) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AtomName matches atoms with any of the given names (i.e. CA), case-insensitive.
+func AtomName(names ...string) Predicate {
+	return func(a *Atom) bool {
+		for _, n := range names {
+			if strings.EqualFold(a.Name, n) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Element matches atoms of any of the given elements, case-insensitive.
+func Element(elements ...string) Predicate {
+	return func(a *Atom) bool {
+		for _, e := range elements {
+			if strings.EqualFold(a.Element, e) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Het matches atoms from HETATM records.
+func Het() Predicate {
+	return func(a *Atom) bool { return a.Het }
+}
+
+// Water matches atoms from water molecules.
+func Water() Predicate {
+	return ResidueName("HOH", "WAT", "DOD", "H2O")
+}
+
+// Protein matches atoms from aminoacid residues.
+func Protein() Predicate {
+	return func(a *Atom) bool {
+		_, _, abbrv1 := AminoacidNames(a.Residue)
+		return abbrv1 != "X"
+	}
+}
+
+// Backbone matches protein backbone atoms.
+func Backbone() Predicate {
+	return And(Protein(), AtomName("N", "CA", "C", "O"))
+}
+
+// Within matches atoms that are within the given distance of any of the atoms.
+// The atoms are indexed once, when the predicate is created.
+func Within(distance float64, atoms []*Atom) Predicate {
+	idx := NewIndex(atoms, math.Max(distance, defaultCellSize))
+	return func(a *Atom) bool {
+		return len(idx.WithinAtom(a, distance)) > 0
+	}
+}
+
+// And matches atoms that match all the predicates.
+func And(predicates ...Predicate) Predicate {
+	return func(a *Atom) bool {
+		for _, p := range predicates {
+			if !p(a) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or matches atoms that match any of the predicates.
+func Or(predicates ...Predicate) Predicate {
+	return func(a *Atom) bool {
+		for _, p := range predicates {
+			if p(a) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not matches atoms that do not match the predicate.
+func Not(p Predicate) Predicate {
+	return func(a *Atom) bool { return !p(a) }
+}
+
+// Compile parses a selection in a text syntax similar to PyMOL and VMD into a predicate, i.e.:
+//
+//	chain A and resi 10-50 and not water
+//	resn HEM or (within 5 of resn HEM and protein)
+//
+// Keywords are all, none, chain, resi, resn, name, elem, het, water, protein, backbone, within N of,
+// and, or, not and parentheses. Values can be separated by spaces, commas or plus signs.
+// Since within needs the atoms of the inner selection, the query is compiled against the structure.
+func (pdb *PDB) Compile(query string) (Predicate, error) {
+	c := &selectionCompiler{pdb: pdb, tokens: tokenizeSelection(query)}
+	if len(c.tokens) == 0 {
+		return nil, errors.New("empty selection")
+	}
+
+	p, err := c.or()
+	if err != nil {
+		return nil, err
+	}
+	if c.pos < len(c.tokens) {
+		return nil, fmt.Errorf("unexpected %q in selection", c.tokens[c.pos])
+	}
+
+	return p, nil
+}
+
+var selectionKeywords = map[string]bool{
+	"and": true, "or": true, "not": true, "(": true, ")": true,
+	"all": true, "none": true, "chain": true, "resi": true, "resn": true, "name": true, "elem": true,
+	"het": true, "water": true, "protein": true, "backbone": true, "within": true, "of": true,
+}
+
+func tokenizeSelection(query string) []string {
+	query = strings.NewReplacer("(", " ( ", ")", " ) ", ",", " ", "+", " ").Replace(query)
+	return strings.Fields(query)
+}
+
+type selectionCompiler struct {
+	pdb    *PDB
+	tokens []string
+	pos    int
+}
+
+func (c *selectionCompiler) peek() string {
+	if c.pos < len(c.tokens) {
+		return strings.ToLower(c.tokens[c.pos])
+	}
+	return ""
+}
+
+func (c *selectionCompiler) or() (Predicate, error) {
+	p, err := c.and()
+	if err != nil {
+		return nil, err
+	}
+
+	ps := []Predicate{p}
+	for c.peek() == "or" {
+		c.pos++
+		p, err := c.and()
+		if err != nil {
+			return nil, err
+		}
+		ps = append(ps, p)
+	}
+
+	if len(ps) == 1 {
+		return p, nil
+	}
+	return Or(ps...), nil
+}
+
+func (c *selectionCompiler) and() (Predicate, error) {
+	p, err := c.not()
+	if err != nil {
+		return nil, err
+	}
+
+	ps := []Predicate{p}
+	for c.peek() == "and" {
+		c.pos++
+		p, err := c.not()
+		if err != nil {
+			return nil, err
+		}
+		ps = append(ps, p)
+	}
+
+	if len(ps) == 1 {
+		return p, nil
+	}
+	return And(ps...), nil
+}
+
+func (c *selectionCompiler) not() (Predicate, error) {
+	if c.peek() == "not" {
+		c.pos++
+		p, err := c.not()
+		if err != nil {
+			return nil, err
+		}
+		return Not(p), nil
+	}
+
+	return c.primary()
+}
+
+// values consumes all the tokens until the next keyword.
+func (c *selectionCompiler) values(keyword string) ([]string, error) {
+	var values []string
+	for c.pos < len(c.tokens) && !selectionKeywords[c.peek()] {
+		values = append(values, c.tokens[c.pos])
+		c.pos++
+	}
+
+	if len(values) == 0 {
+		return nil, fmt.Errorf("missing values for %s", keyword)
+	}
+	return values, nil
+}
+
+var selectionRange = regexp.MustCompile(`^(-?[0-9]+)(?:[-:](-?[0-9]+))?$`)
+
+func (c *selectionCompiler) primary() (Predicate, error) {
+	keyword := c.peek()
+	if keyword == "" {
+		return nil, errors.New("unexpected end of selection")
+	}
+	c.pos++
+
+	switch keyword {
+	case "(":
+		p, err := c.or()
+		if err != nil {
+			return nil, err
+		}
+		if c.peek() != ")" {
+			return nil, errors.New("missing closing parenthesis")
+		}
+		c.pos++
+		return p, nil
+	case "all":
+		return All(), nil
+	case "none":
+		return Not(All()), nil
+	case "het":
+		return Het(), nil
+	case "water":
+		return Water(), nil
+	case "protein":
+		return Protein(), nil
+	case "backbone":
+		return Backbone(), nil
+	case "within":
+		if c.pos >= len(c.tokens) {
+			return nil, errors.New("missing distance for within")
+		}
+		distance, err := strconv.ParseFloat(c.tokens[c.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid distance for within: %s", c.tokens[c.pos])
+		}
+		c.pos++
+		if c.peek() != "of" {
+			return nil, errors.New("expected of after within distance")
+		}
+		c.pos++
+		inner, err := c.not()
+		if err != nil {
+			return nil, err
+		}
+		return Within(distance, c.pdb.Select(inner).Atoms), nil
+	case "chain", "resn", "name", "elem", "resi":
+	default:
+		return nil, fmt.Errorf("unexpected %q in selection", c.tokens[c.pos-1])
+	}
+
+	values, err := c.values(keyword)
+	if err != nil {
+		return nil, err
+	}
+
+	switch keyword {
+	case "chain":
+		return ChainID(values...), nil
+	case "name":
+		return AtomName(values...), nil
+	case "elem":
+		return Element(values...), nil
+	case "resi":
+		var ranges []Predicate
+		for _, v := range values {
+			m := selectionRange.FindStringSubmatch(v)
+			if m == nil {
+				return nil, fmt.Errorf("invalid residue range %s", v)
+			}
+			from, _ := strconv.ParseInt(m[1], 10, 64)
+			to := from
+			if m[2] != "" {
+				to, _ = strconv.ParseInt(m[2], 10, 64)
+			}
+			ranges = append(ranges, ResidueRange(from, to))
+		}
+		return Or(ranges...), nil
+	}
+
+	// resn
+	return ResidueName(values...), nil
+}
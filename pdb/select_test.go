@@ -0,0 +1,90 @@
+package pdb
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSelect(t *testing.T) {
+	raw, err := LoadTestFile("./testdata/1mso.pdb")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+
+	pdb, err := NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sel := pdb.Select(And(ChainID("B"), ResidueRange(1, 10), AtomName("CA")))
+	if len(sel.Atoms) != 10 || len(sel.Residues) != 10 {
+		t.Errorf("expected 10 CA atoms, got %d", len(sel.Atoms))
+	}
+	for i, res := range sel.Residues {
+		if res != pdb.Chains["B"][int64(i+1)] {
+			t.Errorf("expected residues in order, got B-%d at %d", res.StructPosition, i)
+		}
+	}
+
+	sel, err = pdb.SelectQuery("chain A and resi 1-5+21 and not elem H")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sel.Residues) != 6 {
+		t.Errorf("expected 6 residues, got %d", len(sel.Residues))
+	}
+	for _, atom := range sel.Atoms {
+		if atom.Element == "H" || atom.Chain != "A" {
+			t.Errorf("unexpected atom %s in chain %s", atom.Name, atom.Chain)
+		}
+	}
+
+	sel, err = pdb.SelectQuery("protein and within 3 of resn ZN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, res := range sel.Residues {
+		if res.Name3 != "His" {
+			t.Errorf("expected only histidines near zinc, got %s", res.Name3)
+		}
+	}
+	if len(sel.Residues) == 0 {
+		t.Errorf("expected residues near zinc")
+	}
+
+	// Indexed within selection against every pair of atoms
+	all, _ := pdb.SelectQuery("all")
+	zinc, _ := pdb.SelectQuery("resn ZN")
+	for _, distance := range []float64{0, 3, 12.5} {
+		var expected int
+		for _, a := range all.Atoms {
+			for _, zn := range zinc.Atoms {
+				if AtomsDistance(a, zn) <= distance {
+					expected++
+					break
+				}
+			}
+		}
+		sel, err = pdb.SelectQuery(fmt.Sprintf("within %g of resn ZN", distance))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sel.Atoms) != expected {
+			t.Errorf("within %g: expected %d atoms, got %d", distance, expected, len(sel.Atoms))
+		}
+	}
+
+	sel, err = pdb.SelectQuery("water or het")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sel.Atoms) != len(pdb.HetAtoms) || len(sel.Residues) != 0 {
+		t.Errorf("expected %d het atoms, got %d", len(pdb.HetAtoms), len(sel.Atoms))
+	}
+
+	for _, q := range []string{"chain", "resi a-b", "(chain A", "within x of all", "foo A"} {
+		if _, err := pdb.Compile(q); err == nil {
+			t.Errorf("expected error for %q", q)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package pdb
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/tikz/bio/fasta"
+)
+
+// SeqResSequence returns the one letter SEQRES sequence of a chain, with modified residues as their parent
+// and X for unknown components, or an empty string if SEQRES is not available.
+func (pdb *PDB) SeqResSequence(chain string) string {
+	return sequence(pdb.SeqRes[chain])
+}
+
+// ModeledSequence returns the one letter sequence of the modeled residues of a chain, in file order.
+func (pdb *PDB) ModeledSequence(chain string) string {
+	return sequence(pdb.Residues[chain])
+}
+
+// ObservedSequence returns the one letter SEQRES sequence of a chain with a gap (-) for each residue that is not
+// modeled in the structure, so that it aligns position by position with SeqResSequence. If SEQRES is not available,
+// gaps are inferred from breaks in the residue numbering of the modeled residues.
+func (pdb *PDB) ObservedSequence(chain string) string {
+	var b strings.Builder
+
+	seqRes := pdb.SeqRes[chain]
+	if len(seqRes) > 0 {
+		if pdb.SeqResChains == nil {
+			pdb.makeMappings()
+		}
+		for _, res := range seqRes {
+			if res.Modeled {
+				b.WriteString(res.Name1)
+			} else {
+				b.WriteString("-")
+			}
+		}
+		return b.String()
+	}
+
+	for i, res := range pdb.Residues[chain] {
+		if i > 0 {
+			if gap := res.StructPosition - pdb.Residues[chain][i-1].StructPosition - 1; gap > 0 {
+				b.WriteString(strings.Repeat("-", int(gap)))
+			}
+		}
+		b.WriteString(res.Name1)
+	}
+	return b.String()
+}
+
+// FASTA returns a record for each polymer chain in the structure, ordered by chain ID, with identifiers as in the
+// PDB seqres.txt distribution (i.e. 1mso_A). Sequences are the SEQRES ones, or the modeled ones if not available,
+// unless observed is set, in which case ObservedSequence is used.
+func (pdb *PDB) FASTA(observed bool) []*fasta.Record {
+	var records []*fasta.Record
+	for _, chain := range pdb.chainIDs() {
+		t := pdb.ChainType(chain)
+		if t == PolymerNone {
+			continue
+		}
+
+		var seq string
+		switch {
+		case observed:
+			seq = pdb.ObservedSequence(chain)
+		case len(pdb.SeqRes[chain]) > 0:
+			seq = pdb.SeqResSequence(chain)
+		default:
+			seq = pdb.ModeledSequence(chain)
+		}
+
+		records = append(records, &fasta.Record{
+			ID:          fmt.Sprintf("%s_%s", strings.ToLower(pdb.ID), chain),
+			Description: fmt.Sprintf("mol:%s length:%d", strings.ToLower(t.String()), len(seq)),
+			Sequence:    seq,
+		})
+	}
+	return records
+}
+
+// WriteFASTA writes the chain sequences of the structure in FASTA format, see FASTA.
+func (pdb *PDB) WriteFASTA(w io.Writer, observed bool) error {
+	return fasta.Write(w, pdb.FASTA(observed)...)
+}
+
+// chainIDs returns the sorted IDs of the chains with SEQRES or modeled residues.
+func (pdb *PDB) chainIDs() []string {
+	seen := make(map[string]bool)
+	var chains []string
+	for _, m := range []map[string][]*Residue{pdb.SeqRes, pdb.Residues} {
+		for chain := range m {
+			if !seen[chain] {
+				seen[chain] = true
+				chains = append(chains, chain)
+			}
+		}
+	}
+	sort.Strings(chains)
+	return chains
+}
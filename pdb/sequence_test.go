@@ -0,0 +1,92 @@
+package pdb
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/tikz/bio/fasta"
+)
+
+func TestSequences(t *testing.T) {
+	raw, err := LoadTestFile("./testdata/1mso.pdb")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+
+	// Remove GLY A 1 and annotate it as unobserved, and GLY B 8 without annotation
+	r := regexp.MustCompile("(?m)^(ATOM  |ANISOU).{11}GLY (A   1|B   8) .*\n")
+	edited := r.ReplaceAllString(string(raw), "")
+	remark := "REMARK 465   M RES C SSSEQI\nREMARK 465     GLY A     1\n"
+	edited = strings.Replace(edited, "REMARK 470   ", remark+"REMARK 470   ", 1)
+
+	pdb, err := NewPDBFromRaw([]byte(edited))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdb.ID = "1MSO"
+
+	// Without SEQRES, gaps come from the residue numbering
+	if s := pdb.ObservedSequence("B"); s != "FVNQHLC-SHLVEALYLVCGERGFFYTPKT" {
+		t.Errorf("unexpected observed sequence without SEQRES %s", s)
+	}
+	if s := pdb.ObservedSequence("A"); s != "IVEQCCTSICSLYQLENYCN" {
+		t.Errorf("unexpected observed sequence without SEQRES %s", s)
+	}
+
+	if err := pdb.ExtractSeqRes([]byte(edited)); err != nil {
+		t.Fatal(err)
+	}
+	if err := pdb.ExtractMissing([]byte(edited)); err != nil {
+		t.Fatal(err)
+	}
+
+	a := "GIVEQCCTSICSLYQLENYCN"
+	b := "FVNQHLCGSHLVEALYLVCGERGFFYTPKT"
+	if s := pdb.SeqResSequence("A"); s != a {
+		t.Errorf("unexpected SEQRES sequence %s", s)
+	}
+	if s := pdb.ModeledSequence("A"); s != a[1:] {
+		t.Errorf("unexpected modeled sequence %s", s)
+	}
+	if s := pdb.ObservedSequence("A"); s != "-"+a[1:] {
+		t.Errorf("unexpected observed sequence %s", s)
+	}
+
+	if s := pdb.ObservedSequence("B"); s != b[:7]+"-"+b[8:] {
+		t.Errorf("unexpected observed sequence %s", s)
+	}
+
+	var buf bytes.Buffer
+	if err := pdb.WriteFASTA(&buf, false); err != nil {
+		t.Fatal(err)
+	}
+	records, err := fasta.Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*fasta.Record{
+		{ID: "1mso_A", Description: "mol:protein length:21", Sequence: a},
+		{ID: "1mso_B", Description: "mol:protein length:30", Sequence: b},
+		{ID: "1mso_C", Description: "mol:protein length:21", Sequence: a},
+		{ID: "1mso_D", Description: "mol:protein length:30", Sequence: b},
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("expected %d records, got %d", len(expected), len(records))
+	}
+	for i, r := range records {
+		if *r != *expected[i] {
+			t.Errorf("unexpected record %+v", r)
+		}
+	}
+
+	for _, r := range pdb.FASTA(true) {
+		if r.ID == "1mso_A" && r.Sequence != "-"+a[1:] {
+			t.Errorf("unexpected observed record %+v", r)
+		}
+		if len(r.Sequence) != len(pdb.SeqRes[r.ID[5:]]) {
+			t.Errorf("observed sequence %s not aligned to SEQRES", r.ID)
+		}
+	}
+}
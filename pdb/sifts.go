@@ -15,6 +15,8 @@ import (
 type SIFTS struct {
 	Pfam    map[string]*Family    `json:"Pfam"`
 	UniProt map[string]*Accession `json:"UniProt"`
+
+	Residues []*SIFTSResidue `json:"-"` // residue level mappings, only available when loaded from the SIFTS XML files
 }
 
 // Family represents a Pfam family.
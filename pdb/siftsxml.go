@@ -0,0 +1,211 @@
+package pdb
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Reference: https://www.ebi.ac.uk/pdbe/docs/sifts/quick.html
+
+// SIFTSResidue is the mapping of a single SEQRES residue to other databases, from the SIFTS XML files.
+type SIFTSResidue struct {
+	Chain           string   `json:"chain"`         // author chain ID
+	LabelAsymID     string   `json:"labelAsymId"`   // mmCIF label chain ID (SIFTS entity)
+	LabelSeqID      int64    `json:"labelSeqId"`    // SEQRES position
+	AuthSeqID       int64    `json:"authSeqId"`     // author residue number, if observed
+	InsertionCode   string   `json:"insertionCode"` // author insertion code, if observed
+	Name            string   `json:"name"`          // three letter residue name
+	Observed        bool     `json:"observed"`      // whether the residue is modeled in the structure
+	UniProt         string   `json:"uniprot"`       // UniProt accession, if mapped
+	UniProtPosition int64    `json:"uniprotPosition"`
+	Pfam            []string `json:"pfam"`
+	CATH            []string `json:"cath"`
+	SCOP            []string `json:"scop"`
+	InterPro        []string `json:"interpro"`
+}
+
+type siftsEntry struct {
+	Entities []struct {
+		ID       string `xml:"entityId,attr"`
+		Segments []struct {
+			Residues []struct {
+				Number    string `xml:"dbResNum,attr"`
+				Name      string `xml:"dbResName,attr"`
+				CrossRefs []struct {
+					Source    string `xml:"dbSource,attr"`
+					Accession string `xml:"dbAccessionId,attr"`
+					Number    string `xml:"dbResNum,attr"`
+					Chain     string `xml:"dbChainId,attr"`
+				} `xml:"crossRefDb"`
+				Details []struct {
+					Property string `xml:"property,attr"`
+					Value    string `xml:",chardata"`
+				} `xml:"residueDetail"`
+			} `xml:"listResidue>residue"`
+		} `xml:"segment"`
+	} `xml:"entity"`
+}
+
+// ParseSIFTSXML parses the residue level mappings from a per-entry SIFTS XML file, optionally gzipped.
+func ParseSIFTSXML(raw []byte) ([]*SIFTSResidue, error) {
+	raw, err := gunzip(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry siftsEntry
+	err = xml.Unmarshal(raw, &entry)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal: %v", err)
+	}
+
+	var residues []*SIFTSResidue
+	for _, entity := range entry.Entities {
+		for _, segment := range entity.Segments {
+			for _, r := range segment.Residues {
+				labelSeqID, err := strconv.ParseInt(r.Number, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid residue number %s in entity %s", r.Number, entity.ID)
+				}
+				res := &SIFTSResidue{LabelAsymID: entity.ID, LabelSeqID: labelSeqID, Name: r.Name, Observed: true}
+
+				for _, x := range r.CrossRefs {
+					switch x.Source {
+					case "PDB":
+						res.Chain = x.Chain
+						number, insertionCode, ok := splitResidueNumber(x.Number)
+						if ok {
+							res.AuthSeqID, res.InsertionCode = number, insertionCode
+						} else {
+							res.Observed = false // dbResNum="null"
+						}
+					case "UniProt":
+						res.UniProt = x.Accession
+						res.UniProtPosition, _ = strconv.ParseInt(x.Number, 10, 64)
+					case "Pfam":
+						res.Pfam = appendUnique(res.Pfam, x.Accession)
+					case "CATH":
+						res.CATH = appendUnique(res.CATH, x.Accession)
+					case "SCOP":
+						res.SCOP = appendUnique(res.SCOP, x.Accession)
+					case "InterPro":
+						res.InterPro = appendUnique(res.InterPro, x.Accession)
+					}
+				}
+				for _, d := range r.Details {
+					if d.Property == "Annotation" && strings.TrimSpace(d.Value) == "Not_Observed" {
+						res.Observed = false
+					}
+				}
+
+				residues = append(residues, res)
+			}
+		}
+	}
+
+	return residues, nil
+}
+
+// LoadSIFTSXML loads the residue level mappings of the entry from the SIFTS XML file in the given directory,
+// named as in the EBI distribution (i.e. 1mso.xml.gz) or uncompressed, and replaces any previous SIFTS data.
+func (pdb *PDB) LoadSIFTSXML(dir string) error {
+	name := strings.ToLower(pdb.ID) + ".xml"
+	var raw []byte
+	var err error
+	for _, path := range []string{filepath.Join(dir, name+".gz"), filepath.Join(dir, name)} {
+		raw, err = ioutil.ReadFile(path)
+		if err == nil || !os.IsNotExist(err) {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("read SIFTS XML: %v", err)
+	}
+
+	residues, err := ParseSIFTSXML(raw)
+	if err != nil {
+		return err
+	}
+	if len(residues) == 0 {
+		return errors.New("no residues in SIFTS XML")
+	}
+
+	pdb.SIFTS = newSIFTSFromResidues(residues)
+	return nil
+}
+
+// newSIFTSFromResidues builds the segment level mappings from the residue level ones, splitting segments
+// wherever the SEQRES or database numbering is not consecutive.
+func newSIFTSFromResidues(residues []*SIFTSResidue) *SIFTS {
+	sifts := &SIFTS{
+		Pfam:     make(map[string]*Family),
+		UniProt:  make(map[string]*Accession),
+		Residues: residues,
+	}
+
+	type segment struct {
+		mapping *Mapping
+		last    *SIFTSResidue
+	}
+	extend := func(open map[string]*segment, accession string, r *SIFTSResidue) *Mapping {
+		key := accession + "/" + r.LabelAsymID
+		s, ok := open[key]
+		if ok && s.last.LabelSeqID+1 == r.LabelSeqID && s.mapping.UnpEnd+1 == r.UniProtPosition {
+			s.mapping.PDBEnd.ResidueNumber = r.LabelSeqID
+			s.mapping.UnpEnd = r.UniProtPosition
+			s.last = r
+			return nil
+		}
+		m := &Mapping{
+			PDBStart:     &Position{ResidueNumber: r.LabelSeqID},
+			PDBEnd:       &Position{ResidueNumber: r.LabelSeqID},
+			UnpStart:     r.UniProtPosition,
+			UnpEnd:       r.UniProtPosition,
+			ChainID:      r.Chain,
+			StructAsymID: r.LabelAsymID,
+		}
+		open[key] = &segment{mapping: m, last: r}
+		return m
+	}
+
+	unpSegments := make(map[string]*segment)
+	pfamSegments := make(map[string]*segment)
+	for _, r := range residues {
+		if r.UniProt == "" {
+			continue
+		}
+		if _, ok := sifts.UniProt[r.UniProt]; !ok {
+			sifts.UniProt[r.UniProt] = &Accession{Identifier: r.UniProt}
+		}
+		if m := extend(unpSegments, r.UniProt, r); m != nil {
+			sifts.UniProt[r.UniProt].Mappings = append(sifts.UniProt[r.UniProt].Mappings, m)
+		}
+
+		// Pfam positions are in UniProt coordinates
+		for _, pfam := range r.Pfam {
+			if _, ok := sifts.Pfam[pfam]; !ok {
+				sifts.Pfam[pfam] = &Family{Identifier: pfam}
+			}
+			if m := extend(pfamSegments, pfam, r); m != nil {
+				sifts.Pfam[pfam].Mappings = append(sifts.Pfam[pfam].Mappings, m)
+			}
+		}
+	}
+
+	return sifts
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, e := range list {
+		if e == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
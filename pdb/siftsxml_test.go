@@ -0,0 +1,107 @@
+package pdb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"reflect"
+	"testing"
+)
+
+func TestParseSIFTSXML(t *testing.T) {
+	raw, err := LoadTestFile("./testdata/sifts/1mso.xml")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+	residues, err := ParseSIFTSXML(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(residues) != 102 {
+		t.Fatalf("expected 102 residues, got %d", len(residues))
+	}
+
+	r := residues[0]
+	expected := &SIFTSResidue{
+		Chain: "A", LabelAsymID: "A", LabelSeqID: 1, AuthSeqID: 1, Name: "GLY", Observed: true,
+		UniProt: "P01308", UniProtPosition: 90,
+		Pfam: []string{"PF00049"}, CATH: []string{"1.10.100.10"}, SCOP: []string{"16348"}, InterPro: []string{"IPR004825"},
+	}
+	if !reflect.DeepEqual(r, expected) {
+		t.Errorf("unexpected first residue %+v", r)
+	}
+
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write(raw)
+	w.Close()
+	fromGzip, err := ParseSIFTSXML(gz.Bytes())
+	if err != nil || len(fromGzip) != len(residues) {
+		t.Errorf("gzipped file parsed %d residues: %v", len(fromGzip), err)
+	}
+
+	unobserved := []byte(`<entry xmlns="http://www.ebi.ac.uk/pdbe/docs/sifts/eFamily.xsd"><entity type="protein" entityId="B">
+	<segment><listResidue>
+	<residue dbSource="PDBe" dbCoordSys="PDBe" dbResNum="3" dbResName="ASN">
+		<crossRefDb dbSource="PDB" dbCoordSys="PDBresnum" dbAccessionId="1abc" dbResNum="null" dbResName="ASN" dbChainId="A"/>
+		<crossRefDb dbSource="UniProt" dbCoordSys="UniProt" dbAccessionId="P01308" dbResNum="27" dbResName="N"/>
+		<residueDetail dbSource="PDBe" property="Annotation">Not_Observed</residueDetail>
+	</residue>
+	<residue dbSource="PDBe" dbCoordSys="PDBe" dbResNum="4" dbResName="GLN">
+		<crossRefDb dbSource="PDB" dbCoordSys="PDBresnum" dbAccessionId="1abc" dbResNum="52A" dbResName="GLN" dbChainId="A"/>
+	</residue>
+	</listResidue></segment></entity></entry>`)
+	residues, err = ParseSIFTSXML(unobserved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := residues[0]; r.Observed || r.Chain != "A" || r.LabelAsymID != "B" || r.LabelSeqID != 3 || r.UniProtPosition != 27 {
+		t.Errorf("unexpected unobserved residue %+v", r)
+	}
+	if r := residues[1]; !r.Observed || r.AuthSeqID != 52 || r.InsertionCode != "A" || r.UniProt != "" {
+		t.Errorf("unexpected residue with insertion code %+v", r)
+	}
+}
+
+func TestLoadSIFTSXML(t *testing.T) {
+	raw, err := LoadTestFile("./testdata/1mso.pdb")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+	pdb, err := NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pdb.ExtractSeqRes(raw); err != nil {
+		t.Fatal(err)
+	}
+	pdb.ID = "1MSO"
+	if err := pdb.LoadSIFTSXML("./testdata/sifts"); err != nil {
+		t.Fatal(err)
+	}
+	pdb.makeMappings()
+
+	m, err := pdb.SIFTS.GetChainMapping("P01308", "B")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.UnpStart != 25 || m.UnpEnd != 54 || m.PDBStart.ResidueNumber != 1 || m.PDBEnd.ResidueNumber != 30 {
+		t.Errorf("unexpected chain B segment %d-%d to %d-%d", m.PDBStart.ResidueNumber, m.PDBEnd.ResidueNumber, m.UnpStart, m.UnpEnd)
+	}
+	if len(pdb.SIFTS.Pfam["PF00049"].Mappings) != 4 {
+		t.Errorf("expected 4 Pfam segments, got %d", len(pdb.SIFTS.Pfam["PF00049"].Mappings))
+	}
+
+	for _, c := range []struct {
+		chain       string
+		number      int64
+		unpPosition int64
+	}{{"A", 1, 90}, {"C", 21, 110}, {"B", 1, 25}, {"D", 30, 54}} {
+		res := pdb.Chains[c.chain][c.number]
+		if res.UnpID != "P01308" || res.UnpPosition != c.unpPosition {
+			t.Errorf("%s %d mapped to UniProt %d, expected %d", c.chain, c.number, res.UnpPosition, c.unpPosition)
+		}
+	}
+	if n := len(pdb.UniProtPositions["P01308"][25]); n != 2 {
+		t.Errorf("expected chains B and D at UniProt 25, got %d residues", n)
+	}
+}
@@ -19,8 +19,9 @@ func (pdb *PDB) extractSites(rawPDB []byte) error {
 			if residueName != "   " {
 				residueName = strings.TrimSpace(residueName)
 				chain := strings.TrimSpace(string(s[i+4]))
-				pos, _ := strconv.ParseInt(strings.TrimSpace(s[i+5:i+10]), 10, 64)
-				if res, ok := pdb.Chains[chain][pos]; ok {
+				pos, _ := strconv.ParseInt(strings.TrimSpace(s[i+5:i+9]), 10, 64)
+				insertionCode := strings.TrimSpace(string(s[i+9]))
+				if res := pdb.ResidueAt(chain, pos, insertionCode); res != nil {
 					if strings.ToUpper(res.Name3) == residueName {
 						sites[siteName] = append(sites[siteName], res)
 					}
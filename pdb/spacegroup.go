@@ -0,0 +1,296 @@
+package pdb
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Lattice centering translations, added to each operator of the primitive part.
+var centerings = map[byte][]string{
+	'P': {"x,y,z"},
+	'C': {"x,y,z", "x+1/2,y+1/2,z"},
+	'I': {"x,y,z", "x+1/2,y+1/2,z+1/2"},
+	'F': {"x,y,z", "x,y+1/2,z+1/2", "x+1/2,y,z+1/2", "x+1/2,y+1/2,z"},
+	'H': {"x,y,z", "x+2/3,y+1/3,z+1/3", "x+1/3,y+2/3,z+2/3"},
+	'R': {"x,y,z"},
+}
+
+var (
+	p222   = []string{"x,y,z", "-x,-y,z", "-x,y,-z", "x,-y,-z"}
+	p4     = []string{"x,y,z", "-x,-y,z", "-y,x,z", "y,-x,z"}
+	p3     = []string{"x,y,z", "-y,x-y,z", "-x+y,-x,z"}
+	p6     = extend(p3, "-x,-y,z", "y,-x+y,z", "x-y,x,z")
+	p23    = extend(p222, "z,x,y", "z,-x,-y", "-z,-x,y", "-z,x,-y", "y,z,x", "-y,z,-x", "y,-z,-x", "-y,-z,x")
+	p213   = []string{"x,y,z", "-x+1/2,-y,z+1/2", "-x,y+1/2,-z+1/2", "x+1/2,-y+1/2,-z", "z,x,y", "z+1/2,-x+1/2,-y", "-z+1/2,-x,y+1/2", "-z,x+1/2,-y+1/2", "y,z,x", "-y,z+1/2,-x+1/2", "y+1/2,-z+1/2,-x", "-y+1/2,-z,x+1/2"}
+	p432   = extend(p23, "y,x,-z", "-y,-x,-z", "y,-x,z", "-y,x,z", "x,z,-y", "-x,z,y", "-x,-z,-y", "x,-z,y", "z,y,-x", "z,-y,x", "-z,y,x", "-z,-y,-x")
+	p41_32 = extend(p213, "y+3/4,x+1/4,-z+1/4", "-y+3/4,-x+3/4,-z+3/4", "y+1/4,-x+1/4,z+3/4", "-y+1/4,x+3/4,z+1/4", "x+3/4,z+1/4,-y+1/4", "-x+1/4,z+3/4,y+1/4", "-x+3/4,-z+3/4,-y+3/4", "x+1/4,-z+1/4,y+3/4", "z+3/4,y+1/4,-x+1/4", "z+1/4,-y+1/4,x+3/4", "-z+1/4,y+3/4,x+1/4", "-z+3/4,-y+3/4,-x+3/4")
+)
+
+// extend returns a new list of operators with the given ones added.
+func extend(ops []string, more ...string) []string {
+	return append(append([]string{}, ops...), more...)
+}
+
+// spaceGroups are the primitive part of the operators of the 65 space groups allowed for chiral molecules,
+// by their compact Hermann-Mauguin symbol. Centered groups get the lattice translations from the first letter.
+// H groups are rhombohedral groups in the hexagonal setting, as used by the PDB.
+var spaceGroups = map[string][]string{
+	// Triclinic and monoclinic
+	"P1":  {"x,y,z"},
+	"P2":  {"x,y,z", "-x,y,-z"},
+	"P21": {"x,y,z", "-x,y+1/2,-z"},
+	"C2":  {"x,y,z", "-x,y,-z"},
+
+	// Orthorhombic
+	"P222":    p222,
+	"P2221":   {"x,y,z", "-x,-y,z+1/2", "-x,y,-z+1/2", "x,-y,-z"},
+	"P21212":  {"x,y,z", "-x,-y,z", "-x+1/2,y+1/2,-z", "x+1/2,-y+1/2,-z"},
+	"P212121": {"x,y,z", "-x+1/2,-y,z+1/2", "-x,y+1/2,-z+1/2", "x+1/2,-y+1/2,-z"},
+	"C2221":   {"x,y,z", "-x,-y,z+1/2", "-x,y,-z+1/2", "x,-y,-z"},
+	"C222":    p222,
+	"F222":    p222,
+	"I222":    p222,
+	"I212121": {"x,y,z", "-x+1/2,-y,z+1/2", "-x,y+1/2,-z+1/2", "x+1/2,-y+1/2,-z"},
+
+	// Tetragonal
+	"P4":     p4,
+	"P41":    {"x,y,z", "-x,-y,z+1/2", "-y,x,z+1/4", "y,-x,z+3/4"},
+	"P42":    {"x,y,z", "-x,-y,z", "-y,x,z+1/2", "y,-x,z+1/2"},
+	"P43":    {"x,y,z", "-x,-y,z+1/2", "-y,x,z+3/4", "y,-x,z+1/4"},
+	"I4":     p4,
+	"I41":    {"x,y,z", "-x+1/2,-y+1/2,z+1/2", "-y,x+1/2,z+1/4", "y+1/2,-x,z+3/4"},
+	"P422":   extend(p4, "-x,y,-z", "x,-y,-z", "y,x,-z", "-y,-x,-z"),
+	"P4212":  {"x,y,z", "-x,-y,z", "-y+1/2,x+1/2,z", "y+1/2,-x+1/2,z", "-x+1/2,y+1/2,-z", "x+1/2,-y+1/2,-z", "y,x,-z", "-y,-x,-z"},
+	"P4122":  {"x,y,z", "-x,-y,z+1/2", "-y,x,z+1/4", "y,-x,z+3/4", "-x,y,-z", "x,-y,-z+1/2", "y,x,-z+3/4", "-y,-x,-z+1/4"},
+	"P41212": {"x,y,z", "-x,-y,z+1/2", "-y+1/2,x+1/2,z+1/4", "y+1/2,-x+1/2,z+3/4", "-x+1/2,y+1/2,-z+1/4", "x+1/2,-y+1/2,-z+3/4", "y,x,-z", "-y,-x,-z+1/2"},
+	"P4222":  {"x,y,z", "-x,-y,z", "-y,x,z+1/2", "y,-x,z+1/2", "-x,y,-z", "x,-y,-z", "y,x,-z+1/2", "-y,-x,-z+1/2"},
+	"P42212": {"x,y,z", "-x,-y,z", "-y+1/2,x+1/2,z+1/2", "y+1/2,-x+1/2,z+1/2", "-x+1/2,y+1/2,-z+1/2", "x+1/2,-y+1/2,-z+1/2", "y,x,-z", "-y,-x,-z"},
+	"P4322":  {"x,y,z", "-x,-y,z+1/2", "-y,x,z+3/4", "y,-x,z+1/4", "-x,y,-z", "x,-y,-z+1/2", "y,x,-z+1/4", "-y,-x,-z+3/4"},
+	"P43212": {"x,y,z", "-x,-y,z+1/2", "-y+1/2,x+1/2,z+3/4", "y+1/2,-x+1/2,z+1/4", "-x+1/2,y+1/2,-z+3/4", "x+1/2,-y+1/2,-z+1/4", "y,x,-z", "-y,-x,-z+1/2"},
+	"I422":   extend(p4, "-x,y,-z", "x,-y,-z", "y,x,-z", "-y,-x,-z"),
+	"I4122":  {"x,y,z", "-x+1/2,-y+1/2,z+1/2", "-y,x+1/2,z+1/4", "y+1/2,-x,z+3/4", "-x+1/2,y,-z+3/4", "x,-y+1/2,-z+1/4", "y+1/2,x+1/2,-z+1/2", "-y,-x,-z"},
+
+	// Trigonal
+	"P3":    p3,
+	"P31":   {"x,y,z", "-y,x-y,z+1/3", "-x+y,-x,z+2/3"},
+	"P32":   {"x,y,z", "-y,x-y,z+2/3", "-x+y,-x,z+1/3"},
+	"H3":    p3,
+	"R3":    {"x,y,z", "z,x,y", "y,z,x"},
+	"P312":  extend(p3, "-y,-x,-z", "-x+y,y,-z", "x,x-y,-z"),
+	"P321":  extend(p3, "y,x,-z", "x-y,-y,-z", "-x,-x+y,-z"),
+	"P3112": {"x,y,z", "-y,x-y,z+1/3", "-x+y,-x,z+2/3", "-y,-x,-z+2/3", "-x+y,y,-z+1/3", "x,x-y,-z"},
+	"P3121": {"x,y,z", "-y,x-y,z+1/3", "-x+y,-x,z+2/3", "y,x,-z", "x-y,-y,-z+2/3", "-x,-x+y,-z+1/3"},
+	"P3212": {"x,y,z", "-y,x-y,z+2/3", "-x+y,-x,z+1/3", "-y,-x,-z+1/3", "-x+y,y,-z+2/3", "x,x-y,-z"},
+	"P3221": {"x,y,z", "-y,x-y,z+2/3", "-x+y,-x,z+1/3", "y,x,-z", "x-y,-y,-z+1/3", "-x,-x+y,-z+2/3"},
+	"H32":   extend(p3, "y,x,-z", "x-y,-y,-z", "-x,-x+y,-z"),
+	"R32":   {"x,y,z", "z,x,y", "y,z,x", "-y,-x,-z", "-x,-z,-y", "-z,-y,-x"},
+
+	// Hexagonal
+	"P6":    p6,
+	"P61":   {"x,y,z", "-y,x-y,z+1/3", "-x+y,-x,z+2/3", "-x,-y,z+1/2", "y,-x+y,z+5/6", "x-y,x,z+1/6"},
+	"P65":   {"x,y,z", "-y,x-y,z+2/3", "-x+y,-x,z+1/3", "-x,-y,z+1/2", "y,-x+y,z+1/6", "x-y,x,z+5/6"},
+	"P62":   {"x,y,z", "-y,x-y,z+2/3", "-x+y,-x,z+1/3", "-x,-y,z", "y,-x+y,z+2/3", "x-y,x,z+1/3"},
+	"P64":   {"x,y,z", "-y,x-y,z+1/3", "-x+y,-x,z+2/3", "-x,-y,z", "y,-x+y,z+1/3", "x-y,x,z+2/3"},
+	"P63":   {"x,y,z", "-y,x-y,z", "-x+y,-x,z", "-x,-y,z+1/2", "y,-x+y,z+1/2", "x-y,x,z+1/2"},
+	"P622":  extend(p6, "y,x,-z", "x-y,-y,-z", "-x,-x+y,-z", "-y,-x,-z", "-x+y,y,-z", "x,x-y,-z"),
+	"P6122": {"x,y,z", "-y,x-y,z+1/3", "-x+y,-x,z+2/3", "-x,-y,z+1/2", "y,-x+y,z+5/6", "x-y,x,z+1/6", "y,x,-z+1/3", "x-y,-y,-z", "-x,-x+y,-z+2/3", "-y,-x,-z+5/6", "-x+y,y,-z+1/2", "x,x-y,-z+1/6"},
+	"P6522": {"x,y,z", "-y,x-y,z+2/3", "-x+y,-x,z+1/3", "-x,-y,z+1/2", "y,-x+y,z+1/6", "x-y,x,z+5/6", "y,x,-z+2/3", "x-y,-y,-z", "-x,-x+y,-z+1/3", "-y,-x,-z+1/6", "-x+y,y,-z+1/2", "x,x-y,-z+5/6"},
+	"P6222": {"x,y,z", "-y,x-y,z+2/3", "-x+y,-x,z+1/3", "-x,-y,z", "y,-x+y,z+2/3", "x-y,x,z+1/3", "y,x,-z+2/3", "x-y,-y,-z", "-x,-x+y,-z+1/3", "-y,-x,-z+2/3", "-x+y,y,-z", "x,x-y,-z+1/3"},
+	"P6422": {"x,y,z", "-y,x-y,z+1/3", "-x+y,-x,z+2/3", "-x,-y,z", "y,-x+y,z+1/3", "x-y,x,z+2/3", "y,x,-z+1/3", "x-y,-y,-z", "-x,-x+y,-z+2/3", "-y,-x,-z+1/3", "-x+y,y,-z", "x,x-y,-z+2/3"},
+	"P6322": {"x,y,z", "-y,x-y,z", "-x+y,-x,z", "-x,-y,z+1/2", "y,-x+y,z+1/2", "x-y,x,z+1/2", "y,x,-z", "x-y,-y,-z", "-x,-x+y,-z", "-y,-x,-z+1/2", "-x+y,y,-z+1/2", "x,x-y,-z+1/2"},
+
+	// Cubic
+	"P23":   p23,
+	"F23":   p23,
+	"I23":   p23,
+	"P213":  p213,
+	"I213":  p213,
+	"P432":  p432,
+	"P4232": extend(p23, "y+1/2,x+1/2,-z+1/2", "-y+1/2,-x+1/2,-z+1/2", "y+1/2,-x+1/2,z+1/2", "-y+1/2,x+1/2,z+1/2", "x+1/2,z+1/2,-y+1/2", "-x+1/2,z+1/2,y+1/2", "-x+1/2,-z+1/2,-y+1/2", "x+1/2,-z+1/2,y+1/2", "z+1/2,y+1/2,-x+1/2", "z+1/2,-y+1/2,x+1/2", "-z+1/2,y+1/2,x+1/2", "-z+1/2,-y+1/2,-x+1/2"),
+	"F432":  p432,
+	"F4132": {"x,y,z", "-x,-y+1/2,z+1/2", "-x+1/2,y+1/2,-z", "x+1/2,-y,-z+1/2", "z,x,y", "z+1/2,-x,-y+1/2", "-z,-x+1/2,y+1/2", "-z+1/2,x+1/2,-y", "y,z,x", "-y+1/2,z+1/2,-x", "y+1/2,-z,-x+1/2", "-y,-z+1/2,x+1/2", "y+3/4,x+1/4,-z+3/4", "-y+1/4,-x+1/4,-z+1/4", "y+1/4,-x+3/4,z+3/4", "-y+3/4,x+3/4,z+1/4", "x+3/4,z+1/4,-y+3/4", "-x+3/4,z+3/4,y+1/4", "-x+1/4,-z+1/4,-y+1/4", "x+1/4,-z+3/4,y+3/4", "z+3/4,y+1/4,-x+3/4", "z+1/4,-y+3/4,x+3/4", "-z+3/4,y+3/4,x+1/4", "-z+1/4,-y+1/4,-x+1/4"},
+	"I432":  p432,
+	"P4332": extend(p213, "y+1/4,x+3/4,-z+3/4", "-y+1/4,-x+1/4,-z+1/4", "y+3/4,-x+3/4,z+1/4", "-y+3/4,x+1/4,z+3/4", "x+1/4,z+3/4,-y+3/4", "-x+3/4,z+1/4,y+3/4", "-x+1/4,-z+1/4,-y+1/4", "x+3/4,-z+3/4,y+1/4", "z+1/4,y+3/4,-x+3/4", "z+3/4,-y+3/4,x+1/4", "-z+3/4,y+1/4,x+3/4", "-z+1/4,-y+1/4,-x+1/4"),
+	"P4132": p41_32,
+	"I4132": p41_32,
+}
+
+// monoclinicAliases are the full symbols used by the PDB for the monoclinic groups.
+var monoclinicAliases = map[string]string{"P121": "P2", "P1211": "P21", "C121": "C2"}
+
+// SpaceGroupOperators returns the symmetry operators of a space group, in the x,y,z notation of fractional
+// coordinates, by its Hermann-Mauguin symbol as found in PDB and CIF files (i.e. P 21 21 21, P 1 21 1, H 3).
+// Only the 65 groups compatible with chiral molecules are supported. For R groups, hexagonal axes
+// are assumed unless hexagonal is false.
+func SpaceGroupOperators(name string, hexagonal bool) ([]string, error) {
+	symbol := strings.ToUpper(strings.Join(strings.Fields(name), ""))
+	if alias, ok := monoclinicAliases[symbol]; ok {
+		symbol = alias
+	}
+	if hexagonal && strings.HasPrefix(symbol, "R") {
+		symbol = "H" + symbol[1:]
+	}
+
+	primitive, ok := spaceGroups[symbol]
+	if !ok {
+		return nil, fmt.Errorf("space group %s not supported", name)
+	}
+
+	var ops []string
+	for _, c := range centerings[symbol[0]] {
+		for _, op := range primitive {
+			if c == "x,y,z" {
+				ops = append(ops, op)
+				continue
+			}
+			combined, err := combineOperators(c, op)
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, combined)
+		}
+	}
+
+	return ops, nil
+}
+
+// SymmetryOperator is a symmetry operator in fractional coordinates, as a rotation and translation.
+type SymmetryOperator struct {
+	Rotation    [3][3]float64
+	Translation [3]float64
+}
+
+// ParseSymmetryOperator parses an operator in the x,y,z notation, such as -y,x-y,z+1/3.
+func ParseSymmetryOperator(xyz string) (*SymmetryOperator, error) {
+	parts := strings.Split(strings.ToLower(strings.ReplaceAll(xyz, " ", "")), ",")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid symmetry operator %s", xyz)
+	}
+
+	op := &SymmetryOperator{}
+	for row, part := range parts {
+		// Split into signed terms
+		var terms []string
+		start := 0
+		for i := 1; i < len(part); i++ {
+			if part[i] == '+' || part[i] == '-' {
+				terms = append(terms, part[start:i])
+				start = i
+			}
+		}
+		terms = append(terms, part[start:])
+
+		for _, term := range terms {
+			sign := 1.0
+			switch {
+			case strings.HasPrefix(term, "-"):
+				sign, term = -1, term[1:]
+			case strings.HasPrefix(term, "+"):
+				term = term[1:]
+			}
+
+			switch term {
+			case "x", "y", "z":
+				op.Rotation[row][term[0]-'x'] += sign
+			default:
+				v, err := parseFraction(term)
+				if err != nil {
+					return nil, fmt.Errorf("invalid symmetry operator %s: %v", xyz, err)
+				}
+				op.Translation[row] += sign * v
+			}
+		}
+	}
+
+	return op, nil
+}
+
+func parseFraction(s string) (float64, error) {
+	if i := strings.Index(s, "/"); i >= 0 {
+		num, err1 := strconv.ParseFloat(s[:i], 64)
+		den, err2 := strconv.ParseFloat(s[i+1:], 64)
+		if err1 != nil || err2 != nil || den == 0 {
+			return 0, fmt.Errorf("invalid fraction %s", s)
+		}
+		return num / den, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// combineOperators returns the operator that adds the translation of the centering to the operator.
+func combineOperators(centering string, op string) (string, error) {
+	c, err := ParseSymmetryOperator(centering)
+	if err != nil {
+		return "", err
+	}
+	o, err := ParseSymmetryOperator(op)
+	if err != nil {
+		return "", err
+	}
+	for i := range o.Translation {
+		o.Translation[i] = math.Mod(o.Translation[i]+c.Translation[i], 1)
+	}
+	return o.String(), nil
+}
+
+// String returns the operator in the x,y,z notation.
+func (o *SymmetryOperator) String() string {
+	var parts []string
+	for row := 0; row < 3; row++ {
+		var sb strings.Builder
+		for col, axis := range []string{"x", "y", "z"} {
+			switch o.Rotation[row][col] {
+			case 1:
+				if sb.Len() > 0 {
+					sb.WriteString("+")
+				}
+				sb.WriteString(axis)
+			case -1:
+				sb.WriteString("-" + axis)
+			}
+		}
+		if t := o.Translation[row]; t != 0 {
+			if t > 0 {
+				sb.WriteString("+")
+			}
+			sb.WriteString(fraction(t))
+		}
+		parts = append(parts, sb.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// fraction formats a translation as a fraction of twelfths, which covers every crystallographic translation.
+func fraction(v float64) string {
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	n, d := int(v*12+0.5), 12
+	for _, f := range []int{2, 2, 3} {
+		if n%f == 0 {
+			n, d = n/f, d/f
+		}
+	}
+	if d == 1 {
+		return sign + strconv.Itoa(n)
+	}
+	return fmt.Sprintf("%s%d/%d", sign, n, d)
+}
+
+// compose returns the operator equivalent to applying o2 and then o.
+func (o *SymmetryOperator) compose(o2 *SymmetryOperator) *SymmetryOperator {
+	c := &SymmetryOperator{}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				c.Rotation[i][j] += o.Rotation[i][k] * o2.Rotation[k][j]
+			}
+			c.Translation[i] += o.Rotation[i][j] * o2.Translation[j]
+		}
+		c.Translation[i] += o.Translation[i]
+	}
+	return c
+}
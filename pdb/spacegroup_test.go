@@ -0,0 +1,78 @@
+package pdb
+
+import (
+	"math"
+	"testing"
+)
+
+// TestSpaceGroupOperators checks that the operators of every space group form a group, modulo lattice translations.
+func TestSpaceGroupOperators(t *testing.T) {
+	for name := range spaceGroups {
+		xyz, err := SpaceGroupOperators(name, name[0] == 'H')
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var ops []*SymmetryOperator
+		for _, s := range xyz {
+			op, err := ParseSymmetryOperator(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ops = append(ops, op)
+		}
+
+		equal := func(a *SymmetryOperator, b *SymmetryOperator) bool {
+			for i := 0; i < 3; i++ {
+				for j := 0; j < 3; j++ {
+					if a.Rotation[i][j] != b.Rotation[i][j] {
+						return false
+					}
+				}
+				d := a.Translation[i] - b.Translation[i]
+				if math.Abs(d-math.Round(d)) > 1e-6 {
+					return false
+				}
+			}
+			return true
+		}
+
+		for _, a := range ops {
+			for _, b := range ops {
+				product := a.compose(b)
+				found := false
+				for _, c := range ops {
+					if equal(product, c) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("space group %s not closed: %s * %s = %s", name, a, b, product)
+				}
+			}
+			for _, b := range ops {
+				if a != b && equal(a, b) {
+					t.Errorf("space group %s has repeated operator %s", name, a)
+				}
+			}
+		}
+	}
+
+	for _, name := range []string{"P 1 21 1", "P 21 21 21", "H 3", "R 3 2"} {
+		if _, err := SpaceGroupOperators(name, true); err != nil {
+			t.Errorf("expected space group %s, got %v", name, err)
+		}
+	}
+	if _, err := SpaceGroupOperators("P -1", false); err == nil {
+		t.Errorf("expected error for centrosymmetric group")
+	}
+
+	op, err := ParseSymmetryOperator("-X+Y, -x, z+1/3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := op.String(); s != "-x+y,-x,z+1/3" {
+		t.Errorf("expected -x+y,-x,z+1/3, got %s", s)
+	}
+}
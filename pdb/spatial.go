@@ -0,0 +1,207 @@
+package pdb
+
+import (
+	"math"
+	"sort"
+)
+
+// defaultCellSize is the cell edge length in Angstroms for the indexes built on load, about
+// the size of the usual contact cutoffs, so most radius queries only visit the neighbor cells.
+const defaultCellSize = 5.0
+
+// Index is a cell list spatial index over a set of atoms, for radius and nearest neighbor queries
+// without comparing against every atom.
+type Index struct {
+	atoms    []*Atom
+	cellSize float64
+	cells    map[[3]int64][]int
+	min, max [3]int64 // bounds of the occupied cells
+}
+
+// NewIndex builds a spatial index over the atoms, partitioning space into cubic cells of the given edge length in Angstroms.
+func NewIndex(atoms []*Atom, cellSize float64) *Index {
+	idx := &Index{
+		atoms:    atoms,
+		cellSize: cellSize,
+		cells:    make(map[[3]int64][]int),
+	}
+
+	for i, a := range atoms {
+		c := idx.cell(a.X, a.Y, a.Z)
+		idx.cells[c] = append(idx.cells[c], i)
+		for d := range c {
+			if i == 0 || c[d] < idx.min[d] {
+				idx.min[d] = c[d]
+			}
+			if i == 0 || c[d] > idx.max[d] {
+				idx.max[d] = c[d]
+			}
+		}
+	}
+
+	return idx
+}
+
+func (idx *Index) cell(x float64, y float64, z float64) [3]int64 {
+	return [3]int64{
+		int64(math.Floor(x / idx.cellSize)),
+		int64(math.Floor(y / idx.cellSize)),
+		int64(math.Floor(z / idx.cellSize)),
+	}
+}
+
+// Within returns the atoms at a distance less or equal than radius from the given point,
+// in the same order they were given to the index.
+func (idx *Index) Within(x float64, y float64, z float64, radius float64) []*Atom {
+	lo := idx.cell(x-radius, y-radius, z-radius)
+	hi := idx.cell(x+radius, y+radius, z+radius)
+
+	var found []int
+	for i := lo[0]; i <= hi[0]; i++ {
+		for j := lo[1]; j <= hi[1]; j++ {
+			for k := lo[2]; k <= hi[2]; k++ {
+				for _, n := range idx.cells[[3]int64{i, j, k}] {
+					a := idx.atoms[n]
+					if pointsDistance(x, y, z, a.X, a.Y, a.Z) <= radius {
+						found = append(found, n)
+					}
+				}
+			}
+		}
+	}
+	sort.Ints(found)
+
+	atoms := make([]*Atom, len(found))
+	for i, n := range found {
+		atoms[i] = idx.atoms[n]
+	}
+
+	return atoms
+}
+
+// WithinAtom returns the atoms at a distance less or equal than radius from the given atom, including itself if indexed.
+func (idx *Index) WithinAtom(a *Atom, radius float64) []*Atom {
+	return idx.Within(a.X, a.Y, a.Z, radius)
+}
+
+// Nearest returns the closest atom to the given point and its distance, or nil if the index is empty.
+func (idx *Index) Nearest(x float64, y float64, z float64) (*Atom, float64) {
+	if len(idx.atoms) == 0 {
+		return nil, 0
+	}
+
+	// Search shells of cells around the point until one contains an atom.
+	c := idx.cell(x, y, z)
+	var maxShell int64
+	for d := range c {
+		if s := c[d] - idx.min[d]; s > maxShell {
+			maxShell = s
+		}
+		if s := idx.max[d] - c[d]; s > maxShell {
+			maxShell = s
+		}
+	}
+
+	var nearest *Atom
+	best := math.Inf(1)
+	for shell := int64(0); shell <= maxShell && nearest == nil; shell++ {
+		for i := c[0] - shell; i <= c[0]+shell; i++ {
+			for j := c[1] - shell; j <= c[1]+shell; j++ {
+				for k := c[2] - shell; k <= c[2]+shell; k++ {
+					if abs64(i-c[0]) != shell && abs64(j-c[1]) != shell && abs64(k-c[2]) != shell {
+						continue
+					}
+					for _, n := range idx.cells[[3]int64{i, j, k}] {
+						a := idx.atoms[n]
+						if d := pointsDistance(x, y, z, a.X, a.Y, a.Z); d < best {
+							nearest, best = a, d
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// Atoms in the next shells can still be closer than the one found, up to its distance.
+	for _, a := range idx.Within(x, y, z, best) {
+		if d := pointsDistance(x, y, z, a.X, a.Y, a.Z); d < best {
+			nearest, best = a, d
+		}
+	}
+
+	return nearest, best
+}
+
+// NearestAtom returns the closest indexed atom to the given atom, other than itself, and its distance.
+func (idx *Index) NearestAtom(a *Atom) (*Atom, float64) {
+	nearest, dist := idx.Nearest(a.X, a.Y, a.Z)
+	if nearest != a {
+		return nearest, dist
+	}
+
+	// The atom itself is indexed, look for the closest other one in increasingly larger radii.
+	for radius := idx.cellSize; ; radius *= 2 {
+		candidates := idx.WithinAtom(a, radius)
+		nearest, dist = nil, math.Inf(1)
+		for _, b := range candidates {
+			if d := AtomsDistance(a, b); b != a && d < dist {
+				nearest, dist = b, d
+			}
+		}
+		if nearest != nil {
+			return nearest, dist
+		}
+		if len(candidates) == len(idx.atoms) {
+			return nil, 0
+		}
+	}
+}
+
+// AtomIndex returns the spatial index of the ATOM records in the first model of the structure.
+// The index is cached in the model until Reindex is called, or rebuilt if the atoms of the structure
+// are no longer those of the first model.
+func (pdb *PDB) AtomIndex() *Index {
+	if len(pdb.Models) == 0 || !sameAtoms(pdb.Atoms, pdb.Models[0].Atoms) {
+		return NewIndex(pdb.Atoms, defaultCellSize)
+	}
+
+	m := pdb.Models[0]
+	m.indexMu.Lock()
+	defer m.indexMu.Unlock()
+	if m.atomIndex == nil {
+		m.atomIndex = NewIndex(m.Atoms, defaultCellSize)
+	}
+	return m.atomIndex
+}
+
+// HetIndex returns the spatial index of the HETATM records in the first model of the structure,
+// cached as the AtomIndex.
+func (pdb *PDB) HetIndex() *Index {
+	if len(pdb.Models) == 0 || !sameAtoms(pdb.HetAtoms, pdb.Models[0].HetAtoms) {
+		return NewIndex(pdb.HetAtoms, defaultCellSize)
+	}
+
+	m := pdb.Models[0]
+	m.indexMu.Lock()
+	defer m.indexMu.Unlock()
+	if m.hetIndex == nil {
+		m.hetIndex = NewIndex(m.HetAtoms, defaultCellSize)
+	}
+	return m.hetIndex
+}
+
+// sameAtoms reports whether both lists are the same slice of atoms.
+func sameAtoms(a []*Atom, b []*Atom) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
+
+func pointsDistance(x1 float64, y1 float64, z1 float64, x2 float64, y2 float64, z2 float64) float64 {
+	return math.Sqrt((x1-x2)*(x1-x2) + (y1-y2)*(y1-y2) + (z1-z2)*(z1-z2))
+}
+
+func abs64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
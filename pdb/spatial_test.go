@@ -0,0 +1,94 @@
+package pdb
+
+import (
+	"testing"
+)
+
+func TestIndex(t *testing.T) {
+	raw, err := LoadTestFile("./testdata/1mso.pdb")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+
+	pdb, err := NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idx := pdb.AtomIndex()
+	for _, a := range pdb.Atoms[:200] {
+		var expected []*Atom
+		var nearest *Atom
+		for _, b := range pdb.Atoms {
+			if AtomsDistance(a, b) <= 6 {
+				expected = append(expected, b)
+			}
+			if b != a && (nearest == nil || AtomsDistance(a, b) < AtomsDistance(a, nearest)) {
+				nearest = b
+			}
+		}
+
+		found := idx.WithinAtom(a, 6)
+		if len(found) != len(expected) {
+			t.Fatalf("expected %d atoms near %d, got %d", len(expected), a.Number, len(found))
+		}
+		for i := range found {
+			if found[i] != expected[i] {
+				t.Errorf("expected atom %d, got %d", expected[i].Number, found[i].Number)
+			}
+		}
+
+		if n, d := idx.NearestAtom(a); d != AtomsDistance(a, nearest) || n == a {
+			t.Errorf("expected nearest atom %d to %d, got %d", nearest.Number, a.Number, n.Number)
+		}
+	}
+
+	res := pdb.Chains["B"][int64(10)]
+	near := CloseResidues(pdb, res, 5)
+	var expected []*Residue
+	for _, atom := range pdb.Atoms {
+		r := atom.Parent()
+		if r != res && (len(expected) == 0 || expected[len(expected)-1] != r) && ResiduesDistance(r, res) < 5 {
+			expected = append(expected, r)
+		}
+	}
+	if len(near) != len(expected) {
+		t.Fatalf("expected %d close residues, got %d", len(expected), len(near))
+	}
+	for i := range near {
+		if near[i] != expected[i] {
+			t.Errorf("expected %s-%d, got %s-%d", expected[i].Chain, expected[i].StructPosition, near[i].Chain, near[i].StructPosition)
+		}
+	}
+}
+
+func TestReindex(t *testing.T) {
+	raw, err := LoadTestFile("./testdata/1mso.pdb")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+
+	pdb, err := NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idx := pdb.AtomIndex()
+	if pdb.AtomIndex() != idx {
+		t.Errorf("expected cached index")
+	}
+
+	// Atoms moved by hand are found after reindexing
+	a := pdb.Atoms[0]
+	a.X, a.Y, a.Z = 1000, 1000, 1000
+	pdb.Reindex()
+	if found := pdb.AtomIndex().Within(1000, 1000, 1000, 1); len(found) != 1 || found[0] != a {
+		t.Errorf("expected moved atom after Reindex")
+	}
+
+	// Replaced atoms get a new index
+	pdb.Atoms = pdb.Atoms[1:]
+	if found := pdb.AtomIndex().Within(1000, 1000, 1000, 1); len(found) != 0 {
+		t.Errorf("expected removed atom not to be indexed")
+	}
+}
@@ -0,0 +1,280 @@
+package pdb
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// AtomMatch indicates which atoms of each pair of residues are used for superposition.
+type AtomMatch int
+
+const (
+	// MatchCA uses the alpha carbons.
+	MatchCA AtomMatch = iota
+	// MatchBackbone uses the N, CA, C and O atoms.
+	MatchBackbone
+	// MatchAll uses every atom present in both residues, matched by name.
+	MatchAll
+)
+
+// Transform is a rigid body transformation, a rotation followed by a translation.
+type Transform struct {
+	Rotation    [3][3]float64 `json:"rotation"`
+	Translation [3]float64    `json:"translation"`
+}
+
+// Apply returns the transformed coordinates of a point.
+func (t *Transform) Apply(x float64, y float64, z float64) (float64, float64, float64) {
+	r := t.Rotation
+	return r[0][0]*x + r[0][1]*y + r[0][2]*z + t.Translation[0],
+		r[1][0]*x + r[1][1]*y + r[1][2]*z + t.Translation[1],
+		r[2][0]*x + r[2][1]*y + r[2][2]*z + t.Translation[2]
+}
+
+// isIdentity reports whether the transformation leaves the coordinates unchanged, within the precision
+// of the operators listed in PDB and CIF files.
+func (t *Transform) isIdentity() bool {
+	const tolerance = 1e-4
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			expected := 0.0
+			if i == j {
+				expected = 1
+			}
+			if math.Abs(t.Rotation[i][j]-expected) > tolerance {
+				return false
+			}
+		}
+		if math.Abs(t.Translation[i]) > tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// ApplyAtoms transforms the coordinates of the atoms in place, discarding the spatial indexes
+// of the models they belong to.
+func (t *Transform) ApplyAtoms(atoms []*Atom) {
+	moved := make(map[*Model]bool)
+	for _, a := range atoms {
+		a.X, a.Y, a.Z = t.Apply(a.X, a.Y, a.Z)
+		if a.owner != nil && !moved[a.owner] {
+			moved[a.owner] = true
+			a.owner.Reindex()
+		}
+	}
+}
+
+// RMSD returns the root mean square deviation between two equally sized lists of paired atoms, without superposing them.
+func RMSD(a []*Atom, b []*Atom) (float64, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("atom lists have different lengths: %d and %d", len(a), len(b))
+	}
+	if len(a) == 0 {
+		return 0, errors.New("empty atoms list")
+	}
+
+	var sum float64
+	for i := range a {
+		sum += math.Pow(AtomsDistance(a[i], b[i]), 2)
+	}
+
+	return math.Sqrt(sum / float64(len(a))), nil
+}
+
+// Superpose calculates the optimal rigid body transformation of the mobile atoms onto the target atoms,
+// minimizing the RMSD between each pair, using the quaternion formulation of the Kabsch problem.
+// Atoms are paired by index, and are not moved (see Transform.ApplyAtoms).
+// Returns the transformation and the RMSD after superposition.
+func Superpose(mobile []*Atom, target []*Atom) (*Transform, float64, error) {
+	if len(mobile) != len(target) {
+		return nil, 0, fmt.Errorf("atom lists have different lengths: %d and %d", len(mobile), len(target))
+	}
+	if len(mobile) == 0 {
+		return nil, 0, errors.New("empty atoms list")
+	}
+
+	cm, ct := centroid(mobile), centroid(target)
+
+	// Correlation matrix of the centered coordinates
+	var s [3][3]float64
+	for i := range mobile {
+		m := [3]float64{mobile[i].X - cm[0], mobile[i].Y - cm[1], mobile[i].Z - cm[2]}
+		t := [3]float64{target[i].X - ct[0], target[i].Y - ct[1], target[i].Z - ct[2]}
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				s[j][k] += m[j] * t[k]
+			}
+		}
+	}
+
+	// The optimal rotation is the eigenvector of the largest eigenvalue of this matrix, as a unit quaternion.
+	// https://doi.org/10.1364/JOSAA.4.000629
+	n := [4][4]float64{
+		{s[0][0] + s[1][1] + s[2][2], s[1][2] - s[2][1], s[2][0] - s[0][2], s[0][1] - s[1][0]},
+		{s[1][2] - s[2][1], s[0][0] - s[1][1] - s[2][2], s[0][1] + s[1][0], s[2][0] + s[0][2]},
+		{s[2][0] - s[0][2], s[0][1] + s[1][0], -s[0][0] + s[1][1] - s[2][2], s[1][2] + s[2][1]},
+		{s[0][1] - s[1][0], s[2][0] + s[0][2], s[1][2] + s[2][1], -s[0][0] - s[1][1] + s[2][2]},
+	}
+	values, vectors := jacobiEigen(n)
+	best := 0
+	for i := range values {
+		if values[i] > values[best] {
+			best = i
+		}
+	}
+	q := [4]float64{vectors[0][best], vectors[1][best], vectors[2][best], vectors[3][best]}
+
+	t := &Transform{Rotation: [3][3]float64{
+		{q[0]*q[0] + q[1]*q[1] - q[2]*q[2] - q[3]*q[3], 2 * (q[1]*q[2] - q[0]*q[3]), 2 * (q[1]*q[3] + q[0]*q[2])},
+		{2 * (q[1]*q[2] + q[0]*q[3]), q[0]*q[0] - q[1]*q[1] + q[2]*q[2] - q[3]*q[3], 2 * (q[2]*q[3] - q[0]*q[1])},
+		{2 * (q[1]*q[3] - q[0]*q[2]), 2 * (q[2]*q[3] + q[0]*q[1]), q[0]*q[0] - q[1]*q[1] - q[2]*q[2] + q[3]*q[3]},
+	}}
+	rx, ry, rz := t.Apply(cm[0], cm[1], cm[2])
+	t.Translation = [3]float64{ct[0] - rx, ct[1] - ry, ct[2] - rz}
+
+	var sum float64
+	for i := range mobile {
+		x, y, z := t.Apply(mobile[i].X, mobile[i].Y, mobile[i].Z)
+		sum += math.Pow(pointsDistance(x, y, z, target[i].X, target[i].Y, target[i].Z), 2)
+	}
+
+	return t, math.Sqrt(sum / float64(len(mobile))), nil
+}
+
+func centroid(atoms []*Atom) (c [3]float64) {
+	for _, a := range atoms {
+		c[0] += a.X
+		c[1] += a.Y
+		c[2] += a.Z
+	}
+	for i := range c {
+		c[i] /= float64(len(atoms))
+	}
+	return c
+}
+
+// jacobiEigen returns the eigenvalues and eigenvectors (as columns) of a symmetric matrix, using the cyclic Jacobi method.
+func jacobiEigen(a [4][4]float64) ([4]float64, [4][4]float64) {
+	var v [4][4]float64
+	for i := range v {
+		v[i][i] = 1
+	}
+
+	for sweep := 0; sweep < 50; sweep++ {
+		var off float64
+		for p := 0; p < 4; p++ {
+			for q := p + 1; q < 4; q++ {
+				off += a[p][q] * a[p][q]
+			}
+		}
+		if off < 1e-22 {
+			break
+		}
+
+		for p := 0; p < 4; p++ {
+			for q := p + 1; q < 4; q++ {
+				if a[p][q] == 0 {
+					continue
+				}
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+
+				for k := 0; k < 4; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p] = c*akp - s*akq
+					a[k][q] = s*akp + c*akq
+				}
+				for k := 0; k < 4; k++ {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k] = c*apk - s*aqk
+					a[q][k] = s*apk + c*aqk
+				}
+				for k := 0; k < 4; k++ {
+					vkp, vkq := v[k][p], v[k][q]
+					v[k][p] = c*vkp - s*vkq
+					v[k][q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+
+	return [4]float64{a[0][0], a[1][1], a[2][2], a[3][3]}, v
+}
+
+// PairResidues pairs the residues of a chain in each structure that map to the same UniProt position,
+// so different structures of the same protein can be compared regardless of their residue numbering.
+// Both structures need their UniProt mappings (see NewPDBFromID). Pairs are ordered by mobile chain residue.
+func PairResidues(mobile *PDB, mobileChain string, target *PDB, targetChain string) [][2]*Residue {
+	var pairs [][2]*Residue
+	for _, res := range mobile.Residues[mobileChain] {
+		if res.UnpID == "" {
+			continue
+		}
+		for _, tRes := range target.UniProtPositions[res.UnpID][res.UnpPosition] {
+			if tRes.Chain == targetChain {
+				pairs = append(pairs, [2]*Residue{res, tRes})
+				break
+			}
+		}
+	}
+
+	return pairs
+}
+
+// PairAtoms returns the atoms of each pair of residues that are present in both, matched by name.
+func PairAtoms(pairs [][2]*Residue, match AtomMatch) (mobile []*Atom, target []*Atom) {
+	backbone := map[string]bool{"N": true, "CA": true, "C": true, "O": true}
+	for _, pair := range pairs {
+		targetAtoms := make(map[string]*Atom)
+		for _, a := range pair[1].Atoms {
+			if _, ok := targetAtoms[a.Name]; !ok {
+				targetAtoms[a.Name] = a
+			}
+		}
+
+		var names []string
+		mobileAtoms := make(map[string]*Atom)
+		for _, a := range pair[0].Atoms {
+			if mobileAtoms[a.Name] != nil || targetAtoms[a.Name] == nil {
+				continue
+			}
+			if (match == MatchCA && a.Name != "CA") || (match == MatchBackbone && !backbone[a.Name]) {
+				continue
+			}
+			names = append(names, a.Name)
+			mobileAtoms[a.Name] = a
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			mobile = append(mobile, mobileAtoms[name])
+			target = append(target, targetAtoms[name])
+		}
+	}
+
+	return mobile, target
+}
+
+// SuperposeChains superposes a chain of the mobile structure onto a chain of the target structure,
+// pairing residues by their UniProt positions. Returns the transformation and the RMSD after superposition.
+func SuperposeChains(mobile *PDB, mobileChain string, target *PDB, targetChain string, match AtomMatch) (*Transform, float64, error) {
+	pairs := PairResidues(mobile, mobileChain, target, targetChain)
+	if len(pairs) == 0 {
+		return nil, 0, fmt.Errorf("no residues of chain %s paired to chain %s", mobileChain, targetChain)
+	}
+
+	mobileAtoms, targetAtoms := PairAtoms(pairs, match)
+	if len(mobileAtoms) < 3 {
+		return nil, 0, fmt.Errorf("not enough paired atoms: %d", len(mobileAtoms))
+	}
+
+	return Superpose(mobileAtoms, targetAtoms)
+}
@@ -0,0 +1,122 @@
+package pdb
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSuperpose(t *testing.T) {
+	raw, err := LoadTestFile("./testdata/1mso.pdb")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+
+	pdb, err := NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	moved, err := NewPDBFromAtoms(pdb.Atoms, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := 0.7
+	rotation := &Transform{
+		Rotation:    [3][3]float64{{math.Cos(a), -math.Sin(a), 0}, {math.Sin(a), math.Cos(a), 0}, {0, 0, 1}},
+		Translation: [3]float64{10, -5, 3},
+	}
+	rotation.ApplyAtoms(moved.Atoms)
+
+	if rmsd, _ := RMSD(moved.Atoms, pdb.Atoms); rmsd < 1 {
+		t.Errorf("expected moved atoms, got RMSD %f", rmsd)
+	}
+
+	transform, rmsd, err := Superpose(moved.Atoms, pdb.Atoms)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rmsd > 1e-6 {
+		t.Errorf("expected RMSD 0, got %f", rmsd)
+	}
+	transform.ApplyAtoms(moved.Atoms)
+	if d := AtomsDistance(moved.Atoms[0], pdb.Atoms[0]); d > 1e-6 {
+		t.Errorf("expected atom %d at its original position, got %f A away", pdb.Atoms[0].Number, d)
+	}
+
+	// Chains B and D are copies of the same sequence
+	for _, chain := range []string{"B", "D"} {
+		for _, res := range pdb.Residues[chain] {
+			res.UnpID = "P01308"
+			res.UnpPosition = res.StructPosition + 24
+		}
+	}
+	pdb.UniProtPositions = map[string]map[int64][]*Residue{"P01308": {}}
+	for _, res := range pdb.Residues["D"] {
+		pdb.UniProtPositions["P01308"][res.UnpPosition] = append(pdb.UniProtPositions["P01308"][res.UnpPosition], res)
+	}
+
+	pairs := PairResidues(pdb, "B", pdb, "D")
+	if len(pairs) != 30 {
+		t.Errorf("expected 30 residue pairs, got %d", len(pairs))
+	}
+
+	mobile, target := PairAtoms(pairs, MatchCA)
+	if len(mobile) != 30 || len(target) != 30 {
+		t.Errorf("expected 30 CA pairs, got %d", len(mobile))
+	}
+
+	_, rmsdCA, err := SuperposeChains(pdb, "B", pdb, "D", MatchCA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, rmsdAll, err := SuperposeChains(pdb, "B", pdb, "D", MatchAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rmsdCA <= 0 || rmsdCA > 3 || rmsdAll < rmsdCA {
+		t.Errorf("unexpected RMSD between chains B and D: CA %f, all %f", rmsdCA, rmsdAll)
+	}
+}
+
+func TestApplyAtomsReindex(t *testing.T) {
+	raw, err := LoadTestFile("./testdata/1mso.pdb")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+
+	pdb, err := NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := pdb.AtomIndex()
+	tr := &Transform{Rotation: [3][3]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}, Translation: [3]float64{100, 0, 0}}
+	tr.ApplyAtoms(pdb.Atoms)
+
+	idx := pdb.AtomIndex()
+	if idx == before {
+		t.Fatalf("expected a new index after moving the atoms")
+	}
+	for _, a := range pdb.Atoms[:50] {
+		var found bool
+		for _, b := range idx.WithinAtom(a, 0.1) {
+			found = found || b == a
+		}
+		if !found {
+			t.Errorf("moved atom %d not found at its new position", a.Number)
+		}
+	}
+}
+
+func TestTransformIsIdentity(t *testing.T) {
+	// Identity as parsed from a REMARK 350 or CIF operator with limited precision
+	op := &AssemblyOperation{ID: "1", Transform: Transform{Rotation: [3][3]float64{{0.999999, 0, 0}, {0, 1, 0.000001}, {0, 0, 1}}}}
+	if !op.isIdentity() {
+		t.Errorf("expected identity within tolerance")
+	}
+
+	op.Translation[2] = 0.01
+	if op.isIdentity() {
+		t.Errorf("expected translation not to be identity")
+	}
+}
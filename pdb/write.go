@@ -0,0 +1,284 @@
+package pdb
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WritePDB writes the ATOM and HETATM records of every model in PDB format.
+// MODEL/ENDMDL records are only written for structures with more than one model.
+// Chain IDs longer than one character or atom numbers beyond 99999 cannot be represented, use WriteCIF instead.
+func (pdb *PDB) WritePDB(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	models := pdb.writableModels()
+
+	for _, m := range models {
+		if len(models) > 1 {
+			fmt.Fprintf(bw, "%-80s\n", fmt.Sprintf("MODEL     %4d", m.number))
+		}
+
+		var last *Atom
+		for _, a := range m.atoms {
+			if len(a.atom.Chain) > 1 {
+				return fmt.Errorf("chain ID %s does not fit in PDB format", a.atom.Chain)
+			}
+			if a.atom.Number > 99999 || a.atom.ResidueNumber > 9999 || a.atom.ResidueNumber < -999 {
+				return fmt.Errorf("atom %d does not fit in PDB format", a.atom.Number)
+			}
+			if !fitsPDBColumns(a.atom) {
+				return fmt.Errorf("atom %d coordinates, occupancy or B-factor do not fit in PDB format", a.atom.Number)
+			}
+
+			// Chain termination after the last polymer record of each chain, modified residues included
+			polymer := !a.het || a.atom.residue != nil
+			if last != nil && (!polymer || last.Chain != a.atom.Chain) {
+				writeTER(bw, last)
+				last = nil
+			}
+
+			record := "ATOM"
+			if a.het {
+				record = "HETATM"
+			}
+			if polymer {
+				last = a.atom
+			}
+			writeATM(bw, record, a.atom)
+		}
+		if last != nil {
+			writeTER(bw, last)
+		}
+
+		if len(models) > 1 {
+			fmt.Fprintf(bw, "%-80s\n", "ENDMDL")
+		}
+	}
+	fmt.Fprintf(bw, "%-80s\n", "END")
+
+	return bw.Flush()
+}
+
+// writeATM writes a single ATOM or HETATM record.
+// https://www.wwpdb.org/documentation/file-format-content/format33/sect9.html#ATOM
+func writeATM(w io.Writer, record string, a *Atom) {
+	fmt.Fprintf(w, "%-6s%5d %-4s%1s%3s %1s%4d%1s   %8.3f%8.3f%8.3f%6.2f%6.2f          %2s%-2s\n",
+		record, a.Number, pdbAtomName(a), a.AltLoc, a.Residue, a.Chain, a.ResidueNumber, a.InsertionCode,
+		a.X, a.Y, a.Z, a.Occupancy, a.BFactor, a.Element, a.Charge)
+}
+
+// fitsPDBColumns reports whether the coordinates are within [-999.999, 9999.999] and the occupancy and B-factor
+// within [-99.99, 999.99] once rounded, so they do not overflow their fixed width columns.
+func fitsPDBColumns(a *Atom) bool {
+	for _, v := range []float64{a.X, a.Y, a.Z} {
+		if len(strconv.FormatFloat(v, 'f', 3, 64)) > 8 {
+			return false
+		}
+	}
+	for _, v := range []float64{a.Occupancy, a.BFactor} {
+		if len(strconv.FormatFloat(v, 'f', 2, 64)) > 6 {
+			return false
+		}
+	}
+	return true
+}
+
+// writeTER writes a chain termination record after the given atom.
+func writeTER(w io.Writer, a *Atom) {
+	fmt.Fprintf(w, "%-80s\n", fmt.Sprintf("TER   %5d      %3s %1s%4d%1s", a.Number+1, a.Residue, a.Chain, a.ResidueNumber, a.InsertionCode))
+}
+
+// pdbAtomName aligns the atom name in its 4 columns, where one letter elements start at the second column.
+func pdbAtomName(a *Atom) string {
+	if len(a.Name) < 4 && len(a.Element) < 2 {
+		return " " + a.Name
+	}
+	return a.Name
+}
+
+// WriteCIF writes the ATOM and HETATM records of every model as an mmCIF _atom_site category.
+// The label_seq_id is the one read from a CIF file, or else the SEQRES position of the residue if mapped,
+// or left inapplicable (.) otherwise, since author residue numbers are not valid entity sequence indexes.
+func (pdb *PDB) WriteCIF(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	id := pdb.ID
+	if id == "" {
+		id = "structure"
+	}
+	fmt.Fprintf(bw, "data_%s\n#\n", strings.ToUpper(id))
+	fmt.Fprintf(bw, "_entry.id %s\n#\n", cifValue(id))
+	if pdb.Title != "" {
+		fmt.Fprintf(bw, "_struct.entry_id %s\n_struct.title %s\n#\n", cifValue(id), cifValue(pdb.Title))
+	}
+
+	fields := []string{"group_PDB", "id", "type_symbol", "label_atom_id", "label_alt_id", "label_comp_id",
+		"label_asym_id", "label_entity_id", "label_seq_id", "pdbx_PDB_ins_code", "Cartn_x", "Cartn_y", "Cartn_z",
+		"occupancy", "B_iso_or_equiv", "pdbx_formal_charge", "auth_seq_id", "auth_comp_id", "auth_asym_id",
+		"auth_atom_id", "pdbx_PDB_model_num"}
+	fmt.Fprintln(bw, "loop_")
+	for _, f := range fields {
+		fmt.Fprintln(bw, "_atom_site."+f)
+	}
+
+	for _, m := range pdb.writableModels() {
+		for _, a := range m.atoms {
+			atom := a.atom
+			record, labelSeq := "ATOM", "."
+			if a.het {
+				record = "HETATM"
+			}
+			if atom.LabelSeqID != 0 {
+				labelSeq = strconv.FormatInt(atom.LabelSeqID, 10)
+			} else if atom.residue != nil && atom.residue.Position > 0 {
+				labelSeq = strconv.FormatInt(atom.residue.Position, 10)
+			}
+			labelAsym := atom.LabelAsymID
+			if labelAsym == "" {
+				labelAsym = atom.Chain
+			}
+
+			fmt.Fprintln(bw, strings.Join([]string{
+				record,
+				strconv.FormatInt(atom.Number, 10),
+				cifValue(atom.Element),
+				cifValue(atom.Name),
+				cifNullable(atom.AltLoc, "."),
+				cifValue(atom.Residue),
+				cifValue(labelAsym),
+				cifNullable(atom.EntityID, "?"),
+				labelSeq,
+				cifNullable(atom.InsertionCode, "?"),
+				strconv.FormatFloat(atom.X, 'f', 3, 64),
+				strconv.FormatFloat(atom.Y, 'f', 3, 64),
+				strconv.FormatFloat(atom.Z, 'f', 3, 64),
+				strconv.FormatFloat(atom.Occupancy, 'f', 2, 64),
+				strconv.FormatFloat(atom.BFactor, 'f', 2, 64),
+				cifFormalCharge(atom.Charge),
+				strconv.FormatInt(atom.ResidueNumber, 10),
+				cifValue(atom.Residue),
+				cifValue(atom.Chain),
+				cifValue(atom.Name),
+				strconv.FormatInt(m.number, 10),
+			}, " "))
+		}
+	}
+	fmt.Fprintln(bw, "#")
+
+	return bw.Flush()
+}
+
+// cifValue quotes a value if needed to be a valid CIF token.
+func cifValue(s string) string {
+	if s == "" {
+		return "?"
+	}
+	if strings.Contains(s, "\n") {
+		return "\n;" + s + "\n;"
+	}
+	if s == "." || s == "?" || strings.ContainsAny(s, " \t") || strings.ContainsAny(s[:1], "_#$'\"[];") ||
+		hasPrefixFold(s, "data_") || hasPrefixFold(s, "loop_") || hasPrefixFold(s, "save_") {
+		if strings.Contains(s, "' ") || strings.HasSuffix(s, "'") {
+			return "\"" + s + "\""
+		}
+		return "'" + s + "'"
+	}
+	return s
+}
+
+// cifNullable returns the value, or the given null marker if empty.
+func cifNullable(s string, null string) string {
+	if s == "" {
+		return null
+	}
+	return cifValue(s)
+}
+
+// cifFormalCharge converts a PDB column charge (i.e. 1-) to the CIF notation (i.e. -1).
+func cifFormalCharge(charge string) string {
+	if len(charge) != 2 {
+		return "?"
+	}
+	if charge[1] == '-' {
+		return "-" + charge[:1]
+	}
+	return charge[:1]
+}
+
+type writableAtom struct {
+	atom *Atom
+	het  bool
+}
+
+type writableModel struct {
+	number int64
+	atoms  []writableAtom
+}
+
+// writableModels returns the ATOM and HETATM records of each model sorted by atom number.
+// The first model is taken from Atoms and HetAtoms, the default view of the structure.
+func (pdb *PDB) writableModels() []writableModel {
+	var models []writableModel
+	add := func(number int64, atoms []*Atom, hetatms []*Atom) {
+		m := writableModel{number: number}
+		for _, a := range atoms {
+			m.atoms = append(m.atoms, writableAtom{a, a.Het})
+		}
+		for _, a := range hetatms {
+			m.atoms = append(m.atoms, writableAtom{a, true})
+		}
+		sort.SliceStable(m.atoms, func(i, j int) bool {
+			return m.atoms[i].atom.Number < m.atoms[j].atom.Number
+		})
+		models = append(models, m)
+	}
+
+	if len(pdb.Models) == 0 {
+		add(1, pdb.Atoms, pdb.HetAtoms)
+		return models
+	}
+
+	add(pdb.Models[0].Number, pdb.Atoms, pdb.HetAtoms)
+	for _, m := range pdb.Models[1:] {
+		add(m.Number, m.Atoms, m.HetAtoms)
+	}
+
+	return models
+}
+
+// NewPDBFromAtoms constructs a new instance from ATOM and HETATM records, i.e. a subset of the atoms of another structure.
+// Atoms are copied, so the new structure can be edited without affecting the original one.
+func NewPDBFromAtoms(atoms []*Atom, hetatms []*Atom) (*PDB, error) {
+	if len(atoms) == 0 {
+		return nil, errors.New("empty atoms list")
+	}
+
+	pdb := PDB{}
+	copyAtoms := func(atoms []*Atom, het bool) (copies []*Atom) {
+		for _, a := range atoms {
+			c := *a
+			c.Het = het || a.Het
+			c.residue = nil
+			c.het = nil
+			copies = append(copies, &c)
+		}
+		return copies
+	}
+
+	hetCopies := copyAtoms(hetatms, true)
+	for _, a := range hetCopies {
+		pdb.addHetGroup(a.Residue)
+	}
+	pdb.setModels(copyAtoms(atoms, false), hetCopies)
+
+	err := pdb.ExtractPDBChains()
+	if err != nil {
+		return nil, fmt.Errorf("extract PDB chains: %v", err)
+	}
+
+	return &pdb, nil
+}
@@ -0,0 +1,125 @@
+package pdb
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWritePDB(t *testing.T) {
+	raw, err := LoadTestFile("./testdata/1mso.pdb")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+
+	pdb := &PDB{AltLocPolicy: AltLocAll}
+	err = pdb.ExtractResidues(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	err = pdb.WritePDB(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every written record must be identical to the original one
+	original := make(map[string]bool)
+	for _, l := range strings.Split(string(raw), "\n") {
+		if strings.HasPrefix(l, "ATOM") || strings.HasPrefix(l, "HETATM") {
+			original[l] = true
+		}
+	}
+	for _, l := range strings.Split(buf.String(), "\n") {
+		if (strings.HasPrefix(l, "ATOM") || strings.HasPrefix(l, "HETATM")) && !original[l] {
+			t.Errorf("unexpected record %q", l)
+		}
+	}
+
+	written := &PDB{AltLocPolicy: AltLocAll}
+	err = written.ExtractResidues(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written.Atoms) != len(pdb.Atoms) || len(written.HetAtoms) != len(pdb.HetAtoms) {
+		t.Errorf("expected %d atoms, got %d", len(pdb.Atoms), len(written.Atoms))
+	}
+
+	// Values that would overflow their columns
+	a := pdb.Atoms[0]
+	for _, edit := range []func(){
+		func() { a.X = -1000 },
+		func() { a.Z = 9999.9996 },
+		func() { a.BFactor = 1000 },
+		func() { a.Occupancy = -100 },
+	} {
+		x, z, b, occ := a.X, a.Z, a.BFactor, a.Occupancy
+		edit()
+		if err := pdb.WritePDB(&bytes.Buffer{}); err == nil {
+			t.Errorf("expected error for %f %f %f %f", a.X, a.Z, a.BFactor, a.Occupancy)
+		}
+		a.X, a.Z, a.BFactor, a.Occupancy = x, z, b, occ
+	}
+	a.X, a.BFactor = 9999.999, 999.99
+	if err := pdb.WritePDB(&bytes.Buffer{}); err != nil {
+		t.Errorf("expected values at the limits to fit: %v", err)
+	}
+}
+
+func TestWriteCIF(t *testing.T) {
+	raw, err := LoadTestFile("./testdata/1mso.pdb")
+	if err != nil {
+		t.Errorf("cannot open file: %s", err)
+	}
+
+	pdb, err := NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Keep only chain B and edit B-factors
+	var atoms []*Atom
+	for _, res := range pdb.Residues["B"] {
+		atoms = append(atoms, res.Atoms...)
+	}
+	subset, err := NewPDBFromAtoms(atoms, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, atom := range subset.Atoms {
+		atom.BFactor = 50
+	}
+
+	// Only residues mapped to SEQRES have an entity sequence index
+	subset.Residues["B"][0].Position = 1
+
+	var buf bytes.Buffer
+	err = subset.WriteCIF(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := NewPDBFromCIF(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(written.Chains) != 1 || written.TotalLength != 30 || len(written.Atoms) != len(atoms) {
+		t.Errorf("expected 30 residues in chain B, got %d", written.TotalLength)
+	}
+
+	for i, atom := range written.Atoms {
+		a := atoms[i]
+		if atom.BFactor != 50 || atom.Name != a.Name || atom.X != a.X || atom.Residue != a.Residue {
+			t.Errorf("atom %d mismatch", a.Number)
+		}
+	}
+
+	if pdb.Atoms[0].BFactor == 50 {
+		t.Errorf("expected original structure to be unchanged")
+	}
+	if first, second := written.Residues["B"][0].Atoms[0], written.Residues["B"][1].Atoms[0]; first.LabelSeqID != 1 || second.LabelSeqID != 0 {
+		t.Errorf("expected label_seq_id only for the mapped residue, got %d and %d", first.LabelSeqID, second.LabelSeqID)
+	}
+}
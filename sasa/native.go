@@ -0,0 +1,157 @@
+package sasa
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/tikz/bio/pdb"
+)
+
+// Options configures the native surface calculation. Zero values are replaced by the defaults.
+type Options struct {
+	Radii       Radii   // atomic radii set, ProtOr by default
+	ProbeRadius float64 // solvent probe radius, 1.4 A by default
+	Points      int     // test points per atom, 100 by default
+	MaxASA      MaxASA  // maximum ASA table for relative values, Tien et al. 2013 by default
+}
+
+// Native calculates the solvent accessible surface area of the ATOM records of a structure in process,
+// using the Shrake-Rupley algorithm (https://doi.org/10.1016/0022-2836(73)90011-9). Hydrogens are ignored.
+// RelAll is relative to the MaxASA table, while the side chain, main chain, apolar and polar relative values
+// are relative to the NACCESS reference areas (Hubbard1993), the only ones defined by class. They are NaN for
+// non standard residues.
+func Native(p *pdb.PDB, opts Options) (sasa Results, err error) {
+	if opts.ProbeRadius == 0 {
+		opts.ProbeRadius = 1.4
+	}
+	if opts.Points == 0 {
+		opts.Points = 100
+	}
+
+	var atoms []*pdb.Atom
+	for _, a := range p.Atoms {
+		a.SASA = 0
+		if e := strings.ToUpper(a.Element); e != "H" && e != "D" {
+			atoms = append(atoms, a)
+		}
+	}
+	if len(atoms) == 0 {
+		return sasa, errors.New("empty atoms list")
+	}
+
+	areas := atomAreas(atoms, opts)
+
+	sasa.Residues = make(map[*pdb.Residue]ResidueSASA)
+	var residues []*pdb.Residue
+	for i, a := range atoms {
+		a.SASA = areas[i]
+		res := a.Parent()
+		if res == nil {
+			continue
+		}
+		r, ok := sasa.Residues[res]
+		if !ok {
+			residues = append(residues, res)
+		}
+
+		r.All += areas[i]
+		if isBackbone(a) {
+			r.Main += areas[i]
+		} else {
+			r.Side += areas[i]
+		}
+		if isPolar(a) {
+			r.Polar += areas[i]
+		} else {
+			r.Apolar += areas[i]
+		}
+		sasa.Residues[res] = r
+	}
+
+	for _, res := range residues {
+		r := sasa.Residues[res]
+		r.RelAll = math.NaN()
+		if max, ok := maxASA[opts.MaxASA][strings.ToUpper(res.Atoms[0].Residue)]; ok {
+			r.RelAll = 100 * r.All / max
+		}
+		r.RelSide, r.RelMain, r.RelApolar, r.RelPolar = math.NaN(), math.NaN(), math.NaN(), math.NaN()
+		if max, ok := classMaxASA[strings.ToUpper(res.Atoms[0].Residue)]; ok {
+			r.RelSide = 100 * r.Side / max.Side
+			r.RelMain = 100 * r.Main / max.Main
+			r.RelApolar = 100 * r.Apolar / max.Apolar
+			r.RelPolar = 100 * r.Polar / max.Polar
+		}
+		sasa.Residues[res] = r
+
+		sasa.Total += r.All
+		sasa.Side += r.Side
+		sasa.Main += r.Main
+		sasa.Apolar += r.Apolar
+		sasa.Polar += r.Polar
+	}
+
+	return sasa, nil
+}
+
+// spherePoints returns n points evenly distributed on a unit sphere, using the golden section spiral.
+func spherePoints(n int) [][3]float64 {
+	points := make([][3]float64, n)
+	inc := math.Pi * (3 - math.Sqrt(5))
+	for i := range points {
+		y := 1 - (float64(i)+0.5)*2/float64(n)
+		r := math.Sqrt(1 - y*y)
+		phi := float64(i) * inc
+		points[i] = [3]float64{math.Cos(phi) * r, y, math.Sin(phi) * r}
+	}
+	return points
+}
+
+// atomAreas returns the accessible area of each atom, as the fraction of test points on its expanded sphere
+// that are not buried by any neighbor sphere.
+func atomAreas(atoms []*pdb.Atom, opts Options) []float64 {
+	radii := make([]float64, len(atoms))
+	index := make(map[*pdb.Atom]int)
+	var maxRadius float64
+	for i, a := range atoms {
+		radii[i] = radius(a, opts.Radii) + opts.ProbeRadius
+		maxRadius = math.Max(maxRadius, radii[i])
+		index[a] = i
+	}
+
+	idx := pdb.NewIndex(atoms, 2*maxRadius)
+	points := spherePoints(opts.Points)
+	areas := make([]float64, len(atoms))
+	for i, a := range atoms {
+		var neighbors []int
+		for _, b := range idx.WithinAtom(a, radii[i]+maxRadius) {
+			j := index[b]
+			if j != i && pdb.AtomsDistance(a, b) < radii[i]+radii[j] {
+				neighbors = append(neighbors, j)
+			}
+		}
+
+		accessible := 0
+		last := 0 // the last burying neighbor is likely to bury the next point too
+		for _, p := range points {
+			x, y, z := a.X+p[0]*radii[i], a.Y+p[1]*radii[i], a.Z+p[2]*radii[i]
+			buried := false
+			for k := range neighbors {
+				n := neighbors[(k+last)%len(neighbors)]
+				b := atoms[n]
+				if (x-b.X)*(x-b.X)+(y-b.Y)*(y-b.Y)+(z-b.Z)*(z-b.Z) < radii[n]*radii[n] {
+					buried = true
+					last = (k + last) % len(neighbors)
+					break
+				}
+			}
+			if !buried {
+				accessible++
+			}
+		}
+
+		areas[i] = 4 * math.Pi * radii[i] * radii[i] * float64(accessible) / float64(len(points))
+	}
+
+	return areas
+}
@@ -0,0 +1,198 @@
+package sasa
+
+import (
+	"io/ioutil"
+	"math"
+	"os/exec"
+	"regexp"
+	"testing"
+
+	"github.com/tikz/bio/pdb"
+)
+
+func TestNative(t *testing.T) {
+	raw, err := ioutil.ReadFile("../pdb/testdata/1mso.pdb")
+	if err != nil {
+		t.Fatalf("cannot open file: %s", err)
+	}
+
+	p, err := pdb.NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A single atom is fully exposed
+	res := p.Chains["A"][1]
+	single, err := pdb.NewPDBFromAtoms(res.Atoms[1:2], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := Native(single, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := 4 * math.Pi * math.Pow(1.88+1.4, 2)
+	if math.Abs(results.Total-expected) > 1e-6 {
+		t.Errorf("expected %f for a single CA, got %f", expected, results.Total)
+	}
+	if single.Atoms[0].SASA != results.Total {
+		t.Errorf("expected atom SASA to be the total, got %f", single.Atoms[0].SASA)
+	}
+
+	protor, err := Native(p, Options{Points: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	naccess, err := Native(p, Options{Radii: NACCESS, Points: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(protor.Residues) != 102 {
+		t.Errorf("expected 102 residues, got %d", len(protor.Residues))
+	}
+	if math.Abs(protor.Total-protor.Main-protor.Side) > 1e-6 || math.Abs(protor.Total-protor.Polar-protor.Apolar) > 1e-6 {
+		t.Errorf("expected total to be the sum of its parts")
+	}
+	if math.Abs(protor.Total-naccess.Total)/protor.Total > 0.05 {
+		t.Errorf("expected similar totals for ProtOr and NACCESS radii, got %f and %f", protor.Total, naccess.Total)
+	}
+
+	for res, r := range protor.Residues {
+		// Main chain and polar values of terminal residues exceed their Ala-X-Ala references
+		if r.RelAll < 0 || r.RelAll > 150 || !(r.RelSide >= 0 && r.RelMain >= 0 && r.RelApolar >= 0 && r.RelPolar >= 0) ||
+			r.RelSide > 150 || r.RelApolar > 150 {
+			t.Errorf("unexpected relative SASA %+v in %s-%d", r, res.Chain, res.StructPosition)
+		}
+	}
+
+	// The hexamer buries surface of each chain
+	var chainA []*pdb.Atom
+	for _, res := range p.Residues["A"] {
+		chainA = append(chainA, res.Atoms...)
+	}
+	monomer, err := pdb.NewPDBFromAtoms(chainA, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alone, err := Native(monomer, Options{Points: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var inComplex float64
+	for _, res := range p.Residues["A"] {
+		inComplex += protor.Residues[res].All
+	}
+	if alone.Total <= inComplex {
+		t.Errorf("expected chain A to be more exposed alone, got %f and %f", alone.Total, inComplex)
+	}
+}
+
+// maxRelativeDeviations is the number of residues allowed to deviate more than 10 points from the FreeSASA
+// relative values, since Shrake-Rupley test points and Lee-Richards slices do not give identical areas.
+const maxRelativeDeviations = 5
+
+// TestNativeFreeSASA compares the native relative values with FreeSASA using NACCESS radii and reference areas,
+// from testdata/1mso.rsa (freesasa --format=rsa --radii=naccess) or a local FreeSASA run.
+func TestNativeFreeSASA(t *testing.T) {
+	raw, err := ioutil.ReadFile("../pdb/testdata/1mso.pdb")
+	if err != nil {
+		t.Fatalf("cannot open file: %s", err)
+	}
+
+	p, err := pdb.NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := ioutil.ReadFile("testdata/1mso.rsa")
+	if err != nil {
+		if _, err := exec.LookPath("freesasa"); err != nil {
+			t.Skip("neither testdata/1mso.rsa nor freesasa available")
+		}
+		out, err = exec.Command("freesasa", "../pdb/testdata/1mso.pdb", "--format=rsa", "--radii=naccess").Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reference, err := ParseRSA(p, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := Native(p, Options{Radii: NACCESS, MaxASA: Hubbard1993, Points: 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reference.Residues) != len(results.Residues) {
+		t.Errorf("expected %d residues, got %d", len(reference.Residues), len(results.Residues))
+	}
+	deviations := 0
+	for res, ref := range reference.Residues {
+		r := results.Residues[res]
+		rel := [][2]float64{{r.RelAll, ref.RelAll}, {r.RelSide, ref.RelSide}, {r.RelMain, ref.RelMain},
+			{r.RelApolar, ref.RelApolar}, {r.RelPolar, ref.RelPolar}}
+		for _, v := range rel {
+			if math.IsNaN(v[1]) {
+				continue
+			}
+			if math.IsNaN(v[0]) || math.Abs(v[0]-v[1]) > 10 {
+				t.Logf("%s-%d: %+v, FreeSASA %+v", res.Chain, res.StructPosition, r, ref)
+				deviations++
+				break
+			}
+		}
+	}
+	if deviations > maxRelativeDeviations {
+		t.Errorf("%d residues deviate from FreeSASA, %d allowed", deviations, maxRelativeDeviations)
+	}
+}
+
+func TestNativeModifiedResidue(t *testing.T) {
+	raw, err := ioutil.ReadFile("../pdb/testdata/1mso.pdb")
+	if err != nil {
+		t.Fatalf("cannot open file: %s", err)
+	}
+
+	// LEU B 6 as a selenomethionine in HETATM records
+	r := regexp.MustCompile("(?m)^ATOM  (.{11})LEU B   6")
+	edited := r.ReplaceAllString(string(raw), "HETATM${1}MSE B   6")
+
+	original, err := pdb.NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := pdb.NewPDBFromRaw([]byte(edited))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := Native(original, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := Native(p, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := p.ResidueAt("B", 6, "")
+	r6, ok := results.Residues[res]
+	var sum float64
+	for _, a := range res.Atoms {
+		sum += a.SASA
+	}
+	if !ok || r6.All <= 0 || math.Abs(sum-r6.All) > 1e-6 {
+		t.Errorf("unexpected SASA of the modified residue %+v", r6)
+	}
+	if len(results.Residues) != 102 || !math.IsNaN(r6.RelAll) {
+		t.Errorf("expected 102 residues and no relative SASA for MSE")
+	}
+
+	// Neighbors are buried by the modified residue as they were by the original one, with different radii
+	next := results.Residues[p.ResidueAt("B", 7, "")].All
+	if e := expected.Residues[original.ResidueAt("B", 7, "")].All; math.Abs(next-e) > 5 {
+		t.Errorf("expected %f in B-7, got %f", e, next)
+	}
+}
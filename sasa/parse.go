@@ -0,0 +1,200 @@
+package sasa
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tikz/bio/pdb"
+)
+
+// residueNumber matches a residue number with an optional insertion code, i.e. 52 or 52A.
+var residueNumber = regexp.MustCompile(`^(-?[0-9]+)([A-Za-z]?)$`)
+
+// findResidue returns the residue of the structure with the given chain and number (with optional insertion code).
+func findResidue(p *pdb.PDB, chain string, number string) (*pdb.Residue, error) {
+	m := residueNumber.FindStringSubmatch(strings.TrimSpace(number))
+	if m == nil {
+		return nil, fmt.Errorf("invalid residue number %s in chain %s", number, chain)
+	}
+	pos, _ := strconv.ParseInt(m[1], 10, 64)
+
+	res := p.ResidueAt(chain, pos, m[2])
+	if res == nil {
+		return nil, fmt.Errorf("residue %s%s in chain %s not found in structure", m[1], m[2], chain)
+	}
+	return res, nil
+}
+
+// ParseRSA parses the FreeSASA output in RSA format (--format=rsa), which is limited to single character chain IDs.
+func ParseRSA(p *pdb.PDB, out []byte) (sasa Results, err error) {
+	sasa.Residues = make(map[*pdb.Residue]ResidueSASA)
+	for _, l := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(l, "RES") && len(l) >= 80 {
+			res, err := findResidue(p, string(l[8]), l[9:14])
+			if err != nil {
+				return sasa, err
+			}
+
+			parse := func(start int, end int) float64 {
+				s := strings.TrimSpace(l[start:end])
+				if s == "N/A" {
+					return math.NaN()
+				}
+				v, _ := strconv.ParseFloat(s, 64)
+				return v
+			}
+
+			sasa.Residues[res] = ResidueSASA{
+				All:       parse(14, 22),
+				RelAll:    parse(22, 28),
+				Side:      parse(28, 35),
+				RelSide:   parse(35, 41),
+				Main:      parse(41, 48),
+				RelMain:   parse(48, 54),
+				Apolar:    parse(54, 61),
+				RelApolar: parse(61, 67),
+				Polar:     parse(67, 74),
+				RelPolar:  parse(74, 80),
+			}
+		}
+
+		if strings.HasPrefix(l, "TOTAL") {
+			f := strings.Fields(l)
+			if len(f) < 6 {
+				return sasa, fmt.Errorf("invalid TOTAL line: %s", l)
+			}
+			parse := func(s string) float64 {
+				v, _ := strconv.ParseFloat(s, 64)
+				return v
+			}
+			sasa.Total = parse(f[1])
+			sasa.Side = parse(f[2])
+			sasa.Main = parse(f[3])
+			sasa.Apolar = parse(f[4])
+			sasa.Polar = parse(f[5])
+		}
+	}
+
+	return sasa, nil
+}
+
+// freeSASAArea is the area of a structure, chain or residue in FreeSASA JSON and XML output.
+type freeSASAArea struct {
+	Total     float64 `json:"total" xml:"total,attr"`
+	Polar     float64 `json:"polar" xml:"polar,attr"`
+	Apolar    float64 `json:"apolar" xml:"apolar,attr"`
+	MainChain float64 `json:"main-chain" xml:"mainChain,attr"`
+	SideChain float64 `json:"side-chain" xml:"sideChain,attr"`
+	Unknown   float64 `json:"unknown" xml:"unknown,attr"`
+}
+
+type freeSASAAtom struct {
+	Name string  `json:"name" xml:"name,attr"`
+	Area float64 `json:"area" xml:"area,attr"`
+}
+
+type freeSASAResidue struct {
+	Name         string         `json:"name" xml:"name,attr"`
+	Number       string         `json:"number" xml:"number,attr"`
+	Area         freeSASAArea   `json:"area" xml:"area"`
+	RelativeArea *freeSASAArea  `json:"relative-area" xml:"relativeArea"`
+	Atoms        []freeSASAAtom `json:"atoms" xml:"atom"`
+}
+
+type freeSASAChain struct {
+	Label    string            `json:"label" xml:"label,attr"`
+	Residues []freeSASAResidue `json:"residues" xml:"residue"`
+}
+
+type freeSASAStructure struct {
+	Area   freeSASAArea    `json:"area" xml:"area"`
+	Chains []freeSASAChain `json:"chains" xml:"chain"`
+}
+
+// ParseJSON parses the FreeSASA output in JSON format (--format=json). Per atom values
+// are only present with --output-depth=atom, and are set on the atoms of the structure. Only the first structure is read.
+func ParseJSON(p *pdb.PDB, out []byte) (sasa Results, err error) {
+	var doc struct {
+		Results []struct {
+			Structure []freeSASAStructure `json:"structure"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		return sasa, fmt.Errorf("parse JSON: %v", err)
+	}
+	if len(doc.Results) == 0 || len(doc.Results[0].Structure) == 0 {
+		return sasa, fmt.Errorf("no structure in results")
+	}
+
+	return freeSASAResults(p, doc.Results[0].Structure[0])
+}
+
+// ParseXML parses the FreeSASA output in XML format (--format=xml). Per atom values
+// are only present with --output-depth=atom, and are set on the atoms of the structure. Only the first structure is read.
+func ParseXML(p *pdb.PDB, out []byte) (sasa Results, err error) {
+	var doc struct {
+		Results []struct {
+			Structure []freeSASAStructure `xml:"structure"`
+		} `xml:"result"`
+	}
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		return sasa, fmt.Errorf("parse XML: %v", err)
+	}
+	if len(doc.Results) == 0 || len(doc.Results[0].Structure) == 0 {
+		return sasa, fmt.Errorf("no structure in results")
+	}
+
+	return freeSASAResults(p, doc.Results[0].Structure[0])
+}
+
+// freeSASAResults matches the JSON or XML results to the residues and atoms of the structure.
+func freeSASAResults(p *pdb.PDB, s freeSASAStructure) (sasa Results, err error) {
+	sasa.Total = s.Area.Total
+	sasa.Side = s.Area.SideChain
+	sasa.Main = s.Area.MainChain
+	sasa.Apolar = s.Area.Apolar
+	sasa.Polar = s.Area.Polar
+	sasa.Unknown = s.Area.Unknown
+	sasa.Residues = make(map[*pdb.Residue]ResidueSASA)
+
+	for _, chain := range s.Chains {
+		for _, r := range chain.Residues {
+			res, err := findResidue(p, chain.Label, r.Number)
+			if err != nil {
+				return sasa, err
+			}
+
+			rel := freeSASAArea{math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()}
+			if r.RelativeArea != nil {
+				rel = *r.RelativeArea
+			}
+			sasa.Residues[res] = ResidueSASA{
+				All:       r.Area.Total,
+				RelAll:    rel.Total,
+				Side:      r.Area.SideChain,
+				RelSide:   rel.SideChain,
+				Main:      r.Area.MainChain,
+				RelMain:   rel.MainChain,
+				Apolar:    r.Area.Apolar,
+				RelApolar: rel.Apolar,
+				Polar:     r.Area.Polar,
+				RelPolar:  rel.Polar,
+			}
+
+			for _, a := range r.Atoms {
+				atom := res.Atom(strings.TrimSpace(a.Name))
+				if atom == nil {
+					return sasa, fmt.Errorf("atom %s of residue %s in chain %s not found in structure", a.Name, r.Number, chain.Label)
+				}
+				atom.SASA = a.Area
+			}
+		}
+	}
+
+	return sasa, nil
+}
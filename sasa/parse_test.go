@@ -0,0 +1,162 @@
+package sasa
+
+import (
+	"io/ioutil"
+	"math"
+	"testing"
+
+	"github.com/tikz/bio/pdb"
+)
+
+const rsaOutput = `REM  FreeSASA 2.0.3
+REM  RES _ NUM      All-atoms   Total-Side   Main-Chain    Non-polar    All polar
+REM                ABS   REL    ABS   REL    ABS   REL    ABS   REL    ABS   REL
+RES PHE B  24   150.50  62.7 120.25  60.1  30.25  70.2 110.00  65.0  40.50  55.3
+RES TYR B  26    80.00  30.4  60.00  25.0  20.00   N/A  50.00  30.0  30.00  40.0
+END  Absolute sums over single chains surface
+CHAIN  1 B      230.5        180.2         50.2        160.0         70.5
+END  Absolute sums over all chains
+TOTAL           230.5        180.2         50.2        160.0         70.5
+`
+
+const jsonOutput = `{
+  "source": "FreeSASA 2.0.3",
+  "length-unit": "Ångström",
+  "results": [{
+    "input": "test.pdb",
+    "classifier": "ProtOr",
+    "structure": [{
+      "input": "test.pdb",
+      "chain-labels": "BB",
+      "area": {"total": 230.5, "polar": 70.5, "apolar": 160.0, "main-chain": 50.2, "side-chain": 180.2},
+      "chains": [{
+        "label": "BB",
+        "n-residues": 2,
+        "residues": [{
+          "name": "PHE",
+          "number": "24A",
+          "area": {"total": 150.5, "polar": 40.5, "apolar": 110.0, "main-chain": 30.25, "side-chain": 120.25},
+          "relative-area": {"total": 62.7, "polar": 55.3, "apolar": 65.0, "main-chain": 70.2, "side-chain": 60.1},
+          "n-atoms": 2,
+          "atoms": [
+            {"name": "N", "area": 10.5, "is-polar": true, "is-main-chain": true, "radius": 1.64},
+            {"name": "CZ", "area": 30.0, "is-polar": false, "is-main-chain": false, "radius": 1.76}
+          ]
+        }, {
+          "name": "TYR",
+          "number": "26",
+          "area": {"total": 80.0, "polar": 30.0, "apolar": 50.0, "main-chain": 20.0, "side-chain": 60.0}
+        }]
+      }]
+    }]
+  }]
+}`
+
+const xmlOutput = `<?xml version="1.0" encoding="UTF-8"?>
+<results xmlns="http://freesasa.github.io/" source="FreeSASA 2.0.3" lengthUnit="Ångström">
+  <result classifier="ProtOr" input="test.pdb">
+    <structure chains="BB" model="1">
+      <area total="230.5" polar="70.5" apolar="160.0" mainChain="50.2" sideChain="180.2"/>
+      <chain label="BB" nResidues="2">
+        <residue name="PHE" number="24A">
+          <area total="150.5" polar="40.5" apolar="110.0" mainChain="30.25" sideChain="120.25"/>
+          <relativeArea total="62.7" polar="55.3" apolar="65.0" mainChain="70.2" sideChain="60.1"/>
+          <atom name="N" area="10.5" isPolar="true" isMainChain="true" radius="1.64"/>
+          <atom name="CZ" area="30.0" isPolar="false" isMainChain="false" radius="1.76"/>
+        </residue>
+        <residue name="TYR" number="26">
+          <area total="80.0" polar="30.0" apolar="50.0" mainChain="20.0" sideChain="60.0"/>
+        </residue>
+      </chain>
+    </structure>
+  </result>
+</results>`
+
+func TestParseRSA(t *testing.T) {
+	raw, err := ioutil.ReadFile("../pdb/testdata/1mso.pdb")
+	if err != nil {
+		t.Fatalf("cannot open file: %s", err)
+	}
+
+	p, err := pdb.NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := ParseRSA(p, []byte(rsaOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, ok := results.Residues[p.Chains["B"][24]]
+	if !ok || r.All != 150.5 || r.RelAll != 62.7 || r.Side != 120.25 || r.RelPolar != 55.3 {
+		t.Errorf("unexpected B-24 SASA %+v", r)
+	}
+	if r := results.Residues[p.Chains["B"][26]]; !math.IsNaN(r.RelMain) {
+		t.Errorf("expected NaN for N/A")
+	}
+	if results.Total != 230.5 || results.Polar != 70.5 {
+		t.Errorf("unexpected totals %f %f", results.Total, results.Polar)
+	}
+
+	_, err = ParseRSA(p, []byte(rsaOutput+"RES PHE X  24    80.00  30.4  60.00  25.0  20.00  50.0  50.00  30.0  30.00  40.0\n"))
+	if err == nil {
+		t.Errorf("expected error for missing residue")
+	}
+}
+
+func TestParseJSONXML(t *testing.T) {
+	raw, err := ioutil.ReadFile("../pdb/testdata/1mso.pdb")
+	if err != nil {
+		t.Fatalf("cannot open file: %s", err)
+	}
+
+	p, err := pdb.NewPDBFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Chain B renamed to BB, with an insertion code in residue 24
+	var atoms []*pdb.Atom
+	for _, res := range p.Residues["B"] {
+		for _, a := range res.Atoms {
+			c := *a
+			c.Chain = "BB"
+			if c.ResidueNumber == 24 {
+				c.InsertionCode = "A"
+			}
+			atoms = append(atoms, &c)
+		}
+	}
+	p, err = pdb.NewPDBFromAtoms(atoms, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	phe := p.ResidueAt("BB", 24, "A")
+
+	for name, out := range map[string]string{"JSON": jsonOutput, "XML": xmlOutput} {
+		parse := ParseJSON
+		if name == "XML" {
+			parse = ParseXML
+		}
+
+		results, err := parse(p, []byte(out))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		r, ok := results.Residues[phe]
+		if !ok || r.All != 150.5 || r.RelAll != 62.7 || r.Main != 30.25 || r.RelMain != 70.2 {
+			t.Errorf("%s: unexpected BB-24A SASA %+v", name, r)
+		}
+		if r := results.Residues[p.ResidueAt("BB", 26, "")]; r.All != 80 || !math.IsNaN(r.RelAll) {
+			t.Errorf("%s: unexpected BB-26 SASA %+v", name, r)
+		}
+		if phe.Atom("CZ").SASA != 30 || phe.Atom("N").SASA != 10.5 || phe.Atom("CA").SASA != 0 {
+			t.Errorf("%s: unexpected atom SASA", name)
+		}
+		if results.Total != 230.5 || results.Main != 50.2 {
+			t.Errorf("%s: unexpected totals %f %f", name, results.Total, results.Main)
+		}
+	}
+}
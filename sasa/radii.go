@@ -0,0 +1,173 @@
+package sasa
+
+import (
+	"strings"
+
+	"github.com/tikz/bio/pdb"
+)
+
+// Radii is a set of atomic radii for surface calculation.
+type Radii int
+
+const (
+	// ProtOr are the radii of Tsai et al. 1999, by atom type and number of bonded hydrogens.
+	// https://doi.org/10.1006/jmbi.1999.3110
+	ProtOr Radii = iota
+	// NACCESS are the radii of the NACCESS vdw.radii file, based on Chothia 1976.
+	NACCESS
+)
+
+// MaxASA is a table of maximum accessible surface area per residue, for relative values.
+type MaxASA int
+
+const (
+	// Tien2013 are the theoretical values of Tien et al. 2013. https://doi.org/10.1371/journal.pone.0080635
+	Tien2013 MaxASA = iota
+	// Miller1987 are the values of Miller et al. 1987. https://doi.org/10.1016/0022-2836(87)90038-6
+	Miller1987
+	// Hubbard1993 are the Ala-X-Ala values of the NACCESS standard.data file, also used by FreeSASA with NACCESS radii.
+	Hubbard1993
+)
+
+var maxASA = map[MaxASA]map[string]float64{
+	Tien2013: {
+		"ALA": 129, "ARG": 274, "ASN": 195, "ASP": 193, "CYS": 167, "GLN": 225, "GLU": 223, "GLY": 104, "HIS": 224, "ILE": 197,
+		"LEU": 201, "LYS": 236, "MET": 224, "PHE": 240, "PRO": 159, "SER": 155, "THR": 172, "TRP": 285, "TYR": 263, "VAL": 174,
+	},
+	Miller1987: {
+		"ALA": 113, "ARG": 241, "ASN": 158, "ASP": 151, "CYS": 140, "GLN": 189, "GLU": 183, "GLY": 85, "HIS": 194, "ILE": 182,
+		"LEU": 180, "LYS": 211, "MET": 204, "PHE": 218, "PRO": 143, "SER": 122, "THR": 146, "TRP": 259, "TYR": 229, "VAL": 160,
+	},
+	Hubbard1993: {},
+}
+
+// classMaxASA are the reference areas of the NACCESS standard.data file by residue and class,
+// the only table that defines side chain, main chain, apolar and polar values.
+var classMaxASA = map[string]ResidueSASA{
+	"ALA": {All: 107.95, Side: 69.41, Main: 38.54, Apolar: 71.38, Polar: 36.58},
+	"ARG": {All: 238.76, Side: 201.38, Main: 37.38, Apolar: 89.14, Polar: 149.62},
+	"ASN": {All: 143.94, Side: 106.24, Main: 37.70, Apolar: 47.18, Polar: 96.76},
+	"ASP": {All: 140.39, Side: 102.44, Main: 37.95, Apolar: 48.23, Polar: 92.16},
+	"CYS": {All: 134.28, Side: 96.16, Main: 38.12, Apolar: 103.84, Polar: 30.44},
+	"GLN": {All: 178.50, Side: 140.94, Main: 37.56, Apolar: 66.43, Polar: 112.06},
+	"GLU": {All: 172.25, Side: 134.91, Main: 37.34, Apolar: 68.51, Polar: 103.74},
+	"GLY": {All: 80.10, Side: 32.33, Main: 47.77, Apolar: 47.95, Polar: 32.15},
+	"HIS": {All: 182.88, Side: 144.16, Main: 38.72, Apolar: 93.56, Polar: 89.32},
+	"ILE": {All: 175.12, Side: 137.35, Main: 37.77, Apolar: 144.48, Polar: 30.64},
+	"LEU": {All: 178.63, Side: 140.76, Main: 37.87, Apolar: 144.36, Polar: 34.27},
+	"LYS": {All: 200.81, Side: 162.48, Main: 38.33, Apolar: 130.23, Polar: 70.58},
+	"MET": {All: 194.15, Side: 156.07, Main: 38.08, Apolar: 151.90, Polar: 42.25},
+	"PHE": {All: 199.48, Side: 161.55, Main: 37.93, Apolar: 159.57, Polar: 39.91},
+	"PRO": {All: 136.13, Side: 99.64, Main: 36.49, Apolar: 110.40, Polar: 25.73},
+	"SER": {All: 116.50, Side: 77.88, Main: 38.62, Apolar: 52.64, Polar: 63.86},
+	"THR": {All: 139.27, Side: 101.18, Main: 38.09, Apolar: 81.13, Polar: 58.14},
+	"TRP": {All: 249.36, Side: 209.62, Main: 39.74, Apolar: 195.56, Polar: 53.80},
+	"TYR": {All: 212.76, Side: 174.15, Main: 38.61, Apolar: 156.59, Polar: 56.17},
+	"VAL": {All: 151.44, Side: 114.24, Main: 37.20, Apolar: 114.76, Polar: 36.68},
+}
+
+func init() {
+	for res, max := range classMaxASA {
+		maxASA[Hubbard1993][res] = max.All
+	}
+}
+
+// sp2Carbons are the side chain trigonal carbons of each residue, to whether they are bonded to a hydrogen.
+var sp2Carbons = map[string]map[string]bool{
+	"ARG": {"CZ": false},
+	"ASN": {"CG": false},
+	"ASP": {"CG": false},
+	"GLN": {"CD": false},
+	"GLU": {"CD": false},
+	"HIS": {"CG": false, "CD2": true, "CE1": true},
+	"PHE": {"CG": false, "CD1": true, "CD2": true, "CE1": true, "CE2": true, "CZ": true},
+	"TYR": {"CG": false, "CD1": true, "CD2": true, "CE1": true, "CE2": true, "CZ": false},
+	"TRP": {"CG": false, "CD1": true, "CD2": false, "CE2": false, "CE3": true, "CZ2": true, "CZ3": true, "CH2": true},
+}
+
+// hydroxylOxygens are the side chain oxygens bonded to a hydrogen.
+var hydroxylOxygens = map[string]string{"SER": "OG", "THR": "OG1", "TYR": "OH"}
+
+// elementRadii are used for atoms not covered by the radii sets, such as in modified residues.
+var elementRadii = map[string]float64{"C": 1.7, "N": 1.55, "O": 1.52, "S": 1.8, "SE": 1.9, "P": 1.8}
+
+func isBackbone(a *pdb.Atom) bool {
+	switch a.Name {
+	case "N", "CA", "C", "O", "OXT":
+		return true
+	}
+	return false
+}
+
+// radius returns the radius of the atom in the given set.
+func radius(a *pdb.Atom, set Radii) float64 {
+	element := strings.ToUpper(a.Element)
+	if element == "" && len(a.Name) > 0 {
+		element = a.Name[:1]
+	}
+	residue := strings.ToUpper(a.Residue)
+
+	_, _, abbrv1 := pdb.AminoacidNames(residue)
+	if abbrv1 == "X" && residue != "MSE" {
+		if r, ok := elementRadii[element]; ok {
+			return r
+		}
+		return 1.8
+	}
+
+	hydrogen, sp2 := sp2Carbons[residue][a.Name]
+	if a.Name == "C" {
+		sp2 = true
+	}
+
+	switch set {
+	case NACCESS:
+		switch {
+		case element == "C" && sp2:
+			return 1.76
+		case element == "C":
+			return 1.87
+		case residue == "LYS" && a.Name == "NZ":
+			return 1.50
+		case element == "N":
+			return 1.65
+		case element == "O":
+			return 1.40
+		case element == "S":
+			return 1.85
+		case element == "SE":
+			return 1.80
+		}
+
+	default:
+		switch {
+		case element == "C" && sp2 && hydrogen:
+			return 1.76
+		case element == "C" && sp2:
+			return 1.61
+		case element == "C":
+			return 1.88
+		case element == "N":
+			return 1.64
+		case element == "O" && hydroxylOxygens[residue] == a.Name:
+			return 1.46
+		case element == "O":
+			return 1.42
+		case element == "S":
+			return 1.77
+		case element == "SE":
+			return 1.90
+		}
+	}
+
+	if r, ok := elementRadii[element]; ok {
+		return r
+	}
+	return 1.8
+}
+
+// isPolar reports whether the atom is polar (nitrogen or oxygen).
+func isPolar(a *pdb.Atom) bool {
+	element := strings.ToUpper(a.Element)
+	return element == "N" || element == "O"
+}
@@ -0,0 +1,607 @@
+package uniprot
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Reference: https://web.expasy.org/docs/userman.html
+
+// Entry is a complete UniProtKB flat file (TXT) entry.
+type Entry struct {
+	Name        string       `json:"name"`       // entry name, i.e. INS_HUMAN
+	Reviewed    bool         `json:"reviewed"`   // Swiss-Prot if true, TrEMBL otherwise
+	Accessions  []string     `json:"accessions"` // primary accession first, then secondary ones
+	Description *Description `json:"description"`
+	Genes       []*Gene      `json:"genes"`
+	Organism    string       `json:"organism"`   // scientific name, with the common one in parentheses
+	Lineage     []string     `json:"lineage"`    // taxonomic classification, from the OC lines
+	TaxonomyID  int64        `json:"taxonomyId"` // NCBI taxonomy identifier
+	References  []*Reference `json:"references"`
+	Comments    []*Comment   `json:"comments"`
+	CrossRefs   []*CrossRef  `json:"crossRefs"`
+	Keywords    []string     `json:"keywords"`
+	Features    []*Feature   `json:"features"`
+	Sequence    string       `json:"sequence"`  // canonical sequence
+	MolWeight   int64        `json:"molWeight"` // in Da
+	CRC64       string       `json:"crc64"`
+}
+
+// Description contains the protein names from the DE lines.
+type Description struct {
+	Name       string   `json:"name"` // recommended full name, or the submitted one for TrEMBL entries
+	ShortNames []string `json:"shortNames"`
+	AltNames   []string `json:"altNames"`
+	EC         []string `json:"ec"`
+	Contains   []string `json:"contains"` // full names in the Contains and Includes sections, i.e. chains of a precursor
+	Flags      []string `json:"flags"`    // i.e. Precursor or Fragment
+}
+
+// Gene contains the names of a single gene from the GN lines.
+type Gene struct {
+	Name              string   `json:"name"`
+	Synonyms          []string `json:"synonyms"`
+	OrderedLocusNames []string `json:"orderedLocusNames"`
+	ORFNames          []string `json:"orfNames"`
+}
+
+// Reference is a single citation from the RN, RP, RC, RX, RG, RA, RT and RL lines.
+type Reference struct {
+	Number   int    `json:"number"`
+	Position string `json:"position"` // extent of the work, i.e. NUCLEOTIDE SEQUENCE [MRNA]
+	Comment  string `json:"comment"`
+	PubMed   string `json:"pubmed"`
+	DOI      string `json:"doi"`
+	Group    string `json:"group"`
+	Authors  string `json:"authors"`
+	Title    string `json:"title"`
+	Location string `json:"location"` // journal or submission
+}
+
+// Comment is a single topic from the CC lines, i.e. FUNCTION.
+type Comment struct {
+	Topic string `json:"topic"`
+	Text  string `json:"text"`
+}
+
+// CrossRef is a single database cross-reference from the DR lines.
+type CrossRef struct {
+	Database   string   `json:"database"`
+	ID         string   `json:"id"`
+	Properties []string `json:"properties"`
+	Isoform    string   `json:"isoform"` // only if the cross-reference is specific to an isoform
+}
+
+// Feature is a single annotation from the FT lines.
+type Feature struct {
+	Type       string            `json:"type"` // i.e. VARIANT or DISULFID
+	Location   Location          `json:"location"`
+	Note       string            `json:"note"`
+	Evidence   string            `json:"evidence"`
+	ID         string            `json:"id"`
+	Qualifiers map[string]string `json:"qualifiers"` // all qualifiers, note, evidence and ID included
+}
+
+// Location is the position or range of a feature. Positions are 0 if unknown, and modifiers are < or > when the
+// feature extends beyond the position, or ? when the position is uncertain.
+type Location struct {
+	Start         int64  `json:"start"`
+	End           int64  `json:"end"`
+	StartModifier string `json:"startModifier"`
+	EndModifier   string `json:"endModifier"`
+	Isoform       string `json:"isoform"` // only if the location refers to another isoform sequence
+}
+
+// Known returns true if both ends of the location are exact positions in the canonical sequence.
+func (l Location) Known() bool {
+	return l.Start > 0 && l.End > 0 && l.StartModifier == "" && l.EndModifier == "" && l.Isoform == ""
+}
+
+var (
+	evidenceTags = regexp.MustCompile(` ?\{[^}]*\}`)
+	taxonomyID   = regexp.MustCompile(`NCBI_TaxID=([0-9]+)`)
+)
+
+// ParseEntry parses a single UniProtKB flat file entry.
+func ParseEntry(raw []byte) (*Entry, error) {
+	entries, err := ReadEntries(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("no UniProt entry found")
+	}
+	return entries[0], nil
+}
+
+// ReadEntries parses all the entries from a UniProtKB flat file stream, each one ending with a // line.
+func ReadEntries(r io.Reader) ([]*Entry, error) {
+	var entries []*Entry
+	p := &entryParser{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if line == "" {
+			continue
+		}
+		if line == "//" {
+			e, err := p.finish()
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", n, err)
+			}
+			entries = append(entries, e)
+			p = &entryParser{}
+			continue
+		}
+
+		err := p.parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read UniProt TXT: %v", err)
+	}
+	if p.entry != nil {
+		return nil, errors.New("unterminated entry")
+	}
+
+	return entries, nil
+}
+
+// entryParser accumulates the lines of a single entry, as most line types span multiple lines.
+type entryParser struct {
+	entry    *Entry
+	length   int
+	section  bool   // inside a Contains or Includes DE section
+	category string // current DE name category, i.e. RecName or AltName
+	gn       []string
+	os       []string
+	oc       []string
+	kw       []string
+	ref      *Reference
+	ra, rt   []string
+	rl       []string
+	comment  *Comment
+	ccDone   bool // past the copyright block
+	feature  *Feature
+	qualKey  string
+	qualVal  []string
+	sequence strings.Builder
+}
+
+func (p *entryParser) parseLine(line string) error {
+	code, data := line, ""
+	if len(line) > 2 {
+		code = line[:2]
+	}
+	if len(line) > 5 {
+		data = line[5:]
+	}
+
+	if p.entry == nil {
+		if code != "ID" {
+			return fmt.Errorf("expected ID line, got %s", code)
+		}
+		p.entry = &Entry{Description: &Description{}}
+	}
+	e := p.entry
+
+	switch code {
+	case "ID":
+		fields := strings.Fields(data)
+		if len(fields) < 3 {
+			return fmt.Errorf("invalid ID line: %s", line)
+		}
+		e.Name = fields[0]
+		e.Reviewed = strings.TrimSuffix(fields[1], ";") == "Reviewed"
+		p.length, _ = strconv.Atoi(fields[2])
+	case "AC":
+		for _, ac := range strings.Split(data, ";") {
+			if ac = strings.TrimSpace(ac); ac != "" {
+				e.Accessions = append(e.Accessions, ac)
+			}
+		}
+	case "DE":
+		p.parseDE(data)
+	case "GN":
+		if strings.TrimSpace(data) == "and" {
+			p.flushGene()
+		} else {
+			p.gn = append(p.gn, strings.TrimSpace(data))
+		}
+	case "OS":
+		p.os = append(p.os, strings.TrimSpace(data))
+	case "OC":
+		p.oc = append(p.oc, strings.TrimSpace(data))
+	case "OX":
+		m := taxonomyID.FindStringSubmatch(data)
+		if m != nil {
+			e.TaxonomyID, _ = strconv.ParseInt(m[1], 10, 64)
+		}
+	case "RN":
+		p.flushReference()
+		p.ref = &Reference{}
+		if fields := strings.Fields(data); len(fields) > 0 {
+			p.ref.Number, _ = strconv.Atoi(strings.Trim(fields[0], "[]"))
+		}
+	case "RP", "RC", "RX", "RG", "RA", "RT", "RL":
+		if p.ref == nil {
+			return fmt.Errorf("%s line before RN", code)
+		}
+		p.parseReference(code, strings.TrimSpace(data))
+	case "CC":
+		p.parseCC(data)
+	case "DR":
+		e.CrossRefs = append(e.CrossRefs, parseCrossRef(data))
+	case "KW":
+		p.kw = append(p.kw, strings.TrimSpace(data))
+	case "FT":
+		return p.parseFT(line)
+	case "SQ":
+		fields := strings.Fields(data)
+		for i, f := range fields {
+			switch strings.TrimSuffix(f, ";") {
+			case "MW":
+				e.MolWeight, _ = strconv.ParseInt(fields[i-1], 10, 64)
+			case "CRC64":
+				e.CRC64 = fields[i-1]
+			}
+		}
+	case "  ":
+		p.sequence.WriteString(strings.Join(strings.Fields(data), ""))
+	}
+
+	return nil
+}
+
+// parseDE parses the protein names, i.e. RecName: Full=Insulin; or the indented Short= and EC= lines.
+func (p *entryParser) parseDE(data string) {
+	d := p.entry.Description
+	text := strings.TrimSpace(data)
+	switch text {
+	case "Contains:", "Includes:":
+		p.section = true
+		return
+	}
+
+	category := ""
+	if i := strings.Index(text, ": "); i > 0 && !strings.Contains(text[:i], "=") {
+		category, text = text[:i], text[i+2:]
+	}
+	if category == "Flags" {
+		for _, f := range strings.Split(text, ";") {
+			if f = strings.TrimSpace(evidenceTags.ReplaceAllString(f, "")); f != "" {
+				d.Flags = append(d.Flags, f)
+			}
+		}
+		return
+	}
+	if category != "" {
+		p.category = category
+	}
+	current := p.category
+
+	for _, field := range strings.Split(text, ";") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		value := strings.TrimSpace(evidenceTags.ReplaceAllString(kv[1], ""))
+		switch {
+		case p.section:
+			if kv[0] == "Full" && (current == "RecName" || current == "SubName") {
+				d.Contains = append(d.Contains, value)
+			}
+		case kv[0] == "Full" && (current == "RecName" || current == "SubName"):
+			if d.Name == "" {
+				d.Name = value
+			}
+		case kv[0] == "Full" && current == "AltName":
+			d.AltNames = append(d.AltNames, value)
+		case kv[0] == "Short":
+			d.ShortNames = append(d.ShortNames, value)
+		case kv[0] == "EC":
+			d.EC = append(d.EC, value)
+		}
+	}
+}
+
+// flushGene parses the accumulated GN lines of a single gene, i.e. Name=INS; Synonyms=A, B;
+func (p *entryParser) flushGene() {
+	if len(p.gn) == 0 {
+		return
+	}
+
+	g := &Gene{}
+	for _, field := range strings.Split(strings.Join(p.gn, " "), ";") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		var values []string
+		for _, v := range strings.Split(kv[1], ",") {
+			if v = strings.TrimSpace(evidenceTags.ReplaceAllString(v, "")); v != "" {
+				values = append(values, v)
+			}
+		}
+		switch kv[0] {
+		case "Name":
+			if len(values) > 0 {
+				g.Name = values[0]
+			}
+		case "Synonyms":
+			g.Synonyms = append(g.Synonyms, values...)
+		case "OrderedLocusNames":
+			g.OrderedLocusNames = append(g.OrderedLocusNames, values...)
+		case "ORFNames":
+			g.ORFNames = append(g.ORFNames, values...)
+		}
+	}
+	p.entry.Genes = append(p.entry.Genes, g)
+	p.gn = nil
+}
+
+func (p *entryParser) parseReference(code string, data string) {
+	switch code {
+	case "RP":
+		p.ref.Position = strings.TrimSuffix(joinLine(p.ref.Position, data), ".")
+	case "RC":
+		p.ref.Comment = joinLine(p.ref.Comment, data)
+	case "RX":
+		for _, field := range strings.Split(data, ";") {
+			kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			switch kv[0] {
+			case "PubMed":
+				p.ref.PubMed = kv[1]
+			case "DOI":
+				p.ref.DOI = kv[1]
+			}
+		}
+	case "RG":
+		p.ref.Group = strings.TrimSuffix(joinLine(p.ref.Group, data), ";")
+	case "RA":
+		p.ra = append(p.ra, data)
+	case "RT":
+		p.rt = append(p.rt, data)
+	case "RL":
+		p.rl = append(p.rl, data)
+	}
+}
+
+func (p *entryParser) flushReference() {
+	if p.ref == nil {
+		return
+	}
+	p.ref.Authors = strings.TrimSuffix(strings.Join(p.ra, " "), ";")
+	p.ref.Title = strings.Trim(strings.TrimSuffix(strings.Join(p.rt, " "), ";"), "\"")
+	p.ref.Location = strings.Join(p.rl, " ")
+	p.entry.References = append(p.entry.References, p.ref)
+	p.ref, p.ra, p.rt, p.rl = nil, nil, nil, nil
+}
+
+// parseCC parses the comment topics, i.e. -!- FUNCTION: ..., ignoring the copyright block.
+func (p *entryParser) parseCC(data string) {
+	text := strings.TrimSpace(data)
+	switch {
+	case p.ccDone:
+		return
+	case strings.HasPrefix(text, "---"):
+		p.ccDone = true
+	case strings.HasPrefix(text, "-!- "):
+		text = strings.TrimPrefix(text, "-!- ")
+		p.comment = &Comment{Topic: text}
+		if i := strings.Index(text, ":"); i >= 0 {
+			p.comment.Topic, p.comment.Text = text[:i], strings.TrimSpace(text[i+1:])
+		}
+		p.entry.Comments = append(p.entry.Comments, p.comment)
+	case p.comment != nil:
+		p.comment.Text = joinLine(p.comment.Text, text)
+	}
+}
+
+// parseCrossRef parses a DR line, i.e. Pfam; PF00049; Insulin; 1.
+func parseCrossRef(data string) *CrossRef {
+	x := &CrossRef{}
+	data = strings.TrimSpace(data)
+	if i := strings.LastIndex(data, ". ["); i >= 0 && strings.HasSuffix(data, "]") {
+		x.Isoform = data[i+3 : len(data)-1]
+		data = data[:i]
+	}
+	fields := strings.Split(strings.TrimSuffix(data, "."), ";")
+	for i, f := range fields {
+		fields[i] = strings.TrimSpace(f)
+	}
+	x.Database = fields[0]
+	if len(fields) > 1 {
+		x.ID = fields[1]
+	}
+	if len(fields) > 2 {
+		x.Properties = fields[2:]
+	}
+	return x
+}
+
+// parseFT parses a feature line, with the type in columns 6-13 and the location from column 22, or a qualifier
+// of the current feature, i.e. /note="...", possibly continued in the following lines.
+func (p *entryParser) parseFT(line string) error {
+	key, data := "", ""
+	if len(line) > 21 {
+		key, data = strings.TrimSpace(line[5:21]), strings.TrimSpace(line[21:])
+	} else if len(line) > 5 {
+		key = strings.TrimSpace(line[5:])
+	}
+
+	if key != "" {
+		p.flushQualifier()
+		loc, err := parseLocation(data)
+		if err != nil {
+			return fmt.Errorf("feature %s: %v", key, err)
+		}
+		p.feature = &Feature{Type: key, Location: loc, Qualifiers: make(map[string]string)}
+		p.entry.Features = append(p.entry.Features, p.feature)
+		return nil
+	}
+
+	if p.feature == nil {
+		return errors.New("feature qualifier without feature")
+	}
+	if strings.HasPrefix(data, "/") && (p.qualKey == "" || p.qualifierClosed()) {
+		p.flushQualifier()
+		kv := strings.SplitN(data[1:], "=", 2)
+		p.qualKey = kv[0]
+		if len(kv) == 2 {
+			p.qualVal = []string{kv[1]}
+		} else {
+			p.qualVal = []string{""}
+		}
+		return nil
+	}
+	if p.qualKey == "" {
+		return fmt.Errorf("invalid feature line: %s", line)
+	}
+	p.qualVal = append(p.qualVal, data)
+	return nil
+}
+
+// qualifierClosed returns true if the current qualifier value is complete, either unquoted or with both quotes.
+func (p *entryParser) qualifierClosed() bool {
+	v := strings.Join(p.qualVal, " ")
+	if !strings.HasPrefix(v, "\"") {
+		return true
+	}
+	return len(v) > 1 && strings.HasSuffix(v, "\"")
+}
+
+func (p *entryParser) flushQualifier() {
+	if p.feature == nil || p.qualKey == "" {
+		return
+	}
+	v := strings.Trim(strings.Join(p.qualVal, " "), "\"")
+	p.feature.Qualifiers[p.qualKey] = v
+	switch p.qualKey {
+	case "note":
+		p.feature.Note = v
+	case "evidence":
+		p.feature.Evidence = v
+	case "id":
+		p.feature.ID = v
+	}
+	p.qualKey, p.qualVal = "", nil
+}
+
+// parseLocation parses a feature location, i.e. 12, 25..54, <1..>20, ?..30 or P01308-2:10..20.
+func parseLocation(s string) (Location, error) {
+	var loc Location
+	if i := strings.Index(s, ":"); i >= 0 {
+		loc.Isoform, s = s[:i], s[i+1:]
+	}
+
+	ends := strings.SplitN(s, "..", 2)
+	var err error
+	loc.Start, loc.StartModifier, err = parsePosition(ends[0])
+	if err != nil {
+		return loc, err
+	}
+	if len(ends) == 1 {
+		loc.End, loc.EndModifier = loc.Start, loc.StartModifier
+		return loc, nil
+	}
+	loc.End, loc.EndModifier, err = parsePosition(ends[1])
+	return loc, err
+}
+
+func parsePosition(s string) (int64, string, error) {
+	modifier := ""
+	if s != "" && strings.ContainsAny(s[:1], "<>?") {
+		modifier, s = s[:1], s[1:]
+	}
+	if s == "" {
+		if modifier == "?" {
+			return 0, modifier, nil
+		}
+		return 0, modifier, errors.New("empty position")
+	}
+	pos, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, modifier, fmt.Errorf("invalid position %s", s)
+	}
+	return pos, modifier, nil
+}
+
+// finish flushes the multi-line fields and validates the entry at its // line.
+func (p *entryParser) finish() (*Entry, error) {
+	e := p.entry
+	if e == nil {
+		return nil, errors.New("entry without ID line")
+	}
+
+	p.flushGene()
+	p.flushReference()
+	p.flushQualifier()
+
+	e.Organism = strings.TrimSuffix(strings.Join(p.os, " "), ".")
+	for _, taxon := range strings.Split(strings.TrimSuffix(strings.Join(p.oc, " "), "."), ";") {
+		if taxon = strings.TrimSpace(taxon); taxon != "" {
+			e.Lineage = append(e.Lineage, taxon)
+		}
+	}
+	for _, kw := range strings.Split(strings.TrimSuffix(strings.Join(p.kw, " "), "."), ";") {
+		if kw = strings.TrimSpace(evidenceTags.ReplaceAllString(kw, "")); kw != "" {
+			e.Keywords = append(e.Keywords, kw)
+		}
+	}
+
+	e.Sequence = p.sequence.String()
+	if len(e.Accessions) == 0 {
+		return nil, fmt.Errorf("entry %s without accession", e.Name)
+	}
+	if e.Sequence == "" {
+		return nil, fmt.Errorf("entry %s without sequence", e.Name)
+	}
+	if p.length > 0 && len(e.Sequence) != p.length {
+		return nil, fmt.Errorf("entry %s sequence length %d, expected %d", e.Name, len(e.Sequence), p.length)
+	}
+
+	return e, nil
+}
+
+// CrossRefsTo returns the cross-references of the entry to the given database, i.e. Pfam or PDB.
+func (e *Entry) CrossRefsTo(database string) []*CrossRef {
+	var refs []*CrossRef
+	for _, x := range e.CrossRefs {
+		if x.Database == database {
+			refs = append(refs, x)
+		}
+	}
+	return refs
+}
+
+// FeaturesOf returns the features of the entry with the given type, i.e. VARIANT, in file order.
+func (e *Entry) FeaturesOf(featureType string) []*Feature {
+	var features []*Feature
+	for _, f := range e.Features {
+		if f.Type == featureType {
+			features = append(features, f)
+		}
+	}
+	return features
+}
+
+func joinLine(s string, line string) string {
+	if s == "" {
+		return line
+	}
+	return s + " " + line
+}
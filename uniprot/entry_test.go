@@ -0,0 +1,123 @@
+package uniprot
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseEntry(t *testing.T) {
+	raw, err := ioutil.ReadFile("testdata/P01308.txt")
+	if err != nil {
+		t.Fatalf("cannot open file: %s", err)
+	}
+
+	e, err := ParseEntry(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Name != "INS_HUMAN" || !e.Reviewed || !reflect.DeepEqual(e.Accessions, []string{"P01308", "Q5EEX2"}) {
+		t.Errorf("unexpected ID and AC %s %v %v", e.Name, e.Reviewed, e.Accessions)
+	}
+	if d := e.Description; d.Name != "Insulin" || !reflect.DeepEqual(d.Contains, []string{"Insulin B chain", "Insulin A chain"}) || !reflect.DeepEqual(d.Flags, []string{"Precursor"}) {
+		t.Errorf("unexpected description %+v", d)
+	}
+	if len(e.Genes) != 1 || e.Genes[0].Name != "INS" || e.Organism != "Homo sapiens (Human)" || e.TaxonomyID != 9606 || e.Lineage[len(e.Lineage)-1] != "Homo" {
+		t.Errorf("unexpected gene and organism")
+	}
+	if len(e.References) != 3 || e.References[0].PubMed != "7003385" || e.References[0].DOI != "10.1038/284026a0" ||
+		e.References[0].Authors != "Bell G.I., Pictet R.L., Rutter W.J., Cordell B., Tischer E., Goodman H.M." ||
+		e.References[0].Title != "Sequence of the human insulin gene." || e.References[2].Group != "The MGC Project Team" {
+		t.Errorf("unexpected references %+v", e.References[0])
+	}
+	if len(e.Comments) != 3 || e.Comments[1].Topic != "SUBUNIT" || !strings.HasSuffix(e.Comments[1].Text, "linked by two disulfide bonds.") {
+		t.Errorf("unexpected comments %+v", e.Comments)
+	}
+	if pdbs := e.CrossRefsTo("PDB"); len(pdbs) != 2 || pdbs[0].ID != "1MSO" || pdbs[0].Properties[2] != "A=90-110, B=25-54" {
+		t.Errorf("unexpected PDB cross-references %+v", pdbs)
+	}
+	if len(e.Keywords) != 11 || e.Keywords[10] != "Signal" {
+		t.Errorf("unexpected keywords %v", e.Keywords)
+	}
+	if len(e.Sequence) != 110 || !strings.HasPrefix(e.Sequence, "MALWMRLLPL") || e.MolWeight != 11981 || e.CRC64 != "C2C3B23B85E520E5" {
+		t.Errorf("unexpected sequence")
+	}
+
+	regions := e.FeaturesOf("REGION")
+	if len(regions) != 2 {
+		t.Fatalf("expected 2 regions, got %d", len(regions))
+	}
+	expected := Location{Start: 1, End: 20, StartModifier: "<", EndModifier: ">"}
+	if regions[0].Location != expected || regions[0].Location.Known() || regions[0].Note != "Hypothetical region with uncertain boundaries" {
+		t.Errorf("unexpected region %+v", regions[0])
+	}
+	if l := regions[1].Location; l.Start != 0 || l.StartModifier != "?" || l.End != 30 {
+		t.Errorf("unexpected region location %+v", l)
+	}
+
+	variants := e.FeaturesOf("VARIANT")
+	if len(variants) != 5 {
+		t.Fatalf("expected 5 variants, got %d", len(variants))
+	}
+	if v := variants[0]; v.ID != "VAR_063717" || v.Evidence != "ECO:0000269|PubMed:18192540, ECO:0000269|PubMed:20226046" {
+		t.Errorf("unexpected variant %+v", v)
+	}
+	if v := variants[2]; v.Note != "" || v.ID != "VAR_063719" {
+		t.Errorf("unexpected variant without note %+v", v)
+	}
+
+	for _, bad := range []string{
+		"ID   X_HUMAN Reviewed; 3 AA.\nAC   P1;\nSQ   SEQUENCE   3 AA;\n     MA\n//\n",
+		"ID   X_HUMAN Reviewed; 2 AA.\nAC   P1;\nFT   VARIANT         x1\nSQ   SEQUENCE   2 AA;\n     MA\n//\n",
+		"ID   X_HUMAN Reviewed; 2 AA.\nAC   P1;\nSQ   SEQUENCE   2 AA;\n     MA\n",
+	} {
+		if _, err := ParseEntry([]byte(bad)); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestNewUniProtFromRaw(t *testing.T) {
+	raw, err := ioutil.ReadFile("testdata/P01308.txt")
+	if err != nil {
+		t.Fatalf("cannot open file: %s", err)
+	}
+
+	u, err := NewUniProtFromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.ID != "P01308" || u.Name != "Insulin" || u.Gene != "INS" || u.Organism != "Homo sapiens (Human)" || len(u.Sequence) != 110 {
+		t.Errorf("unexpected entry %s %s %s %s", u.ID, u.Name, u.Gene, u.Organism)
+	}
+
+	// Variants without ID are kept, without note or change are skipped
+	var changes []string
+	for _, v := range u.Variants {
+		changes = append(changes, v.Change)
+	}
+	if !reflect.DeepEqual(changes, []string{"R6C", "H34D", "F49L"}) {
+		t.Fatalf("unexpected variants %v", changes)
+	}
+	if v := u.Variants[0]; v.ID != "VAR_063717" || v.DbSNP != "rs121908278" || !reflect.DeepEqual(v.PubMedIDs, []string{"18192540", "20226046"}) {
+		t.Errorf("unexpected variant %+v", v)
+	}
+	if v := u.Variants[1]; v.ID != "" || v.DbSNP != "rs121918101" {
+		t.Errorf("unexpected variant without ID %+v", v)
+	}
+
+	if len(u.Publications) != 2 || u.Publications["6248966"].Journal != "Science 208:57-59(1980)." {
+		t.Errorf("unexpected publications %+v", u.Publications)
+	}
+	if len(u.PTMs.DisulfideBonds) != 3 || u.PTMs.DisulfideBonds[1].Positions != [2]int64{43, 109} ||
+		len(u.PTMs.Glycosilations) != 1 || len(u.PTMs.ModifiedResidues) != 1 {
+		t.Errorf("unexpected PTMs %+v", u.PTMs)
+	}
+	if len(u.Sites) != 3 || u.Sites[0].Note != "Zn(2+)" || u.Sites[2].Position != 55 {
+		t.Errorf("unexpected sites %+v", u.Sites)
+	}
+	if !reflect.DeepEqual(u.Pfam, []string{"PF00049"}) {
+		t.Errorf("unexpected Pfam %v", u.Pfam)
+	}
+}
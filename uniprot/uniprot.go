@@ -26,6 +26,7 @@ type UniProt struct {
 	Variants     []VariantEntry         `json:"variants"`     // variants
 	Publications map[string]Publication `json:"publications"` // PubMed ID to publications
 	Raw          []byte                 `json:"-"`            // TXT API raw bytes.
+	Entry        *Entry                 `json:"-"`            // complete entry parsed from the TXT
 }
 
 // PDB represents a single available PDB structure for an UniProt.
@@ -115,57 +116,58 @@ func NewUniProt(uniprotID string) (*UniProt, error) {
 	// Parse UniProt TXT
 	err = u.extract()
 	if err != nil {
-		return nil, fmt.Errorf("extract PDB crystals %v: %v", uniprotID, err)
-	}
-
-	return u, nil
-}
-
-// extract parses the TXT response.
-func (u *UniProt) extract() error {
-	err := u.extractSequence()
-	if err != nil {
-		return fmt.Errorf("get seq: %v", err)
+		return nil, fmt.Errorf("extract UniProt %v: %v", uniprotID, err)
 	}
 
 	err = u.extractPDBs()
 	if err != nil {
-		return fmt.Errorf("extracting crystals from SIFTS: %v", err)
+		return nil, fmt.Errorf("extracting crystals from SIFTS: %v", err)
 	}
 
-	err = u.extractNames()
-	if err != nil {
-		return fmt.Errorf("extracting names from UniProt TXT: %v", err)
-	}
+	return u, nil
+}
 
-	err = u.extractVariants()
+// NewUniProtFromRaw constructs an instance from the raw bytes of a UniProt TXT entry, without fetching
+// the PDB structures from SIFTS.
+func NewUniProtFromRaw(raw []byte) (*UniProt, error) {
+	u := &UniProt{Raw: raw}
+	err := u.extract()
 	if err != nil {
-		return fmt.Errorf("extracting variants from UniProt TXT: %v", err)
+		return nil, err
 	}
 
-	err = u.extractPublications()
-	if err != nil {
-		return fmt.Errorf("extracting publications from UniProt TXT: %v", err)
-	}
+	u.ID = u.Entry.Accessions[0]
+	u.URL = "https://www.uniprot.org/uniprot/" + u.ID
+	u.TXTURL = u.URL + ".txt"
 
-	err = u.extractPTMs()
+	return u, nil
+}
+
+// extract parses the TXT response.
+func (u *UniProt) extract() error {
+	entry, err := ParseEntry(u.Raw)
 	if err != nil {
-		return fmt.Errorf("extracting PTMs from UniProt TXT: %v", err)
+		return fmt.Errorf("parse UniProt TXT: %v", err)
 	}
+	u.Entry = entry
+	u.Sequence = entry.Sequence
 
-	err = u.extractFams()
+	err = u.extractNames()
 	if err != nil {
-		return fmt.Errorf("extracting families from UniProt TXT: %v", err)
+		return fmt.Errorf("extracting names from UniProt TXT: %v", err)
 	}
 
+	u.extractVariants()
+	u.extractPublications()
+	u.extractPTMs()
+	u.extractFams()
 	u.extractSites()
 
 	return nil
 }
 
-// extractPDBs parses the TXT for PDB IDs and populates UniProt.PDBs
+// extractPDBs populates UniProt.PDBs from the SIFTS best structures.
 func (u *UniProt) extractPDBs() error {
-	// Extract from SIFTS
 	pdbs, err := getSIFTSBestStructures(u.ID)
 	if err != nil {
 		return err
@@ -173,247 +175,121 @@ func (u *UniProt) extractPDBs() error {
 
 	u.PDBs = pdbs
 
-	// r, _ := regexp.Compile(`(?ms)PDB; (.*?); (.*?); ([\.0-9]*).*?;.*?$`)
-	// rStartEnd, _ := regexp.Compile("([0-9]*)-([0-9]*)")
-
-	// matches := r.FindAllStringSubmatch(string(u.Raw), -1)
-	// // Parse each PDB match in TXT
-	// for _, m := range matches {
-	// 	coverage := 0.0
-	// 	rangeMatches := rStartEnd.FindAllStringSubmatch(m[0], -1)
-	// 	for _, rm := range rangeMatches {
-	// 		startPos, _ := strconv.Atoi(rm[1])
-	// 		endPos, _ := strconv.Atoi(rm[2])
-	// 		coverage += float64(endPos - startPos)
-	// 	}
-
-	// 	coverage = coverage / float64(len(u.Sequence))
-	// 	resolution, _ := strconv.ParseFloat(m[3], 64)
-	// 	if m[2] == "X-ray" {
-	// 		u.PDBs = append(u.PDBs, PDB{
-	// 			ID:         m[1],
-	// 			Coverage:   coverage,
-	// 			Method:     m[2],
-	// 			Resolution: resolution,
-	// 		})
-	// 	}
-	// }
-
-	return nil
-}
-
-// extractSequence parses the canonical sequence.
-func (u *UniProt) extractSequence() error {
-	r, _ := regexp.Compile("(?ms)^SQ.*?$(.*?)//") // https://regex101.com/r/ZTOYaJ/1
-	matches := r.FindAllStringSubmatch(string(u.Raw), -1)
-
-	if len(matches) == 0 {
-		return errors.New("canonical sequence not found")
-	}
-
-	seqGroup := matches[0][1]
-	sequence := strings.ReplaceAll(seqGroup, " ", "")
-	sequence = strings.ReplaceAll(sequence, "\n", "")
-
-	u.Sequence = sequence
-
 	return nil
 }
 
-// extractNames parses protein, gene and organism names
+// extractNames sets protein, gene and organism names
 func (u *UniProt) extractNames() error {
-	r, _ := regexp.Compile("(?m)^DE.*?Name.*?Full=(.*?)(;| {)")
-	matches := r.FindAllStringSubmatch(string(u.Raw), -1)
-
-	if len(matches) == 0 {
+	e := u.Entry
+	if e.Description.Name == "" {
 		return errors.New("protein name not found")
 	}
-	u.Name = matches[0][1]
-
-	r, _ = regexp.Compile("(?m)^GN.*?=(.*?)[;| ]")
-	matches = r.FindAllStringSubmatch(string(u.Raw), -1)
-
-	if len(matches) != 0 {
-		u.Gene = matches[0][1]
+	u.Name = e.Description.Name
+
+	for _, g := range e.Genes {
+		names := append([]string{g.Name}, g.OrderedLocusNames...)
+		names = append(names, g.ORFNames...)
+		for _, name := range names {
+			if name != "" {
+				u.Gene = name
+				break
+			}
+		}
+		if u.Gene != "" {
+			break
+		}
 	}
 
-	r, _ = regexp.Compile("(?m)^OS[ ]+(.*?)\\.")
-	matches = r.FindAllStringSubmatch(string(u.Raw), -1)
-
-	if len(matches) == 0 {
+	if e.Organism == "" {
 		return errors.New("organism name not found")
 	}
-	u.Organism = matches[0][1]
+	u.Organism = e.Organism
 
 	return nil
 }
 
-// extractVariants parses for variant references
-func (u *UniProt) extractVariants() error {
-	var variants []VariantEntry
-
-	// https://regex101.com/r/BpJ3QB/1
-	r, _ := regexp.Compile("(?ms)^FT[ ]*VARIANT[ ]*([0-9]*)$(.*?)id=\"(.*?)\"")
-	rPubmed, _ := regexp.Compile("PubMed:([0-9]*)")
-	matches := r.FindAllStringSubmatch(string(u.Raw), -1)
-
-	for _, variant := range matches {
-		var entry VariantEntry
-		pos, err := strconv.ParseInt(variant[1], 10, 64)
-		if err != nil {
-			return fmt.Errorf("cannot parse variant position int: %s", variant[1])
-		}
-		entry.Position = pos
-
-		data := variant[2]
-		s := regexp.MustCompile("\nFT \\s+")
-		d := s.ReplaceAllString(data, " ")
-
-		r, _ := regexp.Compile("(?s)/note=\"(.*?)\"")
-		n := r.FindAllStringSubmatch(d, -1)
-		entry.Note = n[0][1]
-
-		r, _ = regexp.Compile("(.) -> (.) ")
-		ne := r.FindAllStringSubmatch(entry.Note, -1)
-		if len(ne) == 0 {
+// extractVariants sets the single aminoacid substitutions from the VARIANT features, with or without an ID.
+// Deletions, insertions and variants without a description of the change are skipped.
+func (u *UniProt) extractVariants() {
+	rChange := regexp.MustCompile(`^([A-Z]) -> ([A-Z])\b`)
+	rDbSNP := regexp.MustCompile(`dbSNP:(rs[0-9]+)`)
+	rPubmed := regexp.MustCompile(`PubMed:([0-9]+)`)
+
+	u.Variants = nil
+	for _, f := range u.Entry.FeaturesOf("VARIANT") {
+		ne := rChange.FindStringSubmatch(f.Note)
+		if ne == nil || !f.Location.Known() || f.Location.Start != f.Location.End {
 			continue
 		}
-		entry.FromAa = ne[0][1]
-		entry.ToAa = ne[0][2]
-		entry.Change = entry.FromAa + variant[1] + entry.ToAa
-
-		r, _ = regexp.Compile("dbSNP:(rs[0-9]*)")
-		nedb := r.FindAllStringSubmatch(entry.Note, -1)
-		if len(nedb) > 0 {
-			entry.DbSNP = nedb[0][1]
-		}
 
-		r, _ = regexp.Compile("(?s)/evidence=\"(.*?)\"")
-		e := r.FindAllStringSubmatch(d, -1)
-		if len(e) > 0 {
-			entry.Evidence = e[0][1]
-			pubmedMatches := rPubmed.FindAllStringSubmatch(entry.Evidence, -1)
+		entry := VariantEntry{
+			Position: f.Location.Start,
+			FromAa:   ne[1],
+			ToAa:     ne[2],
+			Note:     f.Note,
+			Evidence: f.Evidence,
+			ID:       f.ID,
+		}
+		entry.Change = entry.FromAa + strconv.FormatInt(entry.Position, 10) + entry.ToAa
 
-			for _, match := range pubmedMatches {
-				entry.PubMedIDs = append(entry.PubMedIDs, match[1])
-			}
+		if m := rDbSNP.FindStringSubmatch(f.Note); m != nil {
+			entry.DbSNP = m[1]
+		}
+		for _, m := range rPubmed.FindAllStringSubmatch(f.Evidence, -1) {
+			entry.PubMedIDs = append(entry.PubMedIDs, m[1])
 		}
 
-		entry.ID = variant[3]
-		variants = append(variants, entry)
+		u.Variants = append(u.Variants, entry)
 	}
-
-	u.Variants = variants
-
-	return nil
 }
 
-func (u *UniProt) extractPublications() error {
+// extractPublications sets the references with a PubMed ID.
+func (u *UniProt) extractPublications() {
 	u.Publications = make(map[string]Publication)
-
-	// https://regex101.com/r/G2FV96/1/
-	rPub, _ := regexp.Compile("(?ms)^RN.*?RL.*?$")
-	rTitle, _ := regexp.Compile("(?m)^RT[ ]*(.*)")
-	rAuthors, _ := regexp.Compile("(?m)^RA[ ]*(.*)")
-	rJournal, _ := regexp.Compile("RL[ ]*(.*)")
-	rPubmed, _ := regexp.Compile("PubMed=([0-9]*)")
-	rDOI, _ := regexp.Compile("DOI=(.*?);")
-
-	clean := func(s string) string {
-		s = strings.ReplaceAll(s, ";", "")
-		s = strings.ReplaceAll(s, "\"", "")
-		s = strings.TrimSpace(s)
-		return s
-	}
-
-	pubMatches := rPub.FindAllStringSubmatch(string(u.Raw), -1)
-	for _, pub := range pubMatches {
-		var title string
-		var authors string
-		var journal string
-		var pubmed string
-		var doi string
-
-		pubmedMatches := rPubmed.FindAllStringSubmatch(pub[0], -1)
-		if len(pubmedMatches) > 0 {
-			pubmed = pubmedMatches[0][1]
-		}
-
-		doiMatches := rDOI.FindAllStringSubmatch(pub[0], -1)
-		if len(doiMatches) > 0 {
-			doi = doiMatches[0][1]
-		}
-
-		titleMatches := rTitle.FindAllStringSubmatch(pub[0], -1)
-		for _, titleLine := range titleMatches {
-			title += titleLine[1] + " "
-		}
-		title = clean(title)
-
-		authorMatches := rAuthors.FindAllStringSubmatch(pub[0], -1)
-		for _, authorLine := range authorMatches {
-			authors += authorLine[1] + " "
-		}
-		authors = clean(authors)
-
-		journalMatches := rJournal.FindAllStringSubmatch(pub[0], -1)
-		if len(journalMatches) > 0 {
-			journal = journalMatches[0][1]
+	for _, ref := range u.Entry.References {
+		if ref.PubMed == "" {
+			continue
 		}
-
-		u.Publications[pubmed] = Publication{
-			Title:   title,
-			Authors: authors,
-			Journal: journal,
-			PubMed:  pubmed,
-			DOI:     doi,
+		u.Publications[ref.PubMed] = Publication{
+			Title:   ref.Title,
+			Authors: strings.ReplaceAll(ref.Authors, ";", ""),
+			Journal: ref.Location,
+			PubMed:  ref.PubMed,
+			DOI:     ref.DOI,
 		}
 	}
-
-	return nil
 }
 
-// extractPTMs parses for post translational modifications
-func (u *UniProt) extractPTMs() error {
+// extractPTMs sets the post translational modifications
+func (u *UniProt) extractPTMs() {
 	u.PTMs = PTMs{}
 
 	// Glycosilation sites
-	r, _ := regexp.Compile("(?ms)^FT[ ]*CARBOHYD[ ]*([0-9]*)$.*?note=\"(.*?)\"")
-	matches := r.FindAllStringSubmatch(string(u.Raw), -1)
-
-	for _, glyco := range matches {
-		pos, _ := strconv.ParseInt(glyco[1], 10, 64)
-		if strings.Contains(glyco[2], "N-linked") {
+	for _, f := range u.Entry.FeaturesOf("CARBOHYD") {
+		if f.Location.Known() && strings.Contains(f.Note, "N-linked") {
 			u.PTMs.Glycosilations = append(u.PTMs.Glycosilations,
-				Glycosilation{Position: pos, Note: glyco[2]})
+				Glycosilation{Position: f.Location.Start, Note: f.Note})
 		}
 	}
 
 	// Modified residues
-	r, _ = regexp.Compile("(?ms)^FT[ ]*MOD_RES[ ]*([0-9]*)$.*?note=\"(.*?)\"")
-	matches = r.FindAllStringSubmatch(string(u.Raw), -1)
-
-	for _, modres := range matches {
-		pos, _ := strconv.ParseInt(modres[1], 10, 64)
-		u.PTMs.ModifiedResidues = append(u.PTMs.ModifiedResidues,
-			ModifiedResidue{Position: pos, Note: modres[2]})
+	for _, f := range u.Entry.FeaturesOf("MOD_RES") {
+		if f.Location.Known() {
+			u.PTMs.ModifiedResidues = append(u.PTMs.ModifiedResidues,
+				ModifiedResidue{Position: f.Location.Start, Note: f.Note})
+		}
 	}
 
-	// Disulfide bonds
-	r, _ = regexp.Compile("(?ms)^FT[ ]*DISULFID[ ]*([0-9]*)..([0-9]*)")
-	matches = r.FindAllStringSubmatch(string(u.Raw), -1)
-
-	for _, disulf := range matches {
-		pos1, _ := strconv.ParseInt(disulf[1], 10, 64)
-		pos2, _ := strconv.ParseInt(disulf[2], 10, 64)
-		u.PTMs.DisulfideBonds = append(u.PTMs.DisulfideBonds,
-			Disulfide{Positions: [2]int64{pos1, pos2}})
+	// Disulfide bonds, intrachain only as interchain ones have a single position
+	for _, f := range u.Entry.FeaturesOf("DISULFID") {
+		if f.Location.Known() && f.Location.Start != f.Location.End {
+			u.PTMs.DisulfideBonds = append(u.PTMs.DisulfideBonds,
+				Disulfide{Positions: [2]int64{f.Location.Start, f.Location.End}})
+		}
 	}
-
-	return nil
 }
 
-// extractSites parses for sites
+// extractSites sets the sites, one per position for ranges
 func (u *UniProt) extractSites() {
 	tags := map[string]string{
 		"ACT_SITE": "active",     // https://www.uniprot.org/help/act_site
@@ -423,48 +299,35 @@ func (u *UniProt) extractSites() {
 		"SITE":     "site",       // https://www.uniprot.org/help/site
 	}
 
-	for tag, name := range tags {
-		r, _ := regexp.Compile(`(?ms)^FT[ ]*` + tag + `[ ]*([0-9\.]*)$.*?note=\"(.*?)\"`)
-		matches := r.FindAllStringSubmatch(string(u.Raw), -1)
-
-		for _, site := range matches {
-			posStr := site[1]
-
-			if strings.Contains(posStr, "..") { // range of positions
-				positions := strings.Split(posStr, "..")
-				start, _ := strconv.ParseInt(positions[0], 10, 64)
-				end, _ := strconv.ParseInt(positions[1], 10, 64)
-				for i := start; i <= end; i++ {
-					u.Sites = append(u.Sites,
-						Site{
-							Type:     name,
-							Position: i,
-							Note:     site[2],
-						},
-					)
-				}
-			} else {
-				pos, _ := strconv.ParseInt(site[1], 10, 64)
-				u.Sites = append(u.Sites,
-					Site{
-						Type:     name,
-						Position: pos,
-						Note:     site[2],
-					},
-				)
-			}
+	u.Sites = nil
+	for _, f := range u.Entry.Features {
+		name, ok := tags[f.Type]
+		if !ok || !f.Location.Known() {
+			continue
 		}
-	}
-}
 
-// extractFams parses for Pfam families.
-func (u *UniProt) extractFams() error {
-	r, _ := regexp.Compile("DR[ ]*Pfam; (.*?);")
-	matches := r.FindAllStringSubmatch(string(u.Raw), -1)
+		// Binding sites are described by their ligand since 2021
+		note := f.Note
+		if note == "" {
+			note = f.Qualifiers["ligand"]
+		}
 
-	for _, fam := range matches {
-		u.Pfam = append(u.Pfam, fam[1])
+		for i := f.Location.Start; i <= f.Location.End; i++ {
+			u.Sites = append(u.Sites,
+				Site{
+					Type:     name,
+					Position: i,
+					Note:     note,
+				},
+			)
+		}
 	}
+}
 
-	return nil
+// extractFams sets the Pfam families accessions.
+func (u *UniProt) extractFams() {
+	u.Pfam = nil
+	for _, x := range u.Entry.CrossRefsTo("Pfam") {
+		u.Pfam = append(u.Pfam, x.ID)
+	}
 }